@@ -0,0 +1,110 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+	"github.com/raedahgroup/dcrextdata/helpers"
+	"github.com/raedahgroup/dcrextdata/postgres"
+)
+
+// backfillPollInterval is how often the backfill runner looks for new jobs
+const backfillPollInterval = time.Minute
+
+// manageBackfills creates the backfill job described by opts or changes the
+// status of a job, then prints all jobs with their progress. Jobs are run
+// by the collector.
+func manageBackfills(ctx context.Context, db *postgres.PgDb, restExchanges string, opts BackfillOptions) error {
+	if err := syncExchangeRegistry(ctx, db, restExchanges); err != nil {
+		return err
+	}
+	if exists := db.BackfillJobTableExits(); !exists {
+		if err := db.CreateBackfillJobTable(); err != nil {
+			log.Error("Error creating backfill job table: ", err)
+			return err
+		}
+	}
+
+	if opts.Exchange != "" {
+		if err := createBackfillJob(ctx, db, opts); err != nil {
+			return err
+		}
+	}
+
+	for action, id := range map[string]int{ticks.BackfillPause: opts.Pause,
+		ticks.BackfillResume: opts.Resume, ticks.BackfillCancel: opts.Cancel} {
+		if id == 0 {
+			continue
+		}
+		status, from, err := ticks.BackfillTransition(action)
+		if err != nil {
+			return err
+		}
+		changed, err := db.SetBackfillStatus(ctx, id, status, "", from...)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			return fmt.Errorf("Cannot %s backfill job %d", action, id)
+		}
+	}
+
+	jobs, err := db.BackfillJobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEXCHANGE\tMARKET\tINTERVAL\tFROM\tTO\tCHECKPOINT\tSTATUS\tPROGRESS\tETA\tERROR")
+	for _, job := range jobs {
+		eta := "-"
+		if left, ok := job.ETA(); ok {
+			eta = left.Round(time.Second).String()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%dm\t%s\t%s\t%s\t%s\t%.1f%%\t%s\t%s\n", job.ID, job.Exchange, job.CurrencyPair,
+			job.Interval, formatTime(job.Start), formatTime(job.End), formatTime(job.Checkpoint), job.Status,
+			job.Progress(), eta, job.Error)
+	}
+	return w.Flush()
+}
+
+func createBackfillJob(ctx context.Context, db *postgres.PgDb, opts BackfillOptions) error {
+	if opts.From == "" {
+		return fmt.Errorf("Set the start of the backfill with --from")
+	}
+	from, err := helpers.ParseDate(opts.From)
+	if err != nil {
+		return err
+	}
+	to := time.Now()
+	if opts.To != "" {
+		if to, err = helpers.ParseDate(opts.To); err != nil {
+			return err
+		}
+	}
+
+	job, err := ticks.NewBackfillJob(strings.ToLower(opts.Exchange), opts.Pair,
+		time.Duration(opts.Interval)*time.Minute, from, to)
+	if err != nil {
+		return err
+	}
+	id, err := db.CreateBackfillJob(ctx, job)
+	if err != nil {
+		return err
+	}
+	log.Infof("Created backfill job %d", id)
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04")
+}
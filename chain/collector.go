@@ -0,0 +1,147 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chain
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// storeBatch is the number of blocks stored at once while syncing, so that a
+// long sync is resumed close to where it stopped
+const storeBatch = 100
+
+// Collector records the blocks of the best chain of a dcrd node. Blocks
+// orphaned by a reorganization are replaced by the blocks of the new best
+// chain.
+type Collector struct {
+	node    Node
+	period  time.Duration
+	history int64
+	store   Store
+}
+
+// NewCollector creates a collector that checks the node for new blocks every
+// period. The first sync starts history blocks before the best block.
+func NewCollector(node Node, period time.Duration, history int64, store Store) (*Collector, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("Chain collection interval must be positive, got %v", period)
+	}
+	if history < 1 {
+		return nil, fmt.Errorf("Chain history must be at least 1 block, got %d", history)
+	}
+
+	return &Collector{
+		node:    node,
+		period:  period,
+		history: history,
+		store:   store,
+	}, nil
+}
+
+// Run syncs the blocks of the node until ctx is cancelled
+func (c *Collector) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(c.period)
+	defer ticker.Stop()
+	for {
+		if err := c.sync(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("Unable to sync chain blocks: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sync stores the blocks of the node's best chain after the last stored
+// block it still contains
+func (c *Collector) sync(ctx context.Context) error {
+	best, err := c.node.GetBlockCount(ctx)
+	if err != nil {
+		return err
+	}
+
+	from := best - c.history + 1
+	if from < 0 {
+		from = 0
+	}
+	last, err := c.store.LastChainBlock(ctx)
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		common, err := c.commonHeight(ctx, last, best)
+		if err != nil {
+			return err
+		}
+		if common < last.Height {
+			log.Infof("Chain reorganized, replacing the blocks from %d", common+1)
+		}
+		if common >= from {
+			from = common + 1
+		}
+	}
+
+	blocks := make([]Block, 0, storeBatch)
+	for height := from; height <= best; height++ {
+		hash, err := c.node.GetBlockHash(ctx, height)
+		if err != nil {
+			return err
+		}
+		header, err := c.node.GetBlockHeader(ctx, hash)
+		if err != nil {
+			return err
+		}
+		block, err := header.toBlock()
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, block)
+
+		if len(blocks) == storeBatch || height == best {
+			if err = c.store.StoreChainBlocks(ctx, blocks); err != nil {
+				return err
+			}
+			log.Infof("Stored chain blocks up to %d of %d", height, best)
+			blocks = blocks[:0]
+		}
+	}
+	return nil
+}
+
+// commonHeight returns the height of the last stored block that is in the
+// best chain of the node, walking back from the last stored block. It stops
+// at the first height without a stored block.
+func (c *Collector) commonHeight(ctx context.Context, last *Block, best int64) (int64, error) {
+	height, hash := last.Height, last.Hash
+	if height > best {
+		var err error
+		height = best
+		if hash, err = c.store.ChainBlockHash(ctx, height); err != nil {
+			return 0, err
+		}
+	}
+
+	for height >= 0 && hash != "" {
+		nodeHash, err := c.node.GetBlockHash(ctx, height)
+		if err != nil {
+			return 0, err
+		}
+		if nodeHash == hash {
+			return height, nil
+		}
+		height--
+		if hash, err = c.store.ChainBlockHash(ctx, height); err != nil {
+			return 0, err
+		}
+	}
+	return height, nil
+}
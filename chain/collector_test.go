@@ -0,0 +1,99 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chain
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type memoryStore struct {
+	blocks []Block
+}
+
+func (s *memoryStore) LastChainBlock(ctx context.Context) (*Block, error) {
+	if len(s.blocks) == 0 {
+		return nil, nil
+	}
+	return &s.blocks[len(s.blocks)-1], nil
+}
+
+func (s *memoryStore) ChainBlockHash(ctx context.Context, height int64) (string, error) {
+	for _, b := range s.blocks {
+		if b.Height == height {
+			return b.Hash, nil
+		}
+	}
+	return "", nil
+}
+
+func (s *memoryStore) StoreChainBlocks(ctx context.Context, blocks []Block) error {
+	kept := s.blocks[:0]
+	for _, b := range s.blocks {
+		if b.Height < blocks[0].Height {
+			kept = append(kept, b)
+		}
+	}
+	s.blocks = append(kept, blocks...)
+	return nil
+}
+
+// checkStore checks that the store holds the last count blocks of the node
+func checkStore(t *testing.T, store *memoryStore, node *testNode, count int) {
+	t.Helper()
+	if len(store.blocks) != count {
+		t.Fatalf("stored %d blocks, expected %d", len(store.blocks), count)
+	}
+	offset := len(node.blocks) - count
+	for i, b := range store.blocks {
+		expected := node.blocks[offset+i]
+		if b.Height != expected.Height || b.Hash != expected.Hash || b.Time != expected.Time {
+			t.Errorf("stored block %d is %+v, expected %+v", i, b, expected)
+		}
+	}
+}
+
+func TestCollectorSync(t *testing.T) {
+	node := newTestNode(t)
+	node.extend(250, "a")
+	store := new(memoryStore)
+	collector, err := NewCollector(node.client(t), time.Minute, 200, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// The first sync starts history blocks before the best block
+	if err = collector.sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	checkStore(t, store, node, 200)
+
+	node.extend(3, "a")
+	if err = collector.sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	checkStore(t, store, node, 203)
+
+	// The orphaned blocks are replaced by the blocks of the new best chain
+	node.reorganize(2, 3, "b")
+	if err = collector.sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	checkStore(t, store, node, 204)
+	if last := store.blocks[len(store.blocks)-1]; last.Hash[0] != 'b' {
+		t.Errorf("last block %s is not of the new best chain", last.Hash)
+	}
+}
+
+func TestNewCollectorValidation(t *testing.T) {
+	if _, err := NewCollector(nil, 0, 10, nil); err == nil {
+		t.Error("zero interval accepted")
+	}
+	if _, err := NewCollector(nil, time.Minute, 0, nil); err == nil {
+		t.Error("zero history accepted")
+	}
+}
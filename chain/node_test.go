@@ -0,0 +1,207 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	testUser = "user"
+	testPass = "pass"
+)
+
+// testNode is a stand-in dcrd serving the JSON-RPC methods the collector
+// uses on its websocket endpoint
+type testNode struct {
+	*httptest.Server
+
+	mtx    sync.Mutex
+	blocks []BlockHeader
+	conns  map[*testConn]struct{}
+}
+
+// testConn is a connection to the test node and the notifications requested
+// on it
+type testConn struct {
+	*websocket.Conn
+	writeMtx sync.Mutex
+	blocks   bool
+	txs      bool
+}
+
+func (c *testConn) write(v interface{}) error {
+	c.writeMtx.Lock()
+	defer c.writeMtx.Unlock()
+	return c.WriteJSON(v)
+}
+
+func newTestNode(t *testing.T) *testNode {
+	node := &testNode{conns: make(map[*testConn]struct{})}
+	node.Server = httptest.NewServer(http.HandlerFunc(node.serveWs))
+	t.Cleanup(node.Close)
+	return node
+}
+
+// client returns a client of the node, without TLS
+func (n *testNode) client(t *testing.T) *Client {
+	c, err := NewClient(RPCConfig{
+		Host:  strings.TrimPrefix(n.URL, "http://"),
+		User:  testUser,
+		Pass:  testPass,
+		NoTLS: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// extend appends count blocks to the chain of the node. The hashes of the
+// blocks depend on fork so that forks have different hashes.
+func (n *testNode) extend(count int, fork string) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	for i := 0; i < count; i++ {
+		height := int64(len(n.blocks))
+		n.blocks = append(n.blocks, BlockHeader{
+			Hash:        fmt.Sprintf("%s%063x", fork, height)[:64],
+			Height:      height,
+			Time:        1454954400 + height*300,
+			Difficulty:  float64(1000 + height),
+			SBits:       json.Number(fmt.Sprintf("%d.12345678", 100+height)),
+			PoolSize:    40960 + height,
+			Voters:      5,
+			Revocations: int(height % 2),
+		})
+	}
+}
+
+// reorganize replaces the last depth blocks of the chain of the node with
+// count blocks of fork
+func (n *testNode) reorganize(depth, count int, fork string) {
+	n.mtx.Lock()
+	n.blocks = n.blocks[:len(n.blocks)-depth]
+	n.mtx.Unlock()
+	n.extend(count, fork)
+}
+
+func (n *testNode) serveWs(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/ws" {
+		http.NotFound(w, r)
+		return
+	}
+	if user, pass, ok := r.BasicAuth(); !ok || user != testUser || pass != testPass {
+		http.Error(w, "401 Unauthorized.", http.StatusUnauthorized)
+		return
+	}
+	wsConn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn := &testConn{Conn: wsConn}
+	n.mtx.Lock()
+	n.conns[conn] = struct{}{}
+	n.mtx.Unlock()
+	defer func() {
+		n.mtx.Lock()
+		delete(n.conns, conn)
+		n.mtx.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		// drop closes the connection without responding
+		if req.Method == "drop" {
+			return
+		}
+		result, rpcErr := n.handle(conn, req.Method, req.Params)
+		resp := map[string]interface{}{"id": req.ID, "result": result, "error": rpcErr}
+		if err := conn.write(resp); err != nil {
+			return
+		}
+	}
+}
+
+func (n *testNode) handle(conn *testConn, method string, params []json.RawMessage) (interface{}, *RPCError) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	switch method {
+	case "notifyblocks":
+		conn.blocks = true
+		return nil, nil
+	case "notifynewtransactions":
+		conn.txs = true
+		return nil, nil
+	case "getblockcount":
+		return len(n.blocks) - 1, nil
+	case "getblockhash":
+		var height int
+		if len(params) != 1 || json.Unmarshal(params[0], &height) != nil {
+			return nil, &RPCError{Code: -32602, Message: "invalid parameters"}
+		}
+		if height < 0 || height >= len(n.blocks) {
+			return nil, &RPCError{Code: -1, Message: "Block number out of range"}
+		}
+		return n.blocks[height].Hash, nil
+	case "getblockheader":
+		var hash string
+		if len(params) < 1 || json.Unmarshal(params[0], &hash) != nil {
+			return nil, &RPCError{Code: -32602, Message: "invalid parameters"}
+		}
+		for _, block := range n.blocks {
+			if block.Hash == hash {
+				return block, nil
+			}
+		}
+		return nil, &RPCError{Code: -5, Message: "Block not found"}
+	}
+	return nil, &RPCError{Code: -32601, Message: "Method not found"}
+}
+
+// notify sends a notification to the connections that requested it, and
+// returns the number of connections notified
+func (n *testNode) notify(method string, params ...interface{}) int {
+	n.mtx.Lock()
+	conns := make([]*testConn, 0, len(n.conns))
+	for conn := range n.conns {
+		if method == "blockconnected" && conn.blocks || method == "txacceptedverbose" && conn.txs {
+			conns = append(conns, conn)
+		}
+	}
+	n.mtx.Unlock()
+
+	ntfn := map[string]interface{}{"jsonrpc": "1.0", "id": nil, "method": method, "params": params}
+	for _, conn := range conns {
+		conn.write(ntfn)
+	}
+	return len(conns)
+}
+
+// dropConnections closes the connections to the node
+func (n *testNode) dropConnections() {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	for conn := range n.conns {
+		conn.Close()
+	}
+}
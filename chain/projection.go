@@ -0,0 +1,396 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/pow"
+)
+
+// Mainnet parameters of the difficulty retargets
+const (
+	// RetargetInterval is the number of blocks between the retargets of
+	// both the PoW and the stake difficulty. A retarget happens at the
+	// heights that are multiples of it.
+	RetargetInterval = 144
+
+	// workDiffWindows is the number of intervals the PoW difficulty is
+	// computed from, the most recent ones weighing the most
+	workDiffWindows = 20
+
+	// maxRetargetFactor limits the change of the PoW difficulty at a
+	// retarget
+	maxRetargetFactor = 4
+
+	targetBlockTime = 5 * time.Minute
+
+	ticketsPerBlock  = 5
+	ticketPoolSize   = 8192
+	ticketMaturity   = 256
+	minimumStakeDiff = 2e8
+
+	// targetPoolSizeAll is the target number of live and immature tickets
+	targetPoolSizeAll = ticketsPerBlock * (ticketPoolSize + ticketMaturity)
+
+	blockOneSubsidy          = 168000000000000
+	subsidyReductionInterval = 6144
+	atomsPerCoin             = 1e8
+
+	// projectionHistory is the number of blocks before the tip a projection
+	// may use
+	projectionHistory = (workDiffWindows+1)*RetargetInterval + ticketMaturity
+
+	// asertActivationHeight is the height from which the PoW difficulty
+	// is changed every block by ASERT (DCP-0011) instead of at the
+	// retargets
+	asertActivationHeight = 794368
+
+	// accuracyBuckets is the number of groups of the projections by the
+	// number of blocks they were made before the retarget
+	accuracyBuckets = 4
+)
+
+// Projection is the projected PoW difficulty and ticket price, in DCR, at
+// the retarget height RetargetHeight, projected from the block at Height and
+// the blocks before it. Difficulty and TicketPrice are those of the block at
+// Height. ProjectedDifficulty is 0 for the retargets from the ASERT
+// activation, ProjectedTicketPrice is 0 when the fresh stake of the blocks it
+// needs is unknown, and the actual values are 0 until the retarget.
+type Projection struct {
+	RetargetHeight       int64   `json:"retarget_height"`
+	Height               int64   `json:"height"`
+	Time                 int64   `json:"time"`
+	Difficulty           float64 `json:"difficulty"`
+	ProjectedDifficulty  float64 `json:"projected_difficulty"`
+	TicketPrice          float64 `json:"ticket_price"`
+	ProjectedTicketPrice float64 `json:"projected_ticket_price"`
+	ActualDifficulty     float64 `json:"actual_difficulty"`
+	ActualTicketPrice    float64 `json:"actual_ticket_price"`
+}
+
+// BlocksBefore returns the number of blocks between the projection and the
+// retarget
+func (p Projection) BlocksBefore() int64 {
+	return p.RetargetHeight - p.Height
+}
+
+// DifficultyError returns the error of the projected difficulty in percent
+// of the actual one, 0 before the retarget
+func (p Projection) DifficultyError() float64 {
+	return percentError(p.ProjectedDifficulty, p.ActualDifficulty)
+}
+
+// TicketPriceError returns the error of the projected ticket price in
+// percent of the actual one, 0 before the retarget
+func (p Projection) TicketPriceError() float64 {
+	return percentError(p.ProjectedTicketPrice, p.ActualTicketPrice)
+}
+
+func percentError(projected, actual float64) float64 {
+	if projected == 0 || actual == 0 {
+		return 0
+	}
+	return (projected - actual) / actual * 100
+}
+
+// ProjectionAccuracy is the mean absolute error, in percent, of the
+// projections made between MinBlocksBefore and MaxBlocksBefore blocks before
+// the retarget
+type ProjectionAccuracy struct {
+	MinBlocksBefore        int64   `json:"min_blocks_before"`
+	MaxBlocksBefore        int64   `json:"max_blocks_before"`
+	Projections            int64   `json:"projections"`
+	DifficultyProjections  int64   `json:"difficulty_projections"`
+	DifficultyError        float64 `json:"difficulty_error"`
+	TicketPriceProjections int64   `json:"ticket_price_projections"`
+	TicketPriceError       float64 `json:"ticket_price_error"`
+}
+
+type ProjectionStore interface {
+	// LastChainBlock returns the stored block with the greatest height,
+	// nil when there is none
+	LastChainBlock(ctx context.Context) (*Block, error)
+	// ChainBlocks returns the stored blocks from height, lowest first
+	ChainBlocks(ctx context.Context, from int64) ([]Block, error)
+	// LastProjection returns the most recent projection, nil when there
+	// is none
+	LastProjection(ctx context.Context) (*Projection, error)
+	StoreProjection(ctx context.Context, projection Projection) error
+	// ResolveProjections sets the actual values of the projections of the
+	// retargets that were recorded
+	ResolveProjections(ctx context.Context) error
+}
+
+// Projector projects the next retarget from every new block recorded by the
+// collector, and compares the projections to the retargets once they
+// happen.
+type Projector struct {
+	period time.Duration
+	store  ProjectionStore
+}
+
+// NewProjector creates a projector checking for new blocks every period
+func NewProjector(period time.Duration, store ProjectionStore) (*Projector, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("Projection interval must be positive, got %v", period)
+	}
+	return &Projector{period: period, store: store}, nil
+}
+
+// Run projects the retargets until ctx is cancelled
+func (p *Projector) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(p.period)
+	defer ticker.Stop()
+	for {
+		if err := p.update(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("Unable to project the retarget: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *Projector) update(ctx context.Context) error {
+	if err := p.store.ResolveProjections(ctx); err != nil {
+		return err
+	}
+
+	last, err := p.store.LastProjection(ctx)
+	if err != nil {
+		return err
+	}
+	tip, err := p.store.LastChainBlock(ctx)
+	if err != nil {
+		return err
+	}
+	if tip == nil || last != nil && tip.Height <= last.Height {
+		return nil
+	}
+	blocks, err := p.store.ChainBlocks(ctx, tip.Height-projectionHistory)
+	if err != nil {
+		return err
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	projection, err := Project(blocks)
+	if err != nil {
+		return err
+	}
+	if err = p.store.StoreProjection(ctx, projection); err != nil {
+		return err
+	}
+	log.Infof("Projected difficulty %.0f and ticket price %.2f DCR at %d from block %d",
+		projection.ProjectedDifficulty, projection.ProjectedTicketPrice, projection.RetargetHeight, projection.Height)
+	return nil
+}
+
+// Project projects the next retarget from blocks, lowest first, ending with
+// the tip. Only the consecutive blocks ending with the tip are used, the
+// collector leaves gaps when it was stopped for longer than its history.
+func Project(blocks []Block) (Projection, error) {
+	if len(blocks) == 0 {
+		return Projection{}, fmt.Errorf("no blocks to project from")
+	}
+	for i := len(blocks) - 1; i > 0; i-- {
+		if blocks[i].Height != blocks[i-1].Height+1 {
+			blocks = blocks[i:]
+			break
+		}
+	}
+
+	tip := blocks[len(blocks)-1]
+	var ticketPrice float64
+	if tip.TicketPrice != nil {
+		ticketPrice, _ = tip.TicketPrice.Float64()
+	}
+	projection := Projection{
+		RetargetHeight: (tip.Height/RetargetInterval + 1) * RetargetInterval,
+		Height:         tip.Height,
+		Time:           tip.Time,
+		Difficulty:     tip.Difficulty,
+		TicketPrice:    ticketPrice,
+	}
+
+	at := func(height int64) (Block, bool) {
+		i := height - blocks[0].Height
+		if i < 0 || i >= int64(len(blocks)) {
+			return Block{}, false
+		}
+		return blocks[i], true
+	}
+
+	if projection.RetargetHeight < asertActivationHeight {
+		projection.ProjectedDifficulty = projectDifficulty(tip, projection.RetargetHeight, at)
+	}
+	projection.ProjectedTicketPrice = projectTicketPrice(tip, ticketPrice, projection.RetargetHeight, at)
+	return projection, nil
+}
+
+// projectDifficulty projects the PoW difficulty at a retarget before the
+// ASERT activation. The difficulty
+// is changed by the weighted average of the ratios of the time the last
+// intervals took to their target time, the interval ending at the retarget
+// extrapolated from its blocks so far. Intervals before the known blocks are
+// taken as on target.
+func projectDifficulty(tip Block, retarget int64, at func(int64) (Block, bool)) float64 {
+	targetTimespan := float64(RetargetInterval) * targetBlockTime.Seconds()
+	end := retarget - 1
+
+	var weightedRatios, weights float64
+	for window := int64(0); window < workDiffWindows; window++ {
+		weight := math.Exp2(float64(workDiffWindows - window))
+		weights += weight
+
+		recentHeight := end - window*RetargetInterval
+		olderHeight := recentHeight - RetargetInterval
+		ratio := 1.0
+		older, olderFound := at(olderHeight)
+		if window == 0 {
+			if olderFound {
+				elapsed := float64(tip.Time - older.Time)
+				ratio = elapsed * RetargetInterval / float64(tip.Height-olderHeight) / targetTimespan
+			}
+		} else if recent, found := at(recentHeight); found && olderFound {
+			ratio = float64(recent.Time-older.Time) / targetTimespan
+		}
+		weightedRatios += weight * ratio
+	}
+
+	ratio := weightedRatios / weights
+	if ratio > maxRetargetFactor {
+		ratio = maxRetargetFactor
+	} else if ratio < 1.0/maxRetargetFactor {
+		ratio = 1.0 / maxRetargetFactor
+	}
+	// A longer time than targeted lowers the difficulty
+	return tip.Difficulty / ratio
+}
+
+// projectTicketPrice projects the ticket price at retarget, in DCR, from the
+// live and immature tickets at the previous retarget and those at the tip,
+// extrapolated to the retarget. It returns 0 when the fresh stake of a
+// block it needs is unknown.
+func projectTicketPrice(tip Block, ticketPrice float64, retarget int64, at func(int64) (Block, bool)) float64 {
+	prevHeight := retarget - RetargetInterval - 1
+	prevPoolSizeAll, ok := poolSizeAll(prevHeight, at)
+	if !ok || prevPoolSizeAll == 0 {
+		return 0
+	}
+	tipPoolSizeAll, ok := poolSizeAll(tip.Height, at)
+	if !ok {
+		return 0
+	}
+
+	// The change of the tickets per block since the previous retarget
+	// continues until the block before the retarget
+	perBlock := float64(tipPoolSizeAll-prevPoolSizeAll) / float64(tip.Height-prevHeight)
+	curPoolSizeAll := float64(tipPoolSizeAll) + perBlock*float64(retarget-1-tip.Height)
+
+	nextDiff := ticketPrice * atomsPerCoin * curPoolSizeAll / float64(prevPoolSizeAll) * curPoolSizeAll /
+		targetPoolSizeAll
+	if maximum := float64(estimateSupply(retarget)) / ticketPoolSize; nextDiff > maximum {
+		nextDiff = maximum
+	}
+	if nextDiff < minimumStakeDiff {
+		nextDiff = minimumStakeDiff
+	}
+	return math.Floor(nextDiff) / atomsPerCoin
+}
+
+// poolSizeAll returns the number of live and immature tickets after the block
+// at height, false when a block it needs is unknown
+func poolSizeAll(height int64, at func(int64) (Block, bool)) (int64, bool) {
+	block, found := at(height)
+	if !found {
+		return 0, false
+	}
+	all := block.PoolSize
+	for h := height - ticketMaturity + 1; h <= height; h++ {
+		if h < 0 {
+			continue
+		}
+		b, found := at(h)
+		if !found || b.FreshStake < 0 {
+			return 0, false
+		}
+		all += int64(b.FreshStake)
+	}
+	return all, true
+}
+
+// estimateSupply estimates the supply of coins, in atoms, after the block at
+// height
+func estimateSupply(height int64) int64 {
+	if height <= 0 {
+		return 0
+	}
+	supply := int64(blockOneSubsidy)
+	reductions := height / subsidyReductionInterval
+	for i := int64(0); i < reductions; i++ {
+		supply += subsidyReductionInterval * pow.BlockSubsidy(i*subsidyReductionInterval)
+	}
+	supply += (1 + height%subsidyReductionInterval) * pow.BlockSubsidy(height)
+	// Blocks 0 and 1 have special subsidies, already added
+	return supply - 2*pow.BlockSubsidy(0)
+}
+
+// Accuracy computes the accuracy of the resolved projections, grouped by the
+// number of blocks they were made before the retarget, from the furthest.
+// Groups without projections are left out.
+func Accuracy(projections []Projection) []ProjectionAccuracy {
+	size := int64(RetargetInterval / accuracyBuckets)
+	buckets := make([]ProjectionAccuracy, accuracyBuckets)
+	for i := range buckets {
+		buckets[i].MinBlocksBefore = RetargetInterval - int64(i+1)*size + 1
+		buckets[i].MaxBlocksBefore = RetargetInterval - int64(i)*size
+	}
+
+	for _, p := range projections {
+		if p.ActualDifficulty == 0 {
+			continue
+		}
+		i := (RetargetInterval - p.BlocksBefore()) / size
+		if i < 0 || i >= accuracyBuckets {
+			continue
+		}
+		b := &buckets[i]
+		b.Projections++
+		if p.ProjectedDifficulty > 0 {
+			b.DifficultyProjections++
+			b.DifficultyError += math.Abs(p.DifficultyError())
+		}
+		if p.ProjectedTicketPrice > 0 && p.ActualTicketPrice > 0 {
+			b.TicketPriceProjections++
+			b.TicketPriceError += math.Abs(p.TicketPriceError())
+		}
+	}
+
+	accuracy := make([]ProjectionAccuracy, 0, accuracyBuckets)
+	for _, b := range buckets {
+		if b.Projections == 0 {
+			continue
+		}
+		if b.DifficultyProjections > 0 {
+			b.DifficultyError /= float64(b.DifficultyProjections)
+		}
+		if b.TicketPriceProjections > 0 {
+			b.TicketPriceError /= float64(b.TicketPriceProjections)
+		}
+		accuracy = append(accuracy, b)
+	}
+	return accuracy
+}
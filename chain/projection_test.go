@@ -0,0 +1,292 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chain
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ericlagergren/decimal"
+)
+
+// testChain returns the blocks from 0 to tip, mined blockTime seconds apart,
+// with freshStake tickets bought in each block and 100 DCR tickets
+func testChain(tip, blockTime int64, freshStake int) []Block {
+	blocks := make([]Block, tip+1)
+	for h := range blocks {
+		blocks[h] = Block{
+			Height:      int64(h),
+			Time:        1454954400 + int64(h)*blockTime,
+			Difficulty:  1e10,
+			TicketPrice: decimal.New(100, 0),
+			PoolSize:    40960,
+			FreshStake:  freshStake,
+		}
+	}
+	return blocks
+}
+
+func closeTo(value, expected float64) bool {
+	return math.Abs(value-expected) <= math.Abs(expected)*1e-9
+}
+
+func TestProjectDifficulty(t *testing.T) {
+	tests := []struct {
+		name      string
+		blockTime int64
+		expected  float64
+	}{
+		{"on target", 300, 1e10},
+		{"twice slower", 600, 0.5e10},
+		{"limited", 6000, 0.25e10},
+	}
+	for _, test := range tests {
+		blocks := testChain(30000, test.blockTime, 5)
+		projection, err := Project(blocks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if projection.RetargetHeight != 30096 || projection.Height != 30000 {
+			t.Errorf("%s: projected %d from %d", test.name, projection.RetargetHeight, projection.Height)
+		}
+		if !closeTo(projection.ProjectedDifficulty, test.expected) {
+			t.Errorf("%s: projected difficulty %f, expected %f", test.name, projection.ProjectedDifficulty,
+				test.expected)
+		}
+	}
+
+	// Only the last interval, weighing half, is known to be twice slower
+	blocks := testChain(30000, 300, 5)[30000-144:]
+	for i := range blocks {
+		blocks[i].Time = 1454954400 + int64(i)*600
+	}
+	projection, err := Project(blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weights := math.Exp2(21) - 2
+	expected := 1e10 / ((math.Exp2(20)*2 + weights - math.Exp2(20)) / weights)
+	if !closeTo(projection.ProjectedDifficulty, expected) {
+		t.Errorf("projected difficulty %f, expected %f", projection.ProjectedDifficulty, expected)
+	}
+}
+
+func TestProjectAfterASERT(t *testing.T) {
+	blocks := testChain(projectionHistory, 300, 5)
+	for i := range blocks {
+		blocks[i].Height += 794468 - projectionHistory
+	}
+	projection, err := Project(blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projection.RetargetHeight != 794592 || projection.ProjectedDifficulty != 0 ||
+		projection.ProjectedTicketPrice == 0 {
+		t.Errorf("unexpected projection %+v", projection)
+	}
+}
+
+func TestProjectTicketPrice(t *testing.T) {
+	// A full pool stays at the same price
+	projection, err := Project(testChain(30000, 300, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closeTo(projection.ProjectedTicketPrice, 100) {
+		t.Errorf("projected ticket price %f, expected 100", projection.ProjectedTicketPrice)
+	}
+
+	// A pool growing by one ticket per block since the previous retarget
+	blocks := testChain(30000, 300, 5)
+	for i := 30096 - 145; i <= 30000; i++ {
+		blocks[i].PoolSize += int64(i - (30096 - 145))
+	}
+	projection, err = Project(blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur := float64(42240 + 144)
+	expected := math.Floor(100*1e8*cur/42240*cur/42240) / 1e8
+	if !closeTo(projection.ProjectedTicketPrice, expected) {
+		t.Errorf("projected ticket price %f, expected %f", projection.ProjectedTicketPrice, expected)
+	}
+
+	// An empty pool is limited to the minimum price
+	blocks = testChain(30000, 300, 0)
+	for i := range blocks {
+		blocks[i].PoolSize = 100
+	}
+	if projection, err = Project(blocks); err != nil {
+		t.Fatal(err)
+	}
+	if projection.ProjectedTicketPrice != 2 {
+		t.Errorf("projected ticket price %f, expected 2", projection.ProjectedTicketPrice)
+	}
+
+	// The price is not projected without the fresh stake
+	blocks = testChain(30000, 300, 5)
+	blocks[29900].FreshStake = -1
+	if projection, err = Project(blocks); err != nil {
+		t.Fatal(err)
+	}
+	if projection.ProjectedTicketPrice != 0 || projection.ProjectedDifficulty == 0 {
+		t.Errorf("unexpected projection %+v", projection)
+	}
+}
+
+func TestProjectMissingBlocks(t *testing.T) {
+	// The blocks before the gap are not used, leaving too few for the
+	// ticket price
+	blocks := testChain(300, 300, 5)
+	blocks = append(blocks[:100], blocks[101:]...)
+	projection, err := Project(blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projection.Height != 300 || projection.ProjectedTicketPrice != 0 || !closeTo(projection.ProjectedDifficulty, 1e10) {
+		t.Errorf("unexpected projection %+v", projection)
+	}
+	if _, err := Project(nil); err == nil {
+		t.Error("projected from no blocks")
+	}
+}
+
+func TestEstimateSupply(t *testing.T) {
+	// Block 1 and the subsidy of block 2
+	if supply := estimateSupply(2); supply != 168000000000000+3119582664 {
+		t.Errorf("supply at 2 is %d", supply)
+	}
+	if supply := estimateSupply(6144); supply <= estimateSupply(6143) {
+		t.Errorf("supply at 6144 is %d", supply)
+	}
+}
+
+func TestAccuracy(t *testing.T) {
+	projections := []Projection{
+		{RetargetHeight: 288, Height: 144, ProjectedDifficulty: 110, ActualDifficulty: 100,
+			ProjectedTicketPrice: 90, ActualTicketPrice: 100},
+		{RetargetHeight: 288, Height: 150, ProjectedDifficulty: 130, ActualDifficulty: 100,
+			ActualTicketPrice: 100},
+		{RetargetHeight: 288, Height: 287, ProjectedDifficulty: 99, ActualDifficulty: 100,
+			ProjectedTicketPrice: 101, ActualTicketPrice: 100},
+		// Not resolved yet
+		{RetargetHeight: 432, Height: 300, ProjectedDifficulty: 99},
+		// The PoW difficulty is not projected with ASERT
+		{RetargetHeight: 794448, Height: 794447, ActualDifficulty: 100, ProjectedTicketPrice: 99,
+			ActualTicketPrice: 100},
+	}
+	accuracy := Accuracy(projections)
+	if len(accuracy) != 2 {
+		t.Fatalf("accuracy %+v", accuracy)
+	}
+	furthest, closest := accuracy[0], accuracy[1]
+	if furthest.MinBlocksBefore != 109 || furthest.MaxBlocksBefore != 144 || furthest.Projections != 2 ||
+		furthest.DifficultyProjections != 2 || !closeTo(furthest.DifficultyError, 20) || furthest.TicketPriceProjections != 1 ||
+		!closeTo(furthest.TicketPriceError, 10) {
+		t.Errorf("unexpected accuracy %+v", furthest)
+	}
+	if closest.MinBlocksBefore != 1 || closest.MaxBlocksBefore != 36 || closest.Projections != 2 ||
+		closest.DifficultyProjections != 1 || !closeTo(closest.DifficultyError, 1) ||
+		closest.TicketPriceProjections != 2 || !closeTo(closest.TicketPriceError, 1) {
+		t.Errorf("unexpected accuracy %+v", closest)
+	}
+}
+
+type memoryProjectionStore struct {
+	blocks      []Block
+	projections []Projection
+}
+
+func (s *memoryProjectionStore) LastChainBlock(ctx context.Context) (*Block, error) {
+	if len(s.blocks) == 0 {
+		return nil, nil
+	}
+	return &s.blocks[len(s.blocks)-1], nil
+}
+
+func (s *memoryProjectionStore) ChainBlocks(ctx context.Context, from int64) ([]Block, error) {
+	for i, b := range s.blocks {
+		if b.Height >= from {
+			return s.blocks[i:], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryProjectionStore) LastProjection(ctx context.Context) (*Projection, error) {
+	if len(s.projections) == 0 {
+		return nil, nil
+	}
+	return &s.projections[len(s.projections)-1], nil
+}
+
+func (s *memoryProjectionStore) StoreProjection(ctx context.Context, projection Projection) error {
+	s.projections = append(s.projections, projection)
+	return nil
+}
+
+func (s *memoryProjectionStore) ResolveProjections(ctx context.Context) error {
+	for i, p := range s.projections {
+		for _, b := range s.blocks {
+			if b.Height == p.RetargetHeight {
+				s.projections[i].ActualDifficulty = b.Difficulty
+				s.projections[i].ActualTicketPrice, _ = b.TicketPrice.Float64()
+			}
+		}
+	}
+	return nil
+}
+
+func TestProjectorUpdate(t *testing.T) {
+	chain := testChain(400, 300, 5)
+	store := &memoryProjectionStore{blocks: chain[:287]}
+	p, err := NewProjector(time.Minute, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err = p.update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Without new blocks, there is no new projection
+	if err = p.update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.projections) != 1 || store.projections[0].Height != 286 {
+		t.Fatalf("projections %+v", store.projections)
+	}
+
+	store.blocks = chain
+	if err = p.update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.projections) != 2 || store.projections[1].Height != 400 {
+		t.Fatalf("projections %+v", store.projections)
+	}
+	first := store.projections[0]
+	if first.ActualDifficulty != 1e10 || first.ActualTicketPrice != 100 || store.projections[1].ActualDifficulty != 0 {
+		t.Errorf("unexpected resolution %+v", store.projections)
+	}
+
+	// After a downtime the stored blocks have a gap within the history
+	chain = testChain(6000, 300, 5)
+	store.blocks = append(chain[:3001:3001], chain[4000:]...)
+	if err = p.update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.projections) != 3 || store.projections[2].Height != 6000 ||
+		!closeTo(store.projections[2].ProjectedDifficulty, 1e10) {
+		t.Fatalf("projections %+v", store.projections)
+	}
+
+	if _, err = NewProjector(0, store); err == nil {
+		t.Error("zero interval accepted")
+	}
+}
@@ -0,0 +1,436 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/crypto/blake256"
+)
+
+const (
+	// blockHeaderSize is the size of a serialized block header, and
+	// heightOffset the offset of the height in it
+	blockHeaderSize = 180
+	heightOffset    = 128
+
+	// voteScriptSize is the size of the first output script of a vote, an
+	// OP_RETURN pushing the hash and height of the voted block
+	voteScriptSize = 38
+	opReturn       = 0x6a
+	opData36       = 0x24
+
+	// flushInterval is the interval between checks for blocks and votes
+	// every node saw, or that settled
+	flushInterval = 5 * time.Second
+
+	// defaultReconnectDelay is the time waited before reconnecting to a node
+	defaultReconnectDelay = 10 * time.Second
+)
+
+// NodeConfig is a dcrd node propagation is measured on. Name identifies it
+// in the stored delays, e.g. by its region.
+type NodeConfig struct {
+	Name  string `json:"name"`
+	Host  string `json:"host"`
+	User  string `json:"user"`
+	Pass  string `json:"pass"`
+	Cert  string `json:"cert"`
+	NoTLS bool   `json:"notls"`
+}
+
+// LoadNodes reads a JSON array of node definitions from a file
+func LoadNodes(path string) ([]NodeConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []NodeConfig
+	if err = json.Unmarshal(data, &nodes); err != nil {
+		return nil, fmt.Errorf("invalid node definitions in %s: %v", path, err)
+	}
+	return nodes, nil
+}
+
+// BlockPropagation is the time a node first saw a block. Delay is the time
+// since the first node saw it.
+type BlockPropagation struct {
+	Hash   string        `json:"hash"`
+	Height int64         `json:"height"`
+	Node   string        `json:"node"`
+	SeenAt time.Time     `json:"seen_at"`
+	Delay  time.Duration `json:"delay"`
+}
+
+// VotePropagation is the time a node first saw a vote on the block
+// BlockHash. Delay is the time since the first node saw the vote.
+type VotePropagation struct {
+	Hash        string        `json:"hash"`
+	BlockHash   string        `json:"block_hash"`
+	BlockHeight int64         `json:"block_height"`
+	Node        string        `json:"node"`
+	SeenAt      time.Time     `json:"seen_at"`
+	Delay       time.Duration `json:"delay"`
+}
+
+// NodeDelays sums up the delays of a node, from the time the first node saw
+// the blocks and votes
+type NodeDelays struct {
+	Node             string        `json:"node"`
+	Blocks           int64         `json:"blocks"`
+	AverageDelay     time.Duration `json:"average_delay"`
+	MaxDelay         time.Duration `json:"max_delay"`
+	Votes            int64         `json:"votes"`
+	AverageVoteDelay time.Duration `json:"average_vote_delay"`
+}
+
+type PropagationStore interface {
+	StoreBlockPropagation(ctx context.Context, propagation []BlockPropagation) error
+	StoreVotePropagation(ctx context.Context, propagation []VotePropagation) error
+}
+
+// sighting collects the times the nodes first saw a block or a vote
+type sighting struct {
+	vote      bool
+	hash      string
+	blockHash string
+	height    int64
+	first     time.Time
+	seen      map[string]time.Time
+}
+
+// PropagationMonitor records the time every node first saw each new block,
+// and optionally each vote, from the notifications of the nodes.
+type PropagationMonitor struct {
+	clients        map[string]*Client
+	votes          bool
+	settle         time.Duration
+	reconnectDelay time.Duration
+	store          PropagationStore
+
+	mtx       sync.Mutex
+	sightings map[string]*sighting
+	// flushed holds the keys of the stored sightings until they are old
+	// enough for the nodes to no longer notify them
+	flushed map[string]time.Time
+}
+
+// NewPropagationMonitor creates a monitor of the nodes. A block or vote is
+// stored once every node saw it, or settle after the first node saw it, and
+// the nodes that did not see it by then are left out.
+func NewPropagationMonitor(nodes []NodeConfig, votes bool, settle time.Duration, store PropagationStore) (*PropagationMonitor, error) {
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("No nodes to measure propagation on")
+	}
+	if settle <= 0 {
+		return nil, fmt.Errorf("Propagation settle time must be positive, got %v", settle)
+	}
+
+	m := &PropagationMonitor{
+		clients:        make(map[string]*Client, len(nodes)),
+		votes:          votes,
+		settle:         settle,
+		reconnectDelay: defaultReconnectDelay,
+		store:          store,
+		sightings:      make(map[string]*sighting),
+		flushed:        make(map[string]time.Time),
+	}
+	for _, node := range nodes {
+		if node.Name == "" {
+			return nil, fmt.Errorf("Node %s has no name", node.Host)
+		}
+		if _, found := m.clients[node.Name]; found {
+			return nil, fmt.Errorf("Node name %s is used twice", node.Name)
+		}
+		client, err := NewClient(RPCConfig{Host: node.Host, User: node.User, Pass: node.Pass, Cert: node.Cert,
+			NoTLS: node.NoTLS})
+		if err != nil {
+			return nil, fmt.Errorf("Node %s: %v", node.Name, err)
+		}
+		name := node.Name
+		client.OnNotification(func(n Notification) {
+			m.handle(name, n)
+		})
+		m.clients[name] = client
+	}
+	return m, nil
+}
+
+// Run watches the nodes until ctx is cancelled, then stores what they saw
+func (m *PropagationMonitor) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	nodesWg := new(sync.WaitGroup)
+	for name, client := range m.clients {
+		nodesWg.Add(1)
+		go m.watch(ctx, name, client, nodesWg)
+	}
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			if err := m.flush(ctx, now, false); err != nil && ctx.Err() == nil {
+				log.Errorf("Unable to store propagation: %v", err)
+			}
+		case <-ctx.Done():
+			nodesWg.Wait()
+			// The context is done, store with a fresh one
+			if err := m.flush(context.Background(), time.Now(), true); err != nil {
+				log.Errorf("Unable to store propagation: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// watch subscribes to the notifications of a node, again whenever the
+// connection is lost, until ctx is cancelled
+func (m *PropagationMonitor) watch(ctx context.Context, name string, client *Client, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer client.Close()
+
+	for {
+		lost, err := m.subscribe(ctx, client)
+		if err == nil {
+			log.Infof("Watching the blocks seen by %s", name)
+			select {
+			case <-lost:
+				log.Warnf("Lost the connection to %s, reconnecting in %v", name, m.reconnectDelay)
+			case <-ctx.Done():
+				return
+			}
+		} else if ctx.Err() == nil {
+			log.Errorf("Unable to watch %s, retrying in %v: %v", name, m.reconnectDelay, err)
+		}
+
+		select {
+		case <-time.After(m.reconnectDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// subscribe requests the notifications of new blocks, and of new
+// transactions when votes are watched
+func (m *PropagationMonitor) subscribe(ctx context.Context, client *Client) (<-chan struct{}, error) {
+	lost, err := client.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = client.Call(ctx, "notifyblocks", nil); err != nil {
+		return nil, err
+	}
+	if m.votes {
+		if err = client.Call(ctx, "notifynewtransactions", nil, true); err != nil {
+			return nil, err
+		}
+	}
+	return lost, nil
+}
+
+// handle records the block or vote of a notification of node
+func (m *PropagationMonitor) handle(node string, n Notification) {
+	switch n.Method {
+	case "blockconnected":
+		var header string
+		if len(n.Params) == 0 || json.Unmarshal(n.Params[0], &header) != nil {
+			log.Warnf("Invalid block notification from %s", node)
+			return
+		}
+		hash, height, err := parseHeader(header)
+		if err != nil {
+			log.Warnf("Invalid block notification from %s: %v", node, err)
+			return
+		}
+		m.saw(node, sighting{hash: hash, height: height}, n.Received)
+
+	case "txacceptedverbose":
+		if !m.votes || len(n.Params) == 0 {
+			return
+		}
+		vote, isVote, err := parseVote(n.Params[0])
+		if err != nil {
+			log.Warnf("Invalid transaction notification from %s: %v", node, err)
+			return
+		}
+		if isVote {
+			m.saw(node, vote, n.Received)
+		}
+	}
+}
+
+// saw records that node saw s at the given time, unless it saw it before or
+// s was stored
+func (m *PropagationMonitor) saw(node string, s sighting, at time.Time) {
+	key := s.hash
+	if s.vote {
+		key = "vote:" + key
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if _, flushed := m.flushed[key]; flushed {
+		log.Debugf("%s saw %s after it settled", node, s.hash)
+		return
+	}
+	existing, found := m.sightings[key]
+	if !found {
+		s.first = at
+		s.seen = make(map[string]time.Time, len(m.clients))
+		existing = &s
+		m.sightings[key] = existing
+	}
+	if _, seen := existing.seen[node]; seen {
+		return
+	}
+	existing.seen[node] = at
+	if at.Before(existing.first) {
+		existing.first = at
+	}
+}
+
+// flush stores the blocks and votes every node saw or that settled, all of
+// them when force is set. Sightings that could not be stored are kept for the
+// next flush.
+func (m *PropagationMonitor) flush(ctx context.Context, now time.Time, force bool) error {
+	var blocks []BlockPropagation
+	var votes []VotePropagation
+	taken := make(map[string]*sighting)
+
+	m.mtx.Lock()
+	for key, s := range m.sightings {
+		if !force && len(s.seen) < len(m.clients) && now.Sub(s.first) < m.settle {
+			continue
+		}
+		if len(s.seen) < len(m.clients) {
+			log.Debugf("%s was seen by %d of %d nodes", s.hash, len(s.seen), len(m.clients))
+		}
+		for node, at := range s.seen {
+			if s.vote {
+				votes = append(votes, VotePropagation{Hash: s.hash, BlockHash: s.blockHash, BlockHeight: s.height,
+					Node: node, SeenAt: at, Delay: at.Sub(s.first)})
+			} else {
+				blocks = append(blocks, BlockPropagation{Hash: s.hash, Height: s.height, Node: node, SeenAt: at,
+					Delay: at.Sub(s.first)})
+			}
+		}
+		taken[key] = s
+		delete(m.sightings, key)
+		m.flushed[key] = now
+	}
+	for key, at := range m.flushed {
+		if now.Sub(at) > 10*m.settle {
+			delete(m.flushed, key)
+		}
+	}
+	m.mtx.Unlock()
+
+	if err := m.storePropagation(ctx, blocks, votes); err != nil {
+		// Put the sightings back for the next flush, the rows that were
+		// stored are not duplicated
+		m.mtx.Lock()
+		for key, s := range taken {
+			delete(m.flushed, key)
+			m.sightings[key] = s
+		}
+		m.mtx.Unlock()
+		return err
+	}
+	return nil
+}
+
+// storePropagation stores the block and vote propagation, earliest first
+func (m *PropagationMonitor) storePropagation(ctx context.Context, blocks []BlockPropagation, votes []VotePropagation) error {
+	if len(blocks) > 0 {
+		sort.Slice(blocks, func(i, j int) bool {
+			return blocks[i].SeenAt.Before(blocks[j].SeenAt)
+		})
+		if err := m.store.StoreBlockPropagation(ctx, blocks); err != nil {
+			return err
+		}
+	}
+	if len(votes) > 0 {
+		sort.Slice(votes, func(i, j int) bool {
+			return votes[i].SeenAt.Before(votes[j].SeenAt)
+		})
+		if err := m.store.StoreVotePropagation(ctx, votes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseHeader returns the hash and height of a hex encoded block header
+func parseHeader(header string) (string, int64, error) {
+	b, err := hex.DecodeString(header)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(b) != blockHeaderSize {
+		return "", 0, fmt.Errorf("header of %d bytes, expected %d", len(b), blockHeaderSize)
+	}
+	height := binary.LittleEndian.Uint32(b[heightOffset:])
+	hash := blake256.Sum256(b)
+	return reversedHex(hash[:]), int64(height), nil
+}
+
+// voteTx is the part of a verbose transaction identifying votes
+type voteTx struct {
+	Txid string `json:"txid"`
+	Vin  []struct {
+		Stakebase string `json:"stakebase"`
+	} `json:"vin"`
+	Vout []struct {
+		ScriptPubKey struct {
+			Hex string `json:"hex"`
+		} `json:"scriptPubKey"`
+	} `json:"vout"`
+}
+
+// parseVote parses a verbose transaction, and returns the sighting of the
+// vote when it is one. The first input of a vote is a stakebase, and its
+// first output commits to the voted block.
+func parseVote(raw json.RawMessage) (sighting, bool, error) {
+	var tx voteTx
+	if err := json.Unmarshal(raw, &tx); err != nil {
+		return sighting{}, false, err
+	}
+	if len(tx.Vin) == 0 || tx.Vin[0].Stakebase == "" {
+		return sighting{}, false, nil
+	}
+	if len(tx.Vout) == 0 {
+		return sighting{}, false, fmt.Errorf("vote %s has no outputs", tx.Txid)
+	}
+	script, err := hex.DecodeString(tx.Vout[0].ScriptPubKey.Hex)
+	if err != nil || len(script) != voteScriptSize || script[0] != opReturn || script[1] != opData36 {
+		return sighting{}, false, fmt.Errorf("invalid block commitment of vote %s", tx.Txid)
+	}
+	return sighting{
+		vote:      true,
+		hash:      tx.Txid,
+		blockHash: reversedHex(script[2:34]),
+		height:    int64(binary.LittleEndian.Uint32(script[34:])),
+	}, true, nil
+}
+
+// reversedHex encodes b in reverse order, the order hashes are shown in
+func reversedHex(b []byte) string {
+	reversed := make([]byte, len(b))
+	for i := range b {
+		reversed[len(b)-1-i] = b[i]
+	}
+	return hex.EncodeToString(reversed)
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/crypto/blake256"
+)
+
+type memoryPropagationStore struct {
+	mtx    sync.Mutex
+	blocks []BlockPropagation
+	votes  []VotePropagation
+	// err fails the stores while it is set
+	err error
+}
+
+func (s *memoryPropagationStore) StoreBlockPropagation(ctx context.Context, propagation []BlockPropagation) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.blocks = append(s.blocks, propagation...)
+	return nil
+}
+
+func (s *memoryPropagationStore) StoreVotePropagation(ctx context.Context, propagation []VotePropagation) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.votes = append(s.votes, propagation...)
+	return nil
+}
+
+// testHeader returns a hex encoded block header at height and its hash
+func testHeader(height uint32) (string, string) {
+	header := make([]byte, blockHeaderSize)
+	binary.LittleEndian.PutUint32(header, 7)
+	binary.LittleEndian.PutUint32(header[heightOffset:], height)
+	hash := blake256.Sum256(header)
+	return hex.EncodeToString(header), reversedHex(hash[:])
+}
+
+// testVote returns a verbose vote on the block with hash at height
+func testVote(txid, blockHash string, height uint32) map[string]interface{} {
+	hash, _ := hex.DecodeString(blockHash)
+	script := []byte{opReturn, opData36}
+	for i := len(hash) - 1; i >= 0; i-- {
+		script = append(script, hash[i])
+	}
+	script = append(script, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(script[34:], height)
+	return map[string]interface{}{
+		"txid": txid,
+		"vin":  []interface{}{map[string]interface{}{"stakebase": "0000"}, map[string]interface{}{"txid": "ab"}},
+		"vout": []interface{}{map[string]interface{}{"scriptPubKey": map[string]interface{}{"hex": hex.EncodeToString(script)}}},
+	}
+}
+
+func testNodeConfig(name string, node *testNode) NodeConfig {
+	return NodeConfig{Name: name, Host: strings.TrimPrefix(node.URL, "http://"), User: testUser, Pass: testPass,
+		NoTLS: true}
+}
+
+// waitFor fails the test unless cond holds within a second
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+// subscribed returns whether every connection to node requested block
+// notifications, and there is at least one
+func (n *testNode) subscribed() bool {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	for conn := range n.conns {
+		if !conn.blocks {
+			return false
+		}
+	}
+	return len(n.conns) > 0
+}
+
+func TestParseHeader(t *testing.T) {
+	header, expectedHash := testHeader(402156)
+	hash, height, err := parseHeader(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != expectedHash || height != 402156 {
+		t.Errorf("parsed block %s at %d, expected %s at 402156", hash, height, expectedHash)
+	}
+	if _, _, err = parseHeader(header[:100]); err == nil {
+		t.Error("short header parsed")
+	}
+}
+
+func TestPropagationMonitor(t *testing.T) {
+	nodeA, nodeB := newTestNode(t), newTestNode(t)
+	store := new(memoryPropagationStore)
+	m, err := NewPropagationMonitor([]NodeConfig{testNodeConfig("a", nodeA), testNodeConfig("b", nodeB)}, true,
+		time.Minute, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.reconnectDelay = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	go m.Run(ctx, wg)
+	waitFor(t, "the subscriptions", func() bool { return nodeA.subscribed() && nodeB.subscribed() })
+
+	header, hash := testHeader(100)
+	nodeA.notify("blockconnected", header, []string{})
+	time.Sleep(20 * time.Millisecond)
+	nodeB.notify("blockconnected", header, []string{})
+	// Only the first notification of a node counts
+	nodeA.notify("blockconnected", header, []string{})
+
+	// Votes are recorded, other transactions are not
+	nodeB.notify("txacceptedverbose", testVote("v1", hash, 100))
+	nodeB.notify("txacceptedverbose", map[string]interface{}{"txid": "t1", "vin": []interface{}{map[string]interface{}{"txid": "ab"}}})
+
+	// Once every node saw the block it is stored
+	waitFor(t, "the block sightings", func() bool {
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+		s, ok := m.sightings[hash]
+		return ok && len(s.seen) == 2 && len(m.sightings) == 2
+	})
+	if err = m.flush(ctx, time.Now(), false); err != nil {
+		t.Fatal(err)
+	}
+	store.mtx.Lock()
+	if len(store.blocks) != 2 {
+		t.Fatalf("stored block propagation %+v", store.blocks)
+	}
+	first, second := store.blocks[0], store.blocks[1]
+	if first.Node != "a" || first.Delay != 0 || second.Node != "b" || second.Delay < 20*time.Millisecond {
+		t.Errorf("unexpected delays %+v", store.blocks)
+	}
+	if first.Hash != hash || first.Height != 100 {
+		t.Errorf("unexpected block %+v", first)
+	}
+	// The vote was only seen by b, it waits for a
+	if len(store.votes) != 0 {
+		t.Errorf("unsettled votes stored %+v", store.votes)
+	}
+	store.mtx.Unlock()
+
+	// A node reconnects and subscribes again after losing its connection
+	nodeB.dropConnections()
+	waitFor(t, "the new subscription", nodeB.subscribed)
+	nodeA.notify("txacceptedverbose", testVote("v1", hash, 100))
+	// Blocks seen after they were stored are ignored
+	nodeA.notify("blockconnected", header, []string{})
+	waitFor(t, "the vote sightings", func() bool {
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+		s, ok := m.sightings["vote:v1"]
+		return ok && len(s.seen) == 2
+	})
+
+	cancel()
+	wg.Wait()
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+	if len(store.blocks) != 2 {
+		t.Errorf("stored block propagation %+v", store.blocks)
+	}
+	if len(store.votes) != 2 {
+		t.Fatalf("stored vote propagation %+v", store.votes)
+	}
+	vote := store.votes[0]
+	if vote.Hash != "v1" || vote.Node != "b" || vote.BlockHash != hash || vote.BlockHeight != 100 || vote.Delay != 0 {
+		t.Errorf("unexpected vote %+v", vote)
+	}
+}
+
+func TestPropagationSettles(t *testing.T) {
+	store := new(memoryPropagationStore)
+	m, err := NewPropagationMonitor([]NodeConfig{{Name: "a", Host: "a:9109"}, {Name: "b", Host: "b:9109"}}, false,
+		time.Minute, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	m.saw("a", sighting{hash: "h", height: 1}, now)
+
+	if err = m.flush(context.Background(), now.Add(30*time.Second), false); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.blocks) != 0 {
+		t.Fatalf("unsettled block stored %+v", store.blocks)
+	}
+	// The nodes that did not see the block once it settled are left out
+	if err = m.flush(context.Background(), now.Add(time.Minute), false); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.blocks) != 1 || store.blocks[0].Node != "a" {
+		t.Errorf("stored block propagation %+v", store.blocks)
+	}
+}
+
+func TestPropagationKeptOnStoreError(t *testing.T) {
+	store := &memoryPropagationStore{err: errors.New("connection refused")}
+	m, err := NewPropagationMonitor([]NodeConfig{{Name: "a", Host: "a:9109"}, {Name: "b", Host: "b:9109"}}, false,
+		time.Minute, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	m.saw("a", sighting{hash: "h", height: 1}, now)
+	m.saw("b", sighting{hash: "h", height: 1}, now.Add(time.Second))
+
+	if err = m.flush(context.Background(), now.Add(2*time.Second), false); err == nil {
+		t.Fatal("expected the store error")
+	}
+	// The sightings are kept, and still recorded
+	m.saw("b", sighting{hash: "h", height: 1}, now.Add(3*time.Second))
+	if s, ok := m.sightings["h"]; !ok || len(s.seen) != 2 || s.seen["b"] != now.Add(time.Second) {
+		t.Fatalf("sightings %+v were not kept", m.sightings)
+	}
+
+	store.err = nil
+	if err = m.flush(context.Background(), now.Add(4*time.Second), false); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.blocks) != 2 || store.blocks[1].Delay != time.Second {
+		t.Errorf("stored block propagation %+v", store.blocks)
+	}
+	if len(m.sightings) != 0 {
+		t.Errorf("sightings %+v left after they were stored", m.sightings)
+	}
+}
+
+func TestNewPropagationMonitorValidation(t *testing.T) {
+	tests := map[string][]NodeConfig{
+		"no nodes":  nil,
+		"no name":   {{Host: "a:9109"}},
+		"same name": {{Name: "a", Host: "a:9109"}, {Name: "a", Host: "b:9109"}},
+	}
+	for name, nodes := range tests {
+		if _, err := NewPropagationMonitor(nodes, false, time.Minute, nil); err == nil {
+			t.Errorf("%s: nodes accepted", name)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chain
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrDisconnected is returned for the calls pending when the connection to
+// the node is lost
+var ErrDisconnected = errors.New("disconnected from the node")
+
+// RPCConfig locates a dcrd JSON-RPC server. Cert is the path of the
+// certificate of the server, which is trusted in addition to the system
+// roots.
+type RPCConfig struct {
+	Host  string
+	User  string
+	Pass  string
+	Cert  string
+	NoTLS bool
+}
+
+// RPCError is an error returned by the node
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%d: %s", e.Code, e.Message)
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// rpcMessage is a response to a request, or a notification when it has a
+// method and no ID
+type rpcMessage struct {
+	ID     *uint64           `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	Result json.RawMessage   `json:"result"`
+	Error  *RPCError         `json:"error"`
+}
+
+// Notification is a notification sent by the node, with the time it was
+// received
+type Notification struct {
+	Method   string
+	Params   []json.RawMessage
+	Received time.Time
+}
+
+// Client is a JSON-RPC client of the websocket endpoint of a dcrd node. It
+// connects on the first call and reconnects on the call following a lost
+// connection. It is safe for concurrent use.
+type Client struct {
+	url    string
+	header http.Header
+	dialer *websocket.Dialer
+	notify func(Notification)
+
+	mtx     sync.Mutex
+	conn    *websocket.Conn
+	lost    chan struct{}
+	nextID  uint64
+	pending map[uint64]chan *rpcMessage
+
+	// writeMtx serializes the writes to conn
+	writeMtx sync.Mutex
+}
+
+// NewClient creates a client of the node cfg locates, without connecting
+func NewClient(cfg RPCConfig) (*Client, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("dcrd RPC host is required")
+	}
+
+	dialer := &websocket.Dialer{}
+	scheme := "wss"
+	if cfg.NoTLS {
+		scheme = "ws"
+	} else if cfg.Cert != "" {
+		pem, err := ioutil.ReadFile(cfg.Cert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read dcrd RPC certificate: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", cfg.Cert)
+		}
+		dialer.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	header := make(http.Header)
+	if cfg.User != "" || cfg.Pass != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte(cfg.User + ":" + cfg.Pass))
+		header.Set("Authorization", "Basic "+auth)
+	}
+
+	return &Client{
+		url:     fmt.Sprintf("%s://%s/ws", scheme, cfg.Host),
+		header:  header,
+		dialer:  dialer,
+		pending: make(map[uint64]chan *rpcMessage),
+	}, nil
+}
+
+// Call calls method with params and decodes its result into result, unless
+// it is nil
+func (c *Client) Call(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	if params == nil {
+		params = []interface{}{}
+	}
+	c.mtx.Lock()
+	c.nextID++
+	id := c.nextID
+	respChan := make(chan *rpcMessage, 1)
+	c.pending[id] = respChan
+	c.mtx.Unlock()
+	defer func() {
+		c.mtx.Lock()
+		delete(c.pending, id)
+		c.mtx.Unlock()
+	}()
+
+	req := rpcRequest{JSONRPC: "1.0", ID: id, Method: method, Params: params}
+	c.writeMtx.Lock()
+	err = conn.WriteJSON(req)
+	c.writeMtx.Unlock()
+	if err != nil {
+		return fmt.Errorf("%s: %v", method, err)
+	}
+
+	select {
+	case resp := <-respChan:
+		if resp == nil {
+			return fmt.Errorf("%s: %v", method, ErrDisconnected)
+		}
+		if resp.Error != nil {
+			return fmt.Errorf("%s: %v", method, resp.Error)
+		}
+		if result == nil {
+			return nil
+		}
+		if err = json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("%s: %v", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OnNotification sets the handler of the notifications sent by the node,
+// which must be set before the client connects. The handler is called in the
+// order the notifications are received, from the goroutine reading the
+// connection, so it must not block nor call the node.
+func (c *Client) OnNotification(handler func(Notification)) {
+	c.notify = handler
+}
+
+// Connect connects to the node unless the client is connected, and returns
+// a channel closed when the connection is lost. Notifications requested on
+// the connection stop when it is lost.
+func (c *Client) Connect(ctx context.Context) (<-chan struct{}, error) {
+	if _, err := c.connect(ctx); err != nil {
+		return nil, err
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.lost, nil
+}
+
+// Close closes the connection to the node. A later call reconnects.
+func (c *Client) Close() error {
+	c.mtx.Lock()
+	conn := c.conn
+	c.mtx.Unlock()
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// connect returns the connection to the node, dialing it when there is none
+func (c *Client) connect(ctx context.Context) (*websocket.Conn, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.conn != nil {
+		return c.conn, nil
+	}
+
+	conn, resp, err := c.dialer.DialContext(ctx, c.url, c.header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("unable to connect to %s: %v (%s)", c.url, err, resp.Status)
+		}
+		return nil, fmt.Errorf("unable to connect to %s: %v", c.url, err)
+	}
+	log.Debugf("Connected to %s", c.url)
+	c.conn = conn
+	c.lost = make(chan struct{})
+	go c.read(conn)
+	return conn, nil
+}
+
+// read dispatches the responses and notifications received on conn until it
+// fails, then fails the pending calls
+func (c *Client) read(conn *websocket.Conn) {
+	for {
+		var msg rpcMessage
+		err := conn.ReadJSON(&msg)
+		received := time.Now()
+		if err != nil {
+			if _, ok := err.(*json.SyntaxError); ok {
+				log.Warnf("Invalid message from %s: %v", c.url, err)
+				continue
+			}
+			log.Debugf("Connection to %s lost: %v", c.url, err)
+			break
+		}
+		if msg.ID == nil {
+			if msg.Method != "" && c.notify != nil {
+				c.notify(Notification{Method: msg.Method, Params: msg.Params, Received: received})
+			}
+			continue
+		}
+		c.mtx.Lock()
+		respChan, ok := c.pending[*msg.ID]
+		c.mtx.Unlock()
+		if ok {
+			respChan <- &msg
+		}
+	}
+
+	conn.Close()
+	c.mtx.Lock()
+	if c.conn == conn {
+		c.conn = nil
+		close(c.lost)
+	}
+	for id, respChan := range c.pending {
+		close(respChan)
+		delete(c.pending, id)
+	}
+	c.mtx.Unlock()
+}
+
+// GetBlockCount returns the height of the best block of the node
+func (c *Client) GetBlockCount(ctx context.Context) (int64, error) {
+	var height int64
+	err := c.Call(ctx, "getblockcount", &height)
+	return height, err
+}
+
+// GetBlockHash returns the hash of the block at height in the best chain of
+// the node
+func (c *Client) GetBlockHash(ctx context.Context, height int64) (string, error) {
+	var hash string
+	err := c.Call(ctx, "getblockhash", &hash, height)
+	return hash, err
+}
+
+// GetBlockHeader returns the header of the block with hash
+func (c *Client) GetBlockHeader(ctx context.Context, hash string) (*BlockHeader, error) {
+	header := new(BlockHeader)
+	if err := c.Call(ctx, "getblockheader", header, hash, true); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chain
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestClientCalls(t *testing.T) {
+	node := newTestNode(t)
+	node.extend(3, "a")
+	client := node.client(t)
+	ctx := context.Background()
+
+	height, err := client.GetBlockCount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 2 {
+		t.Errorf("block count is %d, expected 2", height)
+	}
+
+	hash, err := client.GetBlockHash(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := client.GetBlockHeader(ctx, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Hash != hash || header.Height != 1 || header.SBits != "101.12345678" || header.Revocations != 1 {
+		t.Errorf("unexpected header %+v", header)
+	}
+
+	block, err := header.toBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.TicketPrice.String() != "101.12345678" {
+		t.Errorf("ticket price is %s, expected 101.12345678", block.TicketPrice)
+	}
+	if expected := 1001 * float64(1<<32) / 300; block.NetworkHashrate != expected {
+		t.Errorf("network hashrate is %v, expected %v", block.NetworkHashrate, expected)
+	}
+
+	_, err = client.GetBlockHash(ctx, 10)
+	if err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("expected an out of range error, got %v", err)
+	}
+}
+
+func TestClientReconnects(t *testing.T) {
+	node := newTestNode(t)
+	node.extend(1, "a")
+	client := node.client(t)
+	ctx := context.Background()
+
+	// A call pending when the connection is lost fails
+	if err := client.Call(ctx, "drop", nil); err == nil || !strings.Contains(err.Error(), ErrDisconnected.Error()) {
+		t.Errorf("expected a disconnection error, got %v", err)
+	}
+	if _, err := client.GetBlockCount(ctx); err != nil {
+		t.Errorf("call after the connection was lost: %v", err)
+	}
+
+	client.Close()
+	if _, err := client.GetBlockCount(ctx); err != nil {
+		t.Errorf("call after close: %v", err)
+	}
+}
+
+func TestClientUnauthorized(t *testing.T) {
+	node := newTestNode(t)
+	client, err := NewClient(RPCConfig{Host: strings.TrimPrefix(node.URL, "http://"), User: "user", Pass: "wrong",
+		NoTLS: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.GetBlockCount(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected an unauthorized error, got %v", err)
+	}
+}
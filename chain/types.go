@@ -0,0 +1,84 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ericlagergren/decimal"
+	"github.com/raedahgroup/dcrextdata/pow"
+)
+
+// BlockHeader is the verbose block header returned by dcrd's getblockheader.
+// SBits, the stake difficulty, is the ticket price in DCR.
+type BlockHeader struct {
+	Hash        string      `json:"hash"`
+	Height      int64       `json:"height"`
+	Time        int64       `json:"time"`
+	Difficulty  float64     `json:"difficulty"`
+	SBits       json.Number `json:"sbits"`
+	PoolSize    int64       `json:"poolsize"`
+	Voters      int         `json:"voters"`
+	FreshStake  int         `json:"freshstake"`
+	Revocations int         `json:"revocations"`
+}
+
+// Block is the data recorded of a block of the chain. Time is in unix
+// seconds, NetworkHashrate in H/s, derived from the difficulty, and
+// TicketPrice in DCR. FreshStake, the number of tickets bought in the block,
+// is -1 for the blocks recorded before it was.
+type Block struct {
+	Height          int64        `json:"height"`
+	Hash            string       `json:"hash"`
+	Time            int64        `json:"time"`
+	Difficulty      float64      `json:"difficulty"`
+	NetworkHashrate float64      `json:"network_hashrate"`
+	TicketPrice     *decimal.Big `json:"ticket_price"`
+	PoolSize        int64        `json:"pool_size"`
+	Voters          int          `json:"voters"`
+	FreshStake      int          `json:"fresh_stake"`
+	Revocations     int          `json:"revocations"`
+}
+
+// toBlock parses the header into a block
+func (h *BlockHeader) toBlock() (Block, error) {
+	ticketPrice, ok := new(decimal.Big).SetString(h.SBits.String())
+	if !ok {
+		return Block{}, fmt.Errorf("invalid stake difficulty %q of block %d", h.SBits, h.Height)
+	}
+	return Block{
+		Height:          h.Height,
+		Hash:            h.Hash,
+		Time:            h.Time,
+		Difficulty:      h.Difficulty,
+		NetworkHashrate: pow.DifficultyHashrate(h.Difficulty),
+		TicketPrice:     ticketPrice,
+		PoolSize:        h.PoolSize,
+		Voters:          h.Voters,
+		FreshStake:      h.FreshStake,
+		Revocations:     h.Revocations,
+	}, nil
+}
+
+// Node is the part of the dcrd RPC API the collector uses
+type Node interface {
+	GetBlockCount(ctx context.Context) (int64, error)
+	GetBlockHash(ctx context.Context, height int64) (string, error)
+	GetBlockHeader(ctx context.Context, hash string) (*BlockHeader, error)
+}
+
+type Store interface {
+	// LastChainBlock returns the stored block with the greatest height,
+	// nil when there is none
+	LastChainBlock(ctx context.Context) (*Block, error)
+	// ChainBlockHash returns the hash of the stored block at height, an
+	// empty string when there is none
+	ChainBlockHash(ctx context.Context, height int64) (string, error)
+	// StoreChainBlocks stores consecutive blocks, replacing the stored
+	// blocks from the height of the first one
+	StoreChainBlocks(ctx context.Context, blocks []Block) error
+}
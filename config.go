@@ -25,6 +25,9 @@ const (
 type config struct {
 	configFileOptions
 	CommandLineOptions
+
+	// command is the name of the sub command to run, empty when collecting
+	command string
 }
 
 type configFileOptions struct {
@@ -43,35 +46,167 @@ type configFileOptions struct {
 	DBName string `long:"dbname" description:"Database name"`
 
 	// Http Server
-	HTTPHost string `long:"httphost" description:"HTTP server host address or IP when running godcr in http mode."`
-	HTTPPort string `long:"httpport" description:"HTTP server port when running godcr in http mode."`
+	HTTPHost   string `long:"httphost" description:"HTTP server host address or IP when running godcr in http mode."`
+	HTTPPort   string `long:"httpport" description:"HTTP server port when running godcr in http mode."`
+	AdminToken string `long:"admintoken" description:"Bearer token authorizing requests to the admin API under /api/admin, which is disabled when not set"`
 
 	// Exchange collector
 	DisableExchangeTicks bool     `long:"disablexcticks" decription:"Disables collection of ticker data from exchanges"`
 	DisabledExchanges    []string `long:"disableexchange" description:"Disable data collection for this exchange"`
+	ExchangeConcurrency  int      `long:"exchangeconcurrency" description:"Maximum number of exchanges to collect ticks from at the same time"`
+	ExchangeTimeout      int64    `long:"exchangetimeout" description:"Time in seconds after which a tick request cycle of an exchange is cancelled"`
+	RESTExchanges        string   `long:"restexchanges" description:"Path of a JSON file defining additional exchanges with a REST candle API"`
+
+	// Exchange health
+	DisableHealth bool  `long:"disablehealth" description:"Disables detection of exchange outages and stale markets"`
+	StaleAfter    int64 `long:"staleafter" description:"Seconds without a new candle after which a market is considered stale"`
+	DownAfter     int64 `long:"downafter" description:"Seconds of failing requests after which a market is considered down"`
+
+	// Market discovery
+	DisableDiscovery   bool  `long:"disablediscovery" description:"Disables discovery of new DCR markets on the exchanges"`
+	DiscoveryInterval  int64 `long:"discoveryinterval" description:"Interval in seconds between market discovery runs"`
+	AutoCollectMarkets bool  `long:"autocollectmarkets" description:"Start collecting discovered markets right away"`
+
+	// Exchange spread monitor
+	DisableSpreads  bool    `long:"disablespreads" description:"Disables computation of price spreads between exchanges"`
+	SpreadThreshold float64 `long:"spreadthreshold" description:"Spread percentage above which a price dislocation is flagged"`
+	SpreadPeriods   int     `long:"spreadperiods" description:"Number of consecutive intervals a spread must stay above the threshold to be reported as sustained"`
+
+	// Exchange deposit and withdrawal monitor
+	DisableTransfers bool `long:"disabletransfers" description:"Disables monitoring of DCR deposit and withdrawal status on exchanges"`
 
 	// PoW collector
-	DisablePow   bool     `long:"disablepow" description:"Disables collection of data for pows"`
-	DisabledPows []string `long:"disabledpow" description:"Disable data collection for this Pow"`
-	PowInterval  int64    `long:"powI" description:"Collection interval for Pow"`
+	DisablePow       bool             `long:"disablepow" description:"Disables collection of data for pows"`
+	DisabledPows     []string         `long:"disabledpow" description:"Disable data collection for this Pow"`
+	PowInterval      int64            `long:"powI" description:"Collection interval for Pow"`
+	PowIntervals     map[string]int64 `long:"powsourceinterval" description:"Collection interval in seconds of a PoW source, as source:seconds, e.g. luxor:600. Defaults to powI"`
+	PowTimeout       int64            `long:"powtimeout" description:"Time in seconds after which a PoW collection is cancelled"`
+	DisablePowBlocks bool             `long:"disablepowblocks" description:"Disables collection of the blocks found by the PoW pools"`
+
+	// Mining profitability
+	ProfitEfficiency float64 `long:"profitefficiency" description:"Power use in J/TH of the mining hardware profitability is computed for"`
+	ProfitPowerCost  float64 `long:"profitpowercost" description:"Electricity cost in USD per kWh profitability is computed for"`
+
+	// Pool hashrate share monitor
+	DisablePoolShare   bool    `long:"disablepoolshare" description:"Disables computation of the pools' share of the network hashrate"`
+	PoolShareInterval  int64   `long:"poolshareinterval" description:"Length in seconds of the periods pool shares are computed over"`
+	PoolShareThreshold float64 `long:"poolsharethreshold" description:"Percentage of the network hashrate above which a single pool raises an alert"`
+	TopPools           int     `long:"toppools" description:"Number of largest pools whose combined share is checked against toppoolsthreshold"`
+	TopPoolsThreshold  float64 `long:"toppoolsthreshold" description:"Percentage of the network hashrate above which the top pools raise an alert"`
+
+	// dcrd chain collector
+	DcrdRPCServer string `long:"dcrdrpcserver" description:"host:port of the dcrd RPC server on-chain data is collected from. Chain collection is disabled when empty"`
+	DcrdRPCUser   string `long:"dcrdrpcuser" description:"dcrd RPC username"`
+	DcrdRPCPass   string `long:"dcrdrpcpass" description:"dcrd RPC password"`
+	DcrdRPCCert   string `long:"dcrdrpccert" description:"Path of the dcrd RPC certificate"`
+	DcrdNoTLS     bool   `long:"dcrdnotls" description:"Connect to the dcrd RPC server without TLS"`
+	ChainInterval int64  `long:"chaininterval" description:"Interval in seconds between checks for new blocks"`
+	ChainHistory  int64  `long:"chainhistory" description:"Number of blocks before the best block the first chain sync starts from"`
+
+	// Difficulty and ticket price projection
+	DisableProjection bool `long:"disableprojection" description:"Disables the projection of the next PoW difficulty and ticket price from the collected blocks"`
+
+	// Block propagation monitor
+	PropagationNodes  string `long:"propagationnodes" description:"Path of a JSON file listing the dcrd nodes block propagation is measured across. Propagation is not measured when empty"`
+	PropagationVotes  bool   `long:"propagationvotes" description:"Also measure the propagation of votes"`
+	PropagationSettle int64  `long:"propagationsettle" description:"Seconds after the first node saw a block or vote after which the nodes that did not see it are left out"`
 
 	// VSP
 	DisableVSP  bool  `long:"disablevsp" description:"Disables periodic voting service pool status collection"`
 	VSPInterval int64 `long:"vspinterval" description:"Collection interval for pool status collection"`
+
+	// Fiat rates
+	DisableFiat         bool     `long:"disablefiat" description:"Disables collection of fiat exchange rates"`
+	DisabledFiatSources []string `long:"disabledfiatsource" description:"Disable fiat rate collection from this source"`
+	FiatCurrencies      []string `long:"fiatcurrency" description:"Fiat currency to collect BTC and DCR rates in. Defaults to USD and EUR"`
+	FiatInterval        int64    `long:"fiatinterval" description:"Collection interval for fiat rates in seconds"`
 }
 
 // CommandLineOptions holds the top-level options/flags that are displayed on the command-line menu
 type CommandLineOptions struct {
 	HttpMode bool `long:"http" description:"Launch http server"`
+
+	Import    ImportOptions    `command:"import" description:"Import historic exchange candles from a CSV or JSON file"`
+	Export    ExportOptions    `command:"export" description:"Export collected exchange, vsp or PoW data as CSV, JSON Lines or Parquet"`
+	Exchanges ExchangesOptions `command:"exchanges" description:"List the exchange registry or change the status, listing dates and notes of an exchange or market"`
+	Backfill  BackfillOptions  `command:"backfill" description:"List the backfill jobs, create a job collecting the historic candles of a market or pause, resume or cancel a job"`
+}
+
+// ImportOptions holds the options of the import command
+type ImportOptions struct {
+	File        string            `long:"file" required:"true" description:"Path of the CSV or JSON file to import"`
+	Format      string            `long:"format" description:"File format {csv, json}. Detected from the file extension when not set"`
+	Exchange    string            `long:"exchange" required:"true" description:"Name of the exchange the candles belong to"`
+	ExchangeURL string            `long:"exchangeurl" description:"Website of the exchange, used when the exchange is not registered yet"`
+	Interval    int               `long:"interval" required:"true" description:"Candle interval in minutes"`
+	Pair        string            `long:"pair" default:"BTC/DCR" description:"Currency pair of the candles as QUOTE/BASE"`
+	VolumeSide  string            `long:"volumeside" default:"base" choice:"base" choice:"quote" description:"Asset of the pair the volume column is denominated in"`
+	Columns     map[string]string `long:"column" description:"Column of a candle field as field:column, e.g. time:Date or close:4. Fields are time, open, high, low, close and volume"`
+	TimeFormat  string            `long:"timeformat" default:"unix" description:"Format of the time column: unix, unixms or a Go time layout such as 2006-01-02T15:04:05"`
+	NoHeader    bool              `long:"noheader" description:"The CSV file has no header row, columns must be given as indices"`
+	Delimiter   string            `long:"delimiter" default:"," description:"CSV field delimiter"`
+	DryRun      bool              `long:"dryrun" description:"Validate the file without storing the candles"`
+}
+
+// ExportOptions holds the options of the export command
+type ExportOptions struct {
+	Data     string   `long:"data" required:"true" choice:"exchange" choice:"vsp" choice:"pow" description:"Data set to export"`
+	Format   string   `long:"format" default:"csv" choice:"csv" choice:"jsonl" choice:"parquet" description:"Output format"`
+	Out      string   `long:"out" required:"true" description:"Output file"`
+	From     string   `long:"from" description:"Export data from this time, as 2006-01-02 or RFC3339"`
+	To       string   `long:"to" description:"Export data before this time, as 2006-01-02 or RFC3339"`
+	Sources  []string `long:"source" description:"Only export data of this exchange, vsp or PoW source"`
+	Interval int      `long:"interval" description:"Only export exchange ticks of this interval in minutes"`
+}
+
+// ExchangesOptions holds the options of the exchanges command
+type ExchangesOptions struct {
+	Exchange string  `long:"exchange" description:"Exchange to show or change. All exchanges are listed when not set"`
+	Status   string  `long:"status" choice:"active" choice:"suspended" choice:"delisted" description:"Set the status of the exchange. Suspended and delisted exchanges are not collected from"`
+	Pair     string  `long:"pair" description:"Market of the exchange to change, as QUOTE/BASE"`
+	Listed   string  `long:"listed" description:"Set the date the market was listed, as 2006-01-02 or RFC3339, or none to clear it"`
+	Delisted string  `long:"delisted" description:"Set the date the market was delisted, as 2006-01-02 or RFC3339, or none to clear it. Delisted markets are not collected from"`
+	Collect  string  `long:"collect" choice:"on" choice:"off" description:"Turn collection of the market on or off"`
+	Notes    *string `long:"notes" description:"Set the notes of the exchange, or of the market when a pair is given"`
+}
+
+// BackfillOptions holds the options of the backfill command
+type BackfillOptions struct {
+	Exchange string `long:"exchange" description:"Exchange of the market to backfill"`
+	Pair     string `long:"pair" default:"BTC/DCR" description:"Market to backfill, as QUOTE/BASE"`
+	Interval int    `long:"interval" description:"Candle interval in minutes, one of the intervals collected from the exchange"`
+	From     string `long:"from" description:"Backfill candles from this time, as 2006-01-02 or RFC3339"`
+	To       string `long:"to" description:"Backfill candles before this time, as 2006-01-02 or RFC3339. Defaults to now"`
+	Pause    int    `long:"pause" description:"Pause the job with this id"`
+	Resume   int    `long:"resume" description:"Resume the paused or failed job with this id"`
+	Cancel   int    `long:"cancel" description:"Cancel the job with this id"`
 }
 
 func defaultFileOptions() configFileOptions {
 	return configFileOptions{
-		LogFile:     defaultLogFilename,
-		ConfigFile:  defaultConfigFilename,
-		DebugLevel:  defaultLogLevel,
-		VSPInterval: 300,
-		PowInterval: 300,
+		LogFile:             defaultLogFilename,
+		ConfigFile:          defaultConfigFilename,
+		DebugLevel:          defaultLogLevel,
+		VSPInterval:         300,
+		PowInterval:         300,
+		PowTimeout:          120,
+		ProfitEfficiency:    47,
+		ProfitPowerCost:     0.05,
+		PoolShareInterval:   3600,
+		ChainInterval:       60,
+		ChainHistory:        8064,
+		PropagationSettle:   120,
+		PoolShareThreshold:  40,
+		TopPools:            3,
+		TopPoolsThreshold:   75,
+		FiatInterval:        300,
+		ExchangeConcurrency: 4,
+		ExchangeTimeout:     600,
+		DiscoveryInterval:   3600,
+		StaleAfter:          7200,
+		DownAfter:           1800,
+		SpreadThreshold:     2,
+		SpreadPeriods:       3,
 	}
 }
 
@@ -155,6 +290,7 @@ func parseAndSetDebugLevels(debugLevel string) error {
 func loadConfig() (*config, []string, error) {
 	cfg := defaultConfig()
 	parser := flags.NewParser(&cfg, flags.IgnoreUnknown)
+	parser.SubcommandsOptional = true
 	err := flags.NewIniParser(parser).ParseFile(cfg.configFileOptions.ConfigFile)
 	if err != nil {
 		if _, ok := err.(*os.PathError); ok {
@@ -173,6 +309,10 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	if parser.Active != nil {
+		cfg.command = parser.Active.Name
+	}
+
 	initLogRotator(cfg.configFileOptions.LogFile)
 
 	// Special show command to list supported subsystems and exit.
@@ -199,5 +339,9 @@ func loadConfig() (*config, []string, error) {
 		cfg.configFileOptions.VSPInterval = 300
 	}
 
+	if len(cfg.configFileOptions.FiatCurrencies) == 0 {
+		cfg.configFileOptions.FiatCurrencies = []string{"USD", "EUR"}
+	}
+
 	return &cfg, unknownArg, nil
 }
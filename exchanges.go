@@ -0,0 +1,112 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+	"github.com/raedahgroup/dcrextdata/postgres"
+)
+
+// manageExchanges applies the changes given in opts to the exchange registry
+// and prints the registry entries of the selected exchange, or of all
+// exchanges when none is selected.
+func manageExchanges(ctx context.Context, db *postgres.PgDb, restExchanges string, opts ExchangesOptions) error {
+	if err := syncExchangeRegistry(ctx, db, restExchanges); err != nil {
+		return err
+	}
+
+	name := strings.ToLower(opts.Exchange)
+	if name == "" && (opts.Status != "" || opts.Pair != "" || opts.Notes != nil) {
+		return fmt.Errorf("Select the exchange to change with --exchange")
+	}
+
+	if opts.Pair != "" {
+		var update ticks.MarketUpdate
+		var err error
+		if opts.Listed != "" {
+			if update.ListedAt, err = ticks.ParseMarketDate(opts.Listed); err != nil {
+				return err
+			}
+		}
+		if opts.Delisted != "" {
+			if update.DelistedAt, err = ticks.ParseMarketDate(opts.Delisted); err != nil {
+				return err
+			}
+		}
+		if opts.Collect != "" {
+			collect := opts.Collect == "on"
+			update.Collect = &collect
+		}
+		update.Notes = opts.Notes
+		if err = db.UpdateExchangeMarket(ctx, name, opts.Pair, update); err != nil {
+			return err
+		}
+	} else if opts.Listed != "" || opts.Delisted != "" || opts.Collect != "" {
+		return fmt.Errorf("Select the market to change with --pair")
+	}
+
+	var update ticks.ExchangeUpdate
+	if opts.Status != "" {
+		status := ticks.ExchangeStatus(opts.Status)
+		update.Status = &status
+	}
+	if opts.Pair == "" {
+		update.Notes = opts.Notes
+	}
+	if err := db.UpdateExchange(ctx, name, update); err != nil {
+		return err
+	}
+
+	registry, err := db.ExchangeRegistry(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "EXCHANGE\tSTATUS\tINTERVALS\tMARKET\tSYMBOL\tLISTED\tDELISTED\tDISCOVERED\tCOLLECT\tNOTES")
+	found := false
+	for _, info := range registry {
+		if name != "" && info.Name != name {
+			continue
+		}
+		found = true
+		fmt.Fprintf(w, "%s\t%s\t%s\t\t\t\t\t\t\t%s\n", info.Name, info.Status, formatIntervals(info.Intervals), info.Notes)
+		for _, market := range info.Markets {
+			collect := "off"
+			if market.Collect {
+				collect = "on"
+			}
+			fmt.Fprintf(w, "\t\t\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", market.Pair, market.Symbol,
+				formatDate(market.ListedAt), formatDate(market.DelistedAt), formatDate(market.DiscoveredAt),
+				collect, market.Notes)
+		}
+	}
+	if !found && name != "" {
+		return fmt.Errorf("Unknown exchange %s", name)
+	}
+	return w.Flush()
+}
+
+func formatIntervals(intervals []int) string {
+	names := make([]string, len(intervals))
+	for i, interval := range intervals {
+		names[i] = fmt.Sprintf("%dm", interval)
+	}
+	return strings.Join(names, ",")
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format("2006-01-02")
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package arbitrage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// lookback limits how far back spreads are computed on the first run
+	lookback = 14 * 24 * time.Hour
+)
+
+// Monitor computes the price spread between every pair of enabled exchanges
+// from the stored exchange ticks and flags dislocations above the threshold
+// that last for at least the configured number of intervals.
+type Monitor struct {
+	disabled  map[string]struct{}
+	threshold float64
+	periods   int
+	store     Store
+}
+
+func NewMonitor(disabledExchanges []string, threshold float64, periods int, store Store) (*Monitor, error) {
+	if threshold <= 0 {
+		return nil, fmt.Errorf("Spread threshold must be greater than zero, got %v", threshold)
+	}
+	if periods < 1 {
+		return nil, fmt.Errorf("Sustained spread periods must be at least 1, got %d", periods)
+	}
+
+	disabledMap := make(map[string]struct{})
+	for _, e := range disabledExchanges {
+		disabledMap[e] = struct{}{}
+	}
+
+	return &Monitor{
+		disabled:  disabledMap,
+		threshold: threshold,
+		periods:   periods,
+		store:     store,
+	}, nil
+}
+
+func (m *Monitor) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err := m.update(ctx); err != nil {
+		log.Errorf("Unable to compute exchange spreads: %v", err)
+	}
+}
+
+func (m *Monitor) update(ctx context.Context) error {
+	last, err := m.store.LastSpreads(ctx)
+	if err != nil {
+		return err
+	}
+
+	previous := make(map[spreadKey]Spread, len(last))
+	for _, spread := range last {
+		previous[spread.key()] = spread
+	}
+
+	closes, err := m.store.TickCloses(ctx, closesSince(last, time.Now().Add(-lookback)))
+	if err != nil {
+		return err
+	}
+
+	spreads := m.computeSpreads(closes, previous)
+	if len(spreads) == 0 {
+		log.Debug("No new exchange spreads")
+		return nil
+	}
+	return m.store.StoreSpreads(ctx, spreads)
+}
+
+// closesSince returns the time from which the closes are needed to extend the
+// last spreads. Closes are loaded from the last spread of every interval and
+// from the open streaks, so that a pair of exchanges that stopped reporting
+// does not hold the query back, but not before the lookback limit.
+func closesSince(last []Spread, limit time.Time) time.Time {
+	newest := make(map[int]time.Time)
+	var openStreaks []time.Time
+	for _, spread := range last {
+		if spread.Time.After(newest[spread.Interval]) {
+			newest[spread.Interval] = spread.Time
+		}
+		if spread.Streak > 0 {
+			openStreaks = append(openStreaks, spread.Time)
+		}
+	}
+
+	var since time.Time
+	for _, t := range newest {
+		if since.IsZero() || t.Before(since) {
+			since = t
+		}
+	}
+	for _, t := range openStreaks {
+		if t.Before(since) {
+			since = t
+		}
+	}
+	if since.Before(limit) {
+		return limit
+	}
+	return since
+}
+
+type closeGroup struct {
+	interval     int
+	currencyPair string
+	time         time.Time
+}
+
+// computeSpreads pairs up the closing prices of ticks that share an interval,
+// currency pair and time. previous holds the last computed spread of every
+// exchange pair and is updated as new spreads are computed.
+func (m *Monitor) computeSpreads(closes []TickClose, previous map[spreadKey]Spread) []Spread {
+	groups := make(map[closeGroup][]TickClose)
+	order := make([]closeGroup, 0)
+	for _, tc := range closes {
+		if _, disabled := m.disabled[tc.Exchange]; disabled || tc.Close <= 0 {
+			continue
+		}
+		group := closeGroup{interval: tc.Interval, currencyPair: tc.CurrencyPair, time: tc.Time.UTC()}
+		if _, ok := groups[group]; !ok {
+			order = append(order, group)
+		}
+		groups[group] = append(groups[group], tc)
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].time.Before(order[j].time)
+	})
+
+	var spreads []Spread
+	for _, group := range order {
+		members := groups[group]
+		sort.Slice(members, func(i, j int) bool {
+			return members[i].Exchange < members[j].Exchange
+		})
+
+		for i := 0; i < len(members); i++ {
+			for j := i + 1; j < len(members); j++ {
+				a, b := members[i], members[j]
+				if a.Exchange == b.Exchange {
+					continue
+				}
+				spread := Spread{
+					Time:         group.time,
+					Interval:     group.interval,
+					CurrencyPair: group.currencyPair,
+					ExchangeA:    a.Exchange,
+					ExchangeB:    b.Exchange,
+					PriceA:       a.Close,
+					PriceB:       b.Close,
+					Spread:       (b.Close - a.Close) / a.Close * 100,
+				}
+
+				prev, hasPrev := previous[spread.key()]
+				if hasPrev && !spread.Time.After(prev.Time) {
+					continue
+				}
+
+				if math.Abs(spread.Spread) >= m.threshold {
+					spread.Streak = 1
+					expected := spread.Time.Add(-time.Duration(spread.Interval) * time.Minute)
+					if hasPrev && prev.Streak > 0 && prev.Time.Equal(expected) {
+						spread.Streak = prev.Streak + 1
+					}
+				}
+				spread.Sustained = spread.Streak >= m.periods
+
+				if spread.Streak == m.periods {
+					log.Warnf("Sustained %.2f%% spread on %s(%dm) between %s and %s for %d intervals as at %s",
+						spread.Spread, spread.CurrencyPair, spread.Interval, spread.ExchangeA, spread.ExchangeB,
+						spread.Streak, spread.Time.Format("2006-01-02 15:04"))
+				}
+
+				previous[spread.key()] = spread
+				spreads = append(spreads, spread)
+			}
+		}
+	}
+	return spreads
+}
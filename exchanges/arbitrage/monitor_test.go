@@ -0,0 +1,255 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package arbitrage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var testStart = time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// at returns the time of the nth 5 minute interval from testStart
+func at(n int) time.Time {
+	return testStart.Add(time.Duration(n) * 5 * time.Minute)
+}
+
+// closes returns the 5 minute BTC/DCR closes of exchange at consecutive
+// intervals from the nth
+func closes(exchange string, n int, prices ...float64) []TickClose {
+	c := make([]TickClose, len(prices))
+	for i, price := range prices {
+		c[i] = TickClose{Exchange: exchange, Interval: 5, CurrencyPair: "BTC/DCR", Time: at(n + i), Close: price}
+	}
+	return c
+}
+
+func concat(lists ...[]TickClose) []TickClose {
+	var all []TickClose
+	for _, l := range lists {
+		all = append(all, l...)
+	}
+	return all
+}
+
+func TestComputeSpreads(t *testing.T) {
+	type result struct {
+		time      time.Time
+		spread    float64
+		streak    int
+		sustained bool
+	}
+	tests := []struct {
+		name     string
+		closes   []TickClose
+		previous []Spread
+		expected []result
+	}{
+		{
+			name:   "below threshold",
+			closes: concat(closes("a", 0, 100, 100), closes("b", 0, 101, 99)),
+			expected: []result{
+				{at(0), 1, 0, false},
+				{at(1), -1, 0, false},
+			},
+		},
+		{
+			name:   "sustained after three intervals",
+			closes: concat(closes("a", 0, 100, 100, 100, 100, 100), closes("b", 0, 103, 97, 103, 101, 103)),
+			expected: []result{
+				{at(0), 3, 1, false},
+				{at(1), -3, 2, false},
+				{at(2), 3, 3, true},
+				{at(3), 1, 0, false},
+				{at(4), 3, 1, false},
+			},
+		},
+		{
+			name:   "missing interval breaks the streak",
+			closes: concat(closes("a", 0, 100, 100), closes("a", 3, 100), closes("b", 0, 103, 103), closes("b", 3, 103)),
+			expected: []result{
+				{at(0), 3, 1, false},
+				{at(1), 3, 2, false},
+				{at(3), 3, 1, false},
+			},
+		},
+		{
+			name:   "streak continues from the previous spread",
+			closes: concat(closes("a", 2, 100), closes("b", 2, 103)),
+			previous: []Spread{{Time: at(1), Interval: 5, CurrencyPair: "BTC/DCR", ExchangeA: "a", ExchangeB: "b",
+				Spread: 3, Streak: 2}},
+			expected: []result{
+				{at(2), 3, 3, true},
+			},
+		},
+		{
+			name:   "closes up to the previous spread are skipped",
+			closes: concat(closes("a", 0, 100, 100), closes("b", 0, 103, 103)),
+			previous: []Spread{{Time: at(0), Interval: 5, CurrencyPair: "BTC/DCR", ExchangeA: "a", ExchangeB: "b",
+				Spread: 3, Streak: 1}},
+			expected: []result{
+				{at(1), 3, 2, false},
+			},
+		},
+		{
+			name:   "disabled exchanges, zero closes and single exchanges are skipped",
+			closes: concat(closes("a", 0, 100, 0, 100), closes("b", 0, 103, 103), closes("disabled", 0, 110)),
+			expected: []result{
+				{at(0), 3, 1, false},
+			},
+		},
+	}
+
+	m, err := NewMonitor([]string{"disabled"}, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		previous := make(map[spreadKey]Spread)
+		for _, p := range test.previous {
+			previous[p.key()] = p
+		}
+		spreads := m.computeSpreads(test.closes, previous)
+		if len(spreads) != len(test.expected) {
+			t.Errorf("%s: got spreads %+v", test.name, spreads)
+			continue
+		}
+		for i, s := range spreads {
+			e := test.expected[i]
+			if s.ExchangeA != "a" || s.ExchangeB != "b" || !s.Time.Equal(e.time) || s.Spread != e.spread ||
+				s.Streak != e.streak || s.Sustained != e.sustained {
+				t.Errorf("%s: spread %d is %+v, expected %+v", test.name, i, s, e)
+			}
+		}
+	}
+}
+
+func TestComputeSpreadsPairsEveryExchange(t *testing.T) {
+	m, err := NewMonitor(nil, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spreads := m.computeSpreads(concat(closes("c", 0, 100), closes("a", 0, 100), closes("b", 0, 100)),
+		make(map[spreadKey]Spread))
+	pairs := []string{"a-b", "a-c", "b-c"}
+	if len(spreads) != len(pairs) {
+		t.Fatalf("got spreads %+v", spreads)
+	}
+	for i, s := range spreads {
+		if s.ExchangeA+"-"+s.ExchangeB != pairs[i] {
+			t.Errorf("spread %d between %s and %s, expected %s", i, s.ExchangeA, s.ExchangeB, pairs[i])
+		}
+	}
+}
+
+func TestClosesSince(t *testing.T) {
+	limit := at(-1000)
+	tests := []struct {
+		name     string
+		last     []Spread
+		expected time.Time
+	}{
+		{"no spreads", nil, limit},
+		{
+			// The stale pair does not hold the query back
+			name: "stale pair",
+			last: []Spread{
+				{Time: at(-500), Interval: 5, ExchangeA: "a", ExchangeB: "stale"},
+				{Time: at(10), Interval: 5, ExchangeA: "a", ExchangeB: "b"},
+			},
+			expected: at(10),
+		},
+		{
+			name: "open streak",
+			last: []Spread{
+				{Time: at(-20), Interval: 5, ExchangeA: "a", ExchangeB: "stale", Streak: 2},
+				{Time: at(10), Interval: 5, ExchangeA: "a", ExchangeB: "b"},
+			},
+			expected: at(-20),
+		},
+		{
+			name: "longer interval",
+			last: []Spread{
+				{Time: at(-12), Interval: 60, ExchangeA: "a", ExchangeB: "b"},
+				{Time: at(10), Interval: 5, ExchangeA: "a", ExchangeB: "b"},
+			},
+			expected: at(-12),
+		},
+		{
+			name:     "limited",
+			last:     []Spread{{Time: at(-2000), Interval: 5, ExchangeA: "a", ExchangeB: "b", Streak: 1}},
+			expected: limit,
+		},
+	}
+	for _, test := range tests {
+		if since := closesSince(test.last, limit); !since.Equal(test.expected) {
+			t.Errorf("%s: since %v, expected %v", test.name, since, test.expected)
+		}
+	}
+}
+
+type memoryStore struct {
+	closes []TickClose
+	last   []Spread
+	since  time.Time
+	stored []Spread
+}
+
+func (s *memoryStore) TickCloses(ctx context.Context, since time.Time) ([]TickClose, error) {
+	s.since = since
+	var closes []TickClose
+	for _, c := range s.closes {
+		if !c.Time.Before(since) {
+			closes = append(closes, c)
+		}
+	}
+	return closes, nil
+}
+
+func (s *memoryStore) LastSpreads(ctx context.Context) ([]Spread, error) {
+	return s.last, nil
+}
+
+func (s *memoryStore) StoreSpreads(ctx context.Context, spreads []Spread) error {
+	s.stored = append(s.stored, spreads...)
+	return nil
+}
+
+func TestMonitorUpdate(t *testing.T) {
+	now := time.Now().UTC().Truncate(5 * time.Minute)
+	store := &memoryStore{
+		closes: []TickClose{
+			{Exchange: "a", Interval: 5, CurrencyPair: "BTC/DCR", Time: now.Add(-5 * time.Minute), Close: 100},
+			{Exchange: "b", Interval: 5, CurrencyPair: "BTC/DCR", Time: now.Add(-5 * time.Minute), Close: 103},
+			{Exchange: "a", Interval: 5, CurrencyPair: "BTC/DCR", Time: now, Close: 100},
+			{Exchange: "b", Interval: 5, CurrencyPair: "BTC/DCR", Time: now, Close: 103},
+		},
+		last: []Spread{{Time: now.Add(-5 * time.Minute), Interval: 5, CurrencyPair: "BTC/DCR", ExchangeA: "a",
+			ExchangeB: "b", Spread: 3, Streak: 2}},
+	}
+	m, err := NewMonitor(nil, 2, 3, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = m.update(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !store.since.Equal(now.Add(-5 * time.Minute)) {
+		t.Errorf("closes loaded from %v", store.since)
+	}
+	if len(store.stored) != 1 || !store.stored[0].Sustained || !store.stored[0].Time.Equal(now) {
+		t.Errorf("stored spreads %+v", store.stored)
+	}
+}
+
+func TestNewMonitorValidation(t *testing.T) {
+	if _, err := NewMonitor(nil, 0, 3, nil); err == nil {
+		t.Error("zero threshold accepted")
+	}
+	if _, err := NewMonitor(nil, 2, 0, nil); err == nil {
+		t.Error("zero periods accepted")
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package arbitrage
+
+import (
+	"context"
+	"time"
+)
+
+type Store interface {
+	TickCloses(ctx context.Context, since time.Time) ([]TickClose, error)
+	LastSpreads(ctx context.Context) ([]Spread, error)
+	StoreSpreads(ctx context.Context, spreads []Spread) error
+}
+
+// TickClose is the closing price of a stored exchange tick
+type TickClose struct {
+	Exchange     string
+	Interval     int
+	CurrencyPair string
+	Time         time.Time
+	Close        float64
+}
+
+// Spread is the price difference between two exchanges for the same currency
+// pair, interval and tick time. Spread is the percentage by which PriceB
+// differs from PriceA. Streak counts the consecutive intervals, ending at
+// Time, for which the absolute spread stayed above the monitor's threshold and
+// Sustained is set once the streak reaches the configured number of periods.
+type Spread struct {
+	Time         time.Time
+	Interval     int
+	CurrencyPair string
+	ExchangeA    string
+	ExchangeB    string
+	PriceA       float64
+	PriceB       float64
+	Spread       float64
+	Streak       int
+	Sustained    bool
+}
+
+// SpreadDto represents an exchange spread, formatted for presentation
+type SpreadDto struct {
+	Time         time.Time `json:"time"`
+	Interval     int       `json:"interval"`
+	CurrencyPair string    `json:"currency_pair"`
+	ExchangeA    string    `json:"exchange_a"`
+	ExchangeB    string    `json:"exchange_b"`
+	PriceA       float64   `json:"price_a"`
+	PriceB       float64   `json:"price_b"`
+	Spread       float64   `json:"spread"`
+	Streak       int       `json:"streak"`
+	Sustained    bool      `json:"sustained"`
+}
+
+type spreadKey struct {
+	interval     int
+	currencyPair string
+	exchangeA    string
+	exchangeB    string
+}
+
+func (s Spread) key() spreadKey {
+	return spreadKey{
+		interval:     s.Interval,
+		currencyPair: s.CurrencyPair,
+		exchangeA:    s.ExchangeA,
+		exchangeB:    s.ExchangeB,
+	}
+}
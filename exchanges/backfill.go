@@ -0,0 +1,166 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package exchanges
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+)
+
+// errBackfillStopped stops a backfill that was paused or cancelled while it
+// ran
+var errBackfillStopped = errors.New("backfill stopped")
+
+// liveCollection tracks whether a tick hub is collecting, for backfills to
+// give way to it
+type liveCollection struct {
+	mtx     sync.Mutex
+	running int
+	idle    chan struct{}
+}
+
+var live = new(liveCollection)
+
+func (l *liveCollection) begin() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.running == 0 {
+		l.idle = make(chan struct{})
+	}
+	l.running++
+}
+
+func (l *liveCollection) end() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.running--
+	if l.running == 0 {
+		close(l.idle)
+	}
+}
+
+// wait blocks while live collection runs
+func (l *liveCollection) wait(ctx context.Context) error {
+	l.mtx.Lock()
+	running, idle := l.running > 0, l.idle
+	l.mtx.Unlock()
+	if !running {
+		return nil
+	}
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// BackfillRunner runs the stored backfill jobs one at a time, in the order
+// they were created. A job pauses between pages while live tick collection
+// runs.
+type BackfillRunner struct {
+	poll  time.Duration
+	store ticks.BackfillStore
+}
+
+// NewBackfillRunner creates a runner that looks for new jobs every poll
+func NewBackfillRunner(poll time.Duration, store ticks.BackfillStore) (*BackfillRunner, error) {
+	if poll <= 0 {
+		return nil, fmt.Errorf("Backfill poll interval must be positive, got %v", poll)
+	}
+	return &BackfillRunner{
+		poll:  poll,
+		store: store,
+	}, nil
+}
+
+// Run runs the jobs until ctx is cancelled
+func (r *BackfillRunner) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(r.poll)
+	defer ticker.Stop()
+	for {
+		job, err := r.store.NextBackfillJob(ctx)
+		if err == nil && job != nil {
+			if err = r.runJob(ctx, *job); err == nil {
+				continue
+			}
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Error(err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runJob runs job until it completes, fails or is stopped, returning the
+// errors of the store that keep it from running
+func (r *BackfillRunner) runJob(ctx context.Context, job ticks.BackfillJob) error {
+	if err := live.wait(ctx); err != nil {
+		return err
+	}
+	started, err := r.store.SetBackfillStatus(ctx, job.ID, ticks.BackfillRunning, "",
+		ticks.BackfillPending, ticks.BackfillRunning)
+	if err != nil {
+		return fmt.Errorf("Unable to start backfill job %d: %v", job.ID, err)
+	}
+	if !started {
+		return nil
+	}
+	log.Infof("Backfilling %s %s %dm candles from %s to %s", job.Exchange, job.CurrencyPair, job.Interval,
+		job.Checkpoint.UTC().Format(time.RFC3339), job.End.UTC().Format(time.RFC3339))
+
+	resumedAt, resumedFrom := time.Now(), job.Checkpoint
+	job.ResumedAt, job.ResumedFrom = &resumedAt, &resumedFrom
+	last, err := ticks.Backfill(ctx, r.store, job, func(checkpoint time.Time) error {
+		status, err := r.store.UpdateBackfillCheckpoint(ctx, job.ID, checkpoint)
+		if err != nil {
+			return err
+		}
+		if status != ticks.BackfillRunning {
+			return errBackfillStopped
+		}
+		job.Checkpoint, job.UpdatedAt = checkpoint, time.Now()
+		if eta, ok := job.ETA(); ok {
+			log.Infof("Backfill job %d at %s, %.1f%% done, about %v left", job.ID,
+				checkpoint.UTC().Format(time.RFC3339), job.Progress(), eta.Round(time.Second))
+		}
+		return live.wait(ctx)
+	})
+
+	switch {
+	case err == errBackfillStopped:
+		log.Infof("Backfill job %d was stopped", job.ID)
+		return nil
+	case ctx.Err() != nil:
+		// Left running to continue from the checkpoint on the next start
+		return ctx.Err()
+	case err == nil && last.Add(time.Duration(job.Interval)*time.Minute).Before(job.End):
+		err = fmt.Errorf("no candles served after %s", last.UTC().Format(time.RFC3339))
+	}
+
+	status := ticks.BackfillDone
+	errMsg := ""
+	if err != nil {
+		status, errMsg = ticks.BackfillFailed, err.Error()
+		log.Errorf("Backfill job %d failed: %v", job.ID, err)
+	} else {
+		log.Infof("Backfill job %d is done", job.ID)
+	}
+	if _, err = r.store.SetBackfillStatus(ctx, job.ID, status, errMsg, ticks.BackfillRunning); err != nil {
+		return fmt.Errorf("Unable to complete backfill job %d: %v", job.ID, err)
+	}
+	return nil
+}
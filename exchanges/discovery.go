@@ -0,0 +1,102 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package exchanges
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+)
+
+// MarketDiscoverer periodically looks for DCR markets on the exchanges that
+// are not collected yet and records them in the exchange registry
+type MarketDiscoverer struct {
+	exchanges   []string
+	client      *http.Client
+	interval    time.Duration
+	autoCollect bool
+	store       ticks.Registry
+}
+
+// NewMarketDiscoverer creates a discoverer for the exchanges that list their
+// markets and are not disabled. Discovered markets are collected right away
+// when autoCollect is set.
+func NewMarketDiscoverer(disabledexchanges []string, interval time.Duration, autoCollect bool, store ticks.Registry) (*MarketDiscoverer, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("Market discovery interval must be positive, got %v", interval)
+	}
+
+	disabledMap := make(map[string]struct{})
+	for _, e := range disabledexchanges {
+		disabledMap[e] = struct{}{}
+	}
+	var enabled []string
+	for _, exchange := range ticks.DiscoveryExchanges() {
+		if _, ok := disabledMap[exchange]; !ok {
+			enabled = append(enabled, exchange)
+		}
+	}
+	if len(enabled) == 0 {
+		return nil, fmt.Errorf("No exchanges to discover markets on")
+	}
+
+	return &MarketDiscoverer{
+		exchanges:   enabled,
+		client:      &http.Client{Timeout: clientTimeout},
+		interval:    interval,
+		autoCollect: autoCollect,
+		store:       store,
+	}, nil
+}
+
+// Discover records the new DCR markets of every exchange
+func (d *MarketDiscoverer) Discover(ctx context.Context) {
+	for _, exchange := range d.exchanges {
+		if ctx.Err() != nil {
+			return
+		}
+		markets, err := ticks.DiscoverMarkets(ctx, d.client, exchange)
+		if err != nil {
+			log.Errorf("Unable to list the markets of %s: %v", exchange, err)
+			continue
+		}
+		added, err := d.store.RecordDiscoveredMarkets(ctx, exchange, markets, d.autoCollect)
+		if err != nil {
+			log.Errorf("Unable to record the markets of %s: %v", exchange, err)
+			continue
+		}
+		for _, market := range added {
+			if d.autoCollect {
+				log.Infof("Discovered %s market %s (%s), collecting it from the next cycle", exchange,
+					market.Pair(), market.Symbol)
+			} else {
+				log.Infof("Discovered %s market %s (%s), collect it with "+
+					"dcrextdata exchanges --exchange=%s --pair=%s --collect=on", exchange, market.Pair(),
+					market.Symbol, exchange, market.Pair())
+			}
+		}
+	}
+}
+
+// Run discovers markets every interval until ctx is cancelled
+func (d *MarketDiscoverer) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	d.Discover(ctx)
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.Discover(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package exchanges
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+)
+
+// HealthMonitor records the outcome of the tick requests of each market and
+// classifies the markets as healthy, stale or down after every collection
+type HealthMonitor struct {
+	staleAfter time.Duration
+	downAfter  time.Duration
+	store      ticks.HealthStore
+}
+
+// NewHealthMonitor creates a monitor that considers a market stale without a
+// new candle for staleAfter and down when its requests failed for downAfter
+func NewHealthMonitor(staleAfter, downAfter time.Duration, store ticks.HealthStore) (*HealthMonitor, error) {
+	if staleAfter <= 0 {
+		return nil, fmt.Errorf("Exchange staleness period must be positive, got %v", staleAfter)
+	}
+	if downAfter <= 0 {
+		return nil, fmt.Errorf("Exchange outage period must be positive, got %v", downAfter)
+	}
+	return &HealthMonitor{
+		staleAfter: staleAfter,
+		downAfter:  downAfter,
+		store:      store,
+	}, nil
+}
+
+// Record stores the outcome of a request cycle of the collector
+func (m *HealthMonitor) Record(ctx context.Context, collector ticks.Collector, err error) {
+	if err := m.store.RecordCollection(ctx, collector.Exchange(), collector.CurrencyPair(), time.Now(), err); err != nil {
+		log.Errorf("Unable to record the collection of %s %s: %v", collector.Exchange(), collector.CurrencyPair(), err)
+	}
+}
+
+// Check classifies the markets of the collectors and stores the changes of
+// their health
+func (m *HealthMonitor) Check(ctx context.Context, collectors []hubCollector) {
+	states, err := m.store.ExchangeHealth(ctx)
+	if err != nil {
+		log.Errorf("Unable to check the exchange health: %v", err)
+		return
+	}
+	stateMap := make(map[string]ticks.MarketHealth, len(states))
+	for _, state := range states {
+		stateMap[ticks.MarketCollectorName(state.Exchange, state.CurrencyPair)] = state
+	}
+
+	now := time.Now()
+	for _, collector := range collectors {
+		state, ok := stateMap[ticks.MarketCollectorName(collector.Exchange(), collector.CurrencyPair())]
+		if !ok {
+			continue
+		}
+		health, reason := state.Classify(now, m.staleAfter, m.downAfter)
+		if health == state.Health {
+			continue
+		}
+		err = m.store.SetMarketHealth(ctx, state.Exchange, state.CurrencyPair, health, now, reason)
+		if err != nil {
+			log.Errorf("Unable to store the health of %s %s: %v", state.Exchange, state.CurrencyPair, err)
+			continue
+		}
+		if health == ticks.HealthHealthy {
+			log.Infof("%s %s is healthy again after being %s since %s", collector.name, state.CurrencyPair,
+				state.Health, state.Since.UTC().Format(time.RFC3339))
+		} else {
+			log.Warnf("%s %s is %s: %s", collector.name, state.CurrencyPair, health, reason)
+		}
+	}
+}
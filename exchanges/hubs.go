@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"runtime/debug"
 	"sync"
 	"time"
 
@@ -19,8 +20,25 @@ const (
 )
 
 type TickHub struct {
-	collectors []ticks.Collector
-	client     *http.Client
+	collectors  []hubCollector
+	client      *http.Client
+	concurrency int
+	timeout     time.Duration
+	health      *HealthMonitor
+}
+
+// Store keeps the collected ticks and the exchange registry, which decides
+// which exchanges and markets are collected
+type Store interface {
+	ticks.Store
+	ticks.Registry
+}
+
+// hubCollector is a tick collector identified by the exchange it was
+// constructed for
+type hubCollector struct {
+	name string
+	ticks.Collector
 }
 
 var (
@@ -30,15 +48,60 @@ var (
 		ticks.Binance,
 		ticks.Bleutrade,
 		ticks.Poloniex,
+		ticks.Huobi,
+		ticks.Kucoin,
+		ticks.Okex,
+		ticks.Upbit,
 	}
 )
 
-func NewTickHub(ctx context.Context, disabledexchanges []string, store ticks.Store) (*TickHub, error) {
-	collectors := make([]ticks.Collector, 0, len(availableExchanges)-len(disabledexchanges))
+// LoadRESTExchanges registers the exchanges defined in the JSON file at path
+// so that NewTickHub collects their ticks along with the built in exchanges
+func LoadRESTExchanges(path string) error {
+	defs, err := ticks.LoadRESTExchanges(path)
+	if err != nil {
+		return err
+	}
+	for _, def := range defs {
+		names, err := ticks.RegisterRESTExchange(def)
+		if err != nil {
+			return err
+		}
+		availableExchanges = append(availableExchanges, names...)
+		log.Debugf("Loaded REST exchange %s with collectors %v", def.Name, names)
+	}
+	return nil
+}
+
+// NewTickHub creates collectors for all exchanges that are not disabled and
+// not suspended or delisted in the registry, skipping delisted markets and
+// markets whose collection is turned off. Discovered markets are collected
+// once their collection is turned on.
+// Collection runs on up to concurrency exchanges at once and each request
+// cycle of an exchange is cancelled after timeout. The outcome of every cycle
+// is passed to health, unless it is nil.
+func NewTickHub(ctx context.Context, disabledexchanges []string, concurrency int, timeout time.Duration, health *HealthMonitor, store Store) (*TickHub, error) {
+	if concurrency < 1 {
+		return nil, fmt.Errorf("Exchange collection concurrency must be at least 1, got %d", concurrency)
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("Exchange collection timeout must be positive, got %v", timeout)
+	}
+
+	collectors := make([]hubCollector, 0, len(availableExchanges))
 	disabledMap := make(map[string]struct{})
 	for _, e := range disabledexchanges {
 		disabledMap[e] = struct{}{}
 	}
+	registry, err := store.ExchangeRegistry(ctx)
+	if err != nil {
+		return nil, err
+	}
+	infos := make(map[string]ticks.ExchangeInfo, len(registry))
+	for _, info := range registry {
+		infos[info.Name] = info
+	}
+
 	enabledExchanges := make([]string, 0, cap(collectors))
 	for _, exchange := range availableExchanges {
 		if _, ok := disabledMap[exchange]; !ok {
@@ -47,11 +110,41 @@ func NewTickHub(ctx context.Context, disabledexchanges []string, store ticks.Sto
 				log.Error(err)
 				continue
 			}
-			collectors = append(collectors, collector)
+			if reason := inactiveReason(infos, collector); reason != "" {
+				log.Infof("Not collecting %s, %s", exchange, reason)
+				continue
+			}
+			collectors = append(collectors, hubCollector{name: exchange, Collector: collector})
 			enabledExchanges = append(enabledExchanges, exchange)
 		}
 	}
 
+	for _, info := range registry {
+		if _, disabled := disabledMap[info.Name]; disabled || info.Status != ticks.StatusActive {
+			continue
+		}
+		for _, market := range info.Markets {
+			if market.DiscoveredAt == nil || !market.Collect || market.Delisted(time.Now()) {
+				continue
+			}
+			name := ticks.MarketCollectorName(info.Name, market.Pair)
+			if _, disabled := disabledMap[name]; disabled {
+				continue
+			}
+			historicStart := *market.DiscoveredAt
+			if market.ListedAt != nil {
+				historicStart = *market.ListedAt
+			}
+			collector, err := ticks.NewMarketCollector(ctx, store, info.Name, market.Market, historicStart)
+			if err != nil {
+				log.Error(err)
+				continue
+			}
+			collectors = append(collectors, hubCollector{name: name, Collector: collector})
+			enabledExchanges = append(enabledExchanges, name)
+		}
+	}
+
 	if len(collectors) == 0 {
 		return nil, fmt.Errorf("No tick collectors")
 	}
@@ -59,103 +152,125 @@ func NewTickHub(ctx context.Context, disabledexchanges []string, store ticks.Sto
 	log.Infof("Enabled exchange tick collection for %v", enabledExchanges)
 
 	return &TickHub{
-		collectors: collectors,
-		client:     &http.Client{Timeout: clientTimeout},
+		collectors:  collectors,
+		client:      &http.Client{Timeout: clientTimeout},
+		concurrency: concurrency,
+		timeout:     timeout,
+		health:      health,
 	}, nil
 }
 
-func (hub *TickHub) CollectShort(ctx context.Context) {
-	wg := new(sync.WaitGroup)
-	for _, collector := range hub.collectors {
-		if ctx.Err() != nil {
-			log.Error(ctx.Err())
-			break
+// inactiveReason returns why the collector's market should not be polled, or
+// an empty string when it should
+func inactiveReason(infos map[string]ticks.ExchangeInfo, collector ticks.Collector) string {
+	info, ok := infos[collector.Exchange()]
+	if !ok {
+		return ""
+	}
+	if info.Status != ticks.StatusActive {
+		return fmt.Sprintf("the exchange is %s", info.Status)
+	}
+	for _, market := range info.Markets {
+		if market.Pair != collector.CurrencyPair() {
+			continue
+		}
+		if market.Delisted(time.Now()) {
+			return fmt.Sprintf("%s was delisted on %s", market.Pair, market.DelistedAt.Format("2006-01-02"))
+		}
+		if !market.Collect {
+			return fmt.Sprintf("collection of %s is turned off", market.Pair)
 		}
-		wg.Add(1)
-		func(ctx context.Context, wg *sync.WaitGroup, collector ticks.Collector) {
-			err := collector.GetShort(ctx)
-			if err != nil {
-				log.Error(err)
-			}
-			wg.Done()
-		}(ctx, wg, collector)
 	}
-	wg.Wait()
-	log.Info("Completed short collection")
+	return ""
 }
 
-func (hub *TickHub) CollectLong(ctx context.Context) {
+type collectFunc func(ticks.Collector, context.Context) error
+
+// collect runs fn for every collector on a pool of hub.concurrency workers
+// and waits for all of them to complete.
+func (hub *TickHub) collect(ctx context.Context, fns ...collectFunc) {
+	jobs := make(chan hubCollector)
 	wg := new(sync.WaitGroup)
-	for _, collector := range hub.collectors {
-		if ctx.Err() != nil {
-			log.Error(ctx.Err())
-			break
-		}
+	workers := hub.concurrency
+	if workers > len(hub.collectors) {
+		workers = len(hub.collectors)
+	}
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
-		func(ctx context.Context, wg *sync.WaitGroup, collector ticks.Collector) {
-			err := collector.GetLong(ctx)
-			if err != nil {
-				log.Error(err)
+		go func() {
+			defer wg.Done()
+			for collector := range jobs {
+				for _, fn := range fns {
+					if ctx.Err() != nil {
+						break
+					}
+					hub.run(ctx, collector, fn)
+				}
 			}
-			wg.Done()
-		}(ctx, wg, collector)
+		}()
 	}
-	wg.Wait()
-	log.Info("Completed long collection")
-}
 
-func (hub *TickHub) CollectHistoric(ctx context.Context) {
-	wg := new(sync.WaitGroup)
+queue:
 	for _, collector := range hub.collectors {
-		if ctx.Err() != nil {
+		select {
+		case jobs <- collector:
+		case <-ctx.Done():
 			log.Error(ctx.Err())
-			break
+			break queue
 		}
-		wg.Add(1)
-		func(ctx context.Context, wg *sync.WaitGroup, collector ticks.Collector) {
-			err := collector.GetHistoric(ctx)
-			if err != nil {
-				log.Error(err)
-			}
-			wg.Done()
-		}(ctx, wg, collector)
 	}
+	close(jobs)
 	wg.Wait()
-	log.Info("Completed historic collection")
 }
 
-func (hub *TickHub) CollectAll(ctx context.Context) {
-	for _, collector := range hub.collectors {
-		if ctx.Err() != nil {
-			log.Error(ctx.Err())
-			break
+// run calls fn with a deadline of hub.timeout, recovering from any panic so
+// that a misbehaving exchange cannot take down the other collectors. The
+// outcome, which it returns, is recorded by the health monitor unless the hub
+// is shutting down.
+func (hub *TickHub) run(ctx context.Context, collector hubCollector, fn collectFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("Recovered from panic in %s collector: %v\n%s", collector.name, r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
 		}
-
-		err := collector.GetShort(ctx)
-		if err != nil {
-			log.Error(err)
+		if hub.health != nil && ctx.Err() == nil {
+			hub.health.Record(ctx, collector, err)
 		}
+	}()
 
-		err = collector.GetLong(ctx)
-		if err != nil {
-			log.Error(err)
-		}
+	fnCtx, cancel := context.WithTimeout(ctx, hub.timeout)
+	defer cancel()
 
-		err = collector.GetHistoric(ctx)
-		if err != nil {
-			log.Error(err)
+	if err = fn(collector.Collector, fnCtx); err != nil {
+		// The requests wrap the deadline in their own errors
+		if fnCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			err = fmt.Errorf("collection did not complete within %v", hub.timeout)
 		}
+		log.Errorf("%s: %v", collector.name, err)
 	}
+	return err
+}
 
-	/*hub.CollectShort(ctx)
-	if ctx.Err() != nil {
-		return
-	}
-	hub.CollectLong(ctx)
-	if ctx.Err() != nil {
-		return
-	}
-	hub.CollectHistoric(ctx)*/
+func (hub *TickHub) CollectShort(ctx context.Context) {
+	hub.collect(ctx, ticks.Collector.GetShort)
+	log.Info("Completed short collection")
+}
+
+func (hub *TickHub) CollectLong(ctx context.Context) {
+	hub.collect(ctx, ticks.Collector.GetLong)
+	log.Info("Completed long collection")
+}
+
+func (hub *TickHub) CollectHistoric(ctx context.Context) {
+	hub.collect(ctx, ticks.Collector.GetHistoric)
+	log.Info("Completed historic collection")
+}
+
+// CollectAll runs the short, long and historic collection of each exchange in
+// turn, with different exchanges collected concurrently.
+func (hub *TickHub) CollectAll(ctx context.Context) {
+	hub.collect(ctx, ticks.Collector.GetShort, ticks.Collector.GetLong, ticks.Collector.GetHistoric)
+	log.Info("Completed tick collection")
 }
 
 func (hub *TickHub) Run(ctx context.Context, wg *sync.WaitGroup) {
@@ -172,7 +287,12 @@ func (hub *TickHub) Run(ctx context.Context, wg *sync.WaitGroup) {
 		log.Error(ctx.Err())
 		return
 	}
+	live.begin()
 	hub.CollectAll(ctx)
+	live.end()
+	if hub.health != nil && ctx.Err() == nil {
+		hub.health.Check(ctx, hub.collectors)
+	}
 
 	/*for {
 		select {
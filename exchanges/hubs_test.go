@@ -0,0 +1,270 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package exchanges
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+)
+
+// testCollector is a collector whose collections call collect
+type testCollector struct {
+	exchange string
+	pair     string
+	collect  func(context.Context) error
+}
+
+func (c *testCollector) GetShort(ctx context.Context) error    { return c.collect(ctx) }
+func (c *testCollector) GetLong(ctx context.Context) error     { return c.collect(ctx) }
+func (c *testCollector) GetHistoric(ctx context.Context) error { return c.collect(ctx) }
+func (c *testCollector) Exchange() string                      { return c.exchange }
+func (c *testCollector) CurrencyPair() string                  { return c.pair }
+
+func testHub(concurrency int, timeout time.Duration, collect func(context.Context) error, names ...string) *TickHub {
+	hub := &TickHub{concurrency: concurrency, timeout: timeout}
+	for _, name := range names {
+		hub.collectors = append(hub.collectors, hubCollector{
+			name:      name,
+			Collector: &testCollector{exchange: name, pair: "BTC/DCR", collect: collect},
+		})
+	}
+	return hub
+}
+
+func TestCollectConcurrencyLimit(t *testing.T) {
+	var mtx sync.Mutex
+	running, maxRunning, calls := 0, 0, 0
+	collect := func(ctx context.Context) error {
+		mtx.Lock()
+		running++
+		calls++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mtx.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mtx.Lock()
+		running--
+		mtx.Unlock()
+		return nil
+	}
+
+	hub := testHub(2, time.Second, collect, "a", "b", "c", "d", "e")
+	hub.collect(context.Background(), ticks.Collector.GetShort, ticks.Collector.GetLong)
+	if maxRunning != 2 {
+		t.Errorf("%d collections ran at once, expected 2", maxRunning)
+	}
+	if calls != 10 {
+		t.Errorf("%d collections, expected 10", calls)
+	}
+}
+
+func TestCollectStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	hub := testHub(1, time.Second, func(context.Context) error {
+		calls++
+		cancel()
+		return nil
+	}, "a", "b", "c")
+	hub.collect(ctx, ticks.Collector.GetShort, ticks.Collector.GetLong)
+	if calls != 1 {
+		t.Errorf("%d collections after the cancellation, expected 1", calls)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	// The request fails with the deadline wrapped in a url.Error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	collect := func(ctx context.Context) error {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		if err != nil {
+			return err
+		}
+		res, err := server.Client().Do(req.WithContext(ctx))
+		if err == nil {
+			res.Body.Close()
+		}
+		return err
+	}
+
+	hub := testHub(1, 20*time.Millisecond, collect, "slow")
+	err := hub.run(context.Background(), hub.collectors[0], ticks.Collector.GetShort)
+	if err == nil || !strings.Contains(err.Error(), "did not complete within 20ms") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// Other errors are kept
+	hub = testHub(1, time.Second, func(context.Context) error { return fmt.Errorf("bad gateway") }, "failing")
+	if err = hub.run(context.Background(), hub.collectors[0], ticks.Collector.GetShort); err == nil ||
+		err.Error() != "bad gateway" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestRunRecoversPanics(t *testing.T) {
+	var mtx sync.Mutex
+	var collected []string
+	hub := &TickHub{concurrency: 2, timeout: time.Second}
+	for _, name := range []string{"a", "panicking", "b"} {
+		name := name
+		hub.collectors = append(hub.collectors, hubCollector{name: name, Collector: &testCollector{
+			exchange: name,
+			collect: func(context.Context) error {
+				if name == "panicking" {
+					var m map[string]int
+					m[name]++
+				}
+				mtx.Lock()
+				collected = append(collected, name)
+				mtx.Unlock()
+				return nil
+			},
+		}})
+	}
+
+	err := hub.run(context.Background(), hub.collectors[1], ticks.Collector.GetShort)
+	if err == nil || !strings.HasPrefix(err.Error(), "panic: ") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// The other collectors complete
+	hub.collect(context.Background(), ticks.Collector.GetShort)
+	if len(collected) != 2 {
+		t.Errorf("collected %v", collected)
+	}
+}
+
+// registryStore is a store holding a fixed exchange registry
+type registryStore struct {
+	registry []ticks.ExchangeInfo
+}
+
+func (s *registryStore) RegisterExchange(ctx context.Context, exchange ticks.ExchangeData, pair string) (time.Time, time.Time, time.Time, error) {
+	return time.Time{}, time.Time{}, time.Time{}, nil
+}
+
+func (s *registryStore) StoreExchangeTicks(ctx context.Context, exchange string, interval int, pair string, data []ticks.Tick) (time.Time, int, error) {
+	return time.Time{}, 0, nil
+}
+
+func (s *registryStore) SyncExchangeRegistry(ctx context.Context, exchanges []ticks.ExchangeInfo) error {
+	return nil
+}
+
+func (s *registryStore) ExchangeRegistry(ctx context.Context) ([]ticks.ExchangeInfo, error) {
+	return s.registry, nil
+}
+
+func (s *registryStore) UpdateExchange(ctx context.Context, name string, update ticks.ExchangeUpdate) error {
+	return nil
+}
+
+func (s *registryStore) UpdateExchangeMarket(ctx context.Context, name, pair string, update ticks.MarketUpdate) error {
+	return nil
+}
+
+func (s *registryStore) RecordDiscoveredMarkets(ctx context.Context, name string, markets []ticks.Market, collect bool) ([]ticks.Market, error) {
+	return nil, nil
+}
+
+func TestInactiveReason(t *testing.T) {
+	yesterday := time.Now().Add(-24 * time.Hour)
+	tomorrow := time.Now().Add(24 * time.Hour)
+	infos := map[string]ticks.ExchangeInfo{
+		"suspended": {Name: "suspended", Status: ticks.StatusSuspended},
+		"delisted":  {Name: "delisted", Status: ticks.StatusDelisted},
+		"active": {Name: "active", Status: ticks.StatusActive, Markets: []ticks.MarketInfo{
+			{Pair: "BTC/DCR", Collect: true},
+			{Pair: "USDT/DCR", DelistedAt: &yesterday, Collect: true},
+			{Pair: "USDC/DCR", DelistedAt: &tomorrow, Collect: true},
+			{Pair: "ETH/DCR", Collect: false},
+		}},
+	}
+	tests := []struct {
+		exchange, pair string
+		reason         string
+	}{
+		{"unregistered", "BTC/DCR", ""},
+		{"suspended", "BTC/DCR", "the exchange is suspended"},
+		{"delisted", "BTC/DCR", "the exchange is delisted"},
+		{"active", "BTC/DCR", ""},
+		{"active", "USDT/DCR", "USDT/DCR was delisted on " + yesterday.Format("2006-01-02")},
+		{"active", "USDC/DCR", ""},
+		{"active", "ETH/DCR", "collection of ETH/DCR is turned off"},
+		{"active", "EUR/DCR", ""},
+	}
+	for _, test := range tests {
+		collector := &testCollector{exchange: test.exchange, pair: test.pair}
+		if reason := inactiveReason(infos, collector); reason != test.reason {
+			t.Errorf("%s %s: got %q, expected %q", test.exchange, test.pair, reason, test.reason)
+		}
+	}
+}
+
+func TestNewTickHub(t *testing.T) {
+	yesterday := time.Now().Add(-24 * time.Hour)
+	discovered := time.Now().Add(-48 * time.Hour)
+	store := &registryStore{registry: []ticks.ExchangeInfo{
+		{Name: ticks.Binance, Status: ticks.StatusSuspended},
+		{Name: ticks.Bittrex, Status: ticks.StatusActive, Markets: []ticks.MarketInfo{
+			{Pair: "BTC/DCR", DelistedAt: &yesterday, Collect: true},
+			{Pair: "USD/BTC", Collect: true},
+		}},
+		{Name: ticks.Poloniex, Status: ticks.StatusActive, Markets: []ticks.MarketInfo{
+			{Pair: "BTC/DCR", Collect: false},
+		}},
+		{Name: ticks.Huobi, Status: ticks.StatusActive, Markets: []ticks.MarketInfo{
+			{Pair: "BTC/DCR", Collect: true},
+			{Market: ticks.Market{Base: "DCR", Quote: "USDT", Symbol: "dcrusdt"}, Pair: "USDT/DCR",
+				DiscoveredAt: &discovered, Collect: true},
+			{Market: ticks.Market{Base: "DCR", Quote: "ETH", Symbol: "dcreth"}, Pair: "ETH/DCR",
+				DiscoveredAt: &discovered, Collect: false},
+		}},
+		{Name: ticks.Kucoin, Status: ticks.StatusActive, Markets: []ticks.MarketInfo{
+			{Market: ticks.Market{Base: "DCR", Quote: "USDT", Symbol: "DCR-USDT"}, Pair: "USDT/DCR",
+				DiscoveredAt: &discovered, Collect: true},
+		}},
+	}}
+
+	hub, err := NewTickHub(context.Background(), []string{ticks.Kucoin}, 2, time.Minute, nil, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(hub.collectors))
+	for _, collector := range hub.collectors {
+		names = append(names, collector.name)
+	}
+	want := []string{ticks.Bittrexusd, ticks.Bleutrade, ticks.Huobi, ticks.Okex, ticks.Upbit,
+		ticks.MarketCollectorName(ticks.Huobi, "USDT/DCR")}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("collecting %v, expected %v", names, want)
+	}
+
+	invalid := []struct {
+		name        string
+		concurrency int
+		timeout     time.Duration
+	}{
+		{"no concurrency", 0, time.Minute},
+		{"no timeout", 1, 0},
+	}
+	for _, test := range invalid {
+		if _, err := NewTickHub(context.Background(), nil, test.concurrency, test.timeout, nil, store); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package importer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ericlagergren/decimal"
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+)
+
+const batchSize = 1000
+
+// Validate checks that the tick is a well formed candle of the given interval
+func Validate(tick ticks.Tick, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid interval %v", interval)
+	}
+	if tick.Time.IsZero() || tick.Time.Unix() <= 0 {
+		return fmt.Errorf("invalid time %v", tick.Time)
+	}
+	if tick.Time.After(time.Now()) {
+		return fmt.Errorf("time %v is in the future", tick.Time)
+	}
+	if tick.Time.Unix()%int64(interval.Seconds()) != 0 {
+		return fmt.Errorf("time %v is not aligned to the %v interval", tick.Time, interval)
+	}
+	for _, price := range []*decimal.Big{tick.Open, tick.High, tick.Low, tick.Close} {
+		if price == nil || price.Sign() <= 0 {
+			return fmt.Errorf("prices must be positive")
+		}
+	}
+	if tick.Volume == nil || tick.Volume.Sign() < 0 {
+		return fmt.Errorf("negative volume %v", tick.Volume)
+	}
+	if tick.High.Cmp(tick.Low) < 0 {
+		return fmt.Errorf("high %v is below low %v", tick.High, tick.Low)
+	}
+	if tick.Open.Cmp(tick.High) > 0 || tick.Close.Cmp(tick.High) > 0 {
+		return fmt.Errorf("open or close is above high %v", tick.High)
+	}
+	if tick.Open.Cmp(tick.Low) < 0 || tick.Close.Cmp(tick.Low) < 0 {
+		return fmt.Errorf("open or close is below low %v", tick.Low)
+	}
+	return nil
+}
+
+// Result summarises the ticks prepared for an import
+type Result struct {
+	Duplicates int
+	First      time.Time
+	Last       time.Time
+}
+
+// Prepare sorts the ticks read from a file by time and removes duplicate
+// times, keeping the first occurrence.
+func Prepare(data []ticks.Tick, result *Result) []ticks.Tick {
+	sorted := make([]ticks.Tick, len(data))
+	copy(sorted, data)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	unique := sorted[:0]
+	for _, tick := range sorted {
+		if len(unique) > 0 && unique[len(unique)-1].Time.Equal(tick.Time) {
+			result.Duplicates++
+			continue
+		}
+		unique = append(unique, tick)
+	}
+
+	if len(unique) > 0 {
+		result.First = unique[0].Time
+		result.Last = unique[len(unique)-1].Time
+	}
+	return unique
+}
+
+// Store registers the exchange if it is unknown and writes the ticks in
+// batches. Ticks that already exist in the store are skipped by the store and
+// left out of the number of ticks stored it returns.
+func Store(ctx context.Context, store ticks.Store, exchange ticks.ExchangeData, interval time.Duration,
+	pair string, data []ticks.Tick) (int, error) {
+	if _, _, _, err := store.RegisterExchange(ctx, exchange, pair); err != nil {
+		return 0, err
+	}
+
+	stored := 0
+	for start := 0; start < len(data); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return stored, err
+		}
+		end := start + batchSize
+		if end > len(data) {
+			end = len(data)
+		}
+		_, added, err := store.StoreExchangeTicks(ctx, exchange.Name, int(interval.Minutes()), pair, data[start:end])
+		if err != nil {
+			return stored, err
+		}
+		stored += added
+		log.Debugf("Imported %d of %d ticks, %d already stored", stored, end, end-stored)
+	}
+	return stored, nil
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package importer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ericlagergren/decimal"
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+	"github.com/raedahgroup/dcrextdata/helpers"
+)
+
+const hour = time.Hour
+
+func dec(t *testing.T, s string) *decimal.Big {
+	t.Helper()
+	d, err := helpers.ParseDecimal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestReadCSV(t *testing.T) {
+	file := `time,open,high,low,close,volume
+1559347200,0.0025,0.0026,0.0024,0.00251,10.5
+1559350800,0.0025,0.0026,0.0024,0.00251
+1559354400,0.0025,0.0026,0.0024,abc,10
+1559358000,0.0025,0.0024,0.0026,0.0025,10
+1559361600,0.0027,0.0026,0.0024,0.0025,10
+1559365201,0.0025,0.0026,0.0024,0.0025,10
+"1559368800,0.0025,0.0026,0.0024,0.0025,10
+`
+	data, rowErrs, err := Read(strings.NewReader(file), Options{Interval: hour, Format: "CSV", TimeFormat: TimeUnix})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 {
+		t.Fatalf("read %d candles, expected 1", len(data))
+	}
+	tick := data[0]
+	if tick.Time.Unix() != 1559347200 || tick.Close.String() != "0.00251" || tick.Volume.String() != "10.5" {
+		t.Errorf("unexpected candle %+v", tick)
+	}
+
+	// Rows count the header
+	expected := []struct {
+		row    int
+		reason string
+	}{
+		{3, "wrong number of fields"},
+		{4, "invalid close"},
+		{5, "high"},
+		{6, "open or close is above high"},
+		{7, "not aligned"},
+		{8, "quote"},
+	}
+	if len(rowErrs) != len(expected) {
+		t.Fatalf("row errors %v", rowErrs)
+	}
+	for i, e := range expected {
+		if rowErrs[i].Row != e.row || !strings.Contains(rowErrs[i].Reason, e.reason) {
+			t.Errorf("row error %d is %v, expected row %d with %q", i, rowErrs[i], e.row, e.reason)
+		}
+	}
+}
+
+func TestReadCSVColumns(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		opts  Options
+		valid bool
+	}{
+		{
+			name:  "renamed columns",
+			file:  "date;o;h;l;c;v\n2019-06-01 00:00;1;2;1;2;3\n",
+			opts:  Options{Columns: map[string]string{"time": "date", "open": "o", "high": "h", "low": "l", "close": "c", "volume": "v"}, TimeFormat: "2006-01-02 15:04", Delimiter: ';'},
+			valid: true,
+		},
+		{
+			name:  "indices without header",
+			file:  "3,1,2,1,2,1559347200000\n",
+			opts:  Options{NoHeader: true, Columns: map[string]string{"time": "5", "open": "1", "high": "2", "low": "3", "close": "4", "volume": "0"}, TimeFormat: TimeUnixMs},
+			valid: true,
+		},
+		{
+			name: "names without header",
+			file: "1559347200,1,2,1,2,3\n",
+			opts: Options{NoHeader: true, TimeFormat: TimeUnix},
+		},
+		{
+			name: "column not in header",
+			file: "time,open,high,low,close\n",
+			opts: Options{TimeFormat: TimeUnix},
+		},
+		{
+			name: "unknown field",
+			file: "time,open,high,low,close,volume\n",
+			opts: Options{Columns: map[string]string{"price": "close"}, TimeFormat: TimeUnix},
+		},
+	}
+	for _, test := range tests {
+		test.opts.Interval = hour
+		test.opts.Format = FormatCSV
+		data, rowErrs, err := Read(strings.NewReader(test.file), test.opts)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%s: file accepted", test.name)
+			}
+			continue
+		}
+		if err != nil || len(rowErrs) != 0 || len(data) != 1 {
+			t.Errorf("%s: read %+v, row errors %v, error %v", test.name, data, rowErrs, err)
+			continue
+		}
+		if data[0].Time.Unix() != 1559347200 || data[0].Volume.String() != "3" {
+			t.Errorf("%s: unexpected candle %+v", test.name, data[0])
+		}
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	file := `[
+		{"time": 1559347200, "open": "0.0025", "high": 0.0026, "low": 0.0024, "close": 0.00251, "volume": 10},
+		[1559350800, 0.0025, 0.0026, 0.0024, 0.00251, 10],
+		"candle",
+		{"time": 1559354400, "open": 0.0025, "high": 0.0026, "low": 0.0024, "close": 0.00251},
+		{"time": 1559358000, "open": -1, "high": 0.0026, "low": 0.0024, "close": 0.00251, "volume": 10}
+	]`
+	// Array elements are addressed by index, objects by name
+	data, rowErrs, err := Read(strings.NewReader(file), Options{Interval: hour, Format: FormatJSON, TimeFormat: TimeUnix})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 || data[0].Open.String() != "0.0025" || data[0].Close.String() != "0.00251" {
+		t.Errorf("read %+v", data)
+	}
+	if len(rowErrs) != 4 || rowErrs[0].Row != 2 || rowErrs[1].Row != 3 || rowErrs[2].Row != 4 || rowErrs[3].Row != 5 {
+		t.Errorf("row errors %v", rowErrs)
+	}
+
+	if _, _, err = Read(strings.NewReader(`{"time": 1}`), Options{Interval: hour, Format: FormatJSON}); err == nil {
+		t.Error("object accepted")
+	}
+	if _, _, err = Read(strings.NewReader(`[]`), Options{Interval: hour, Format: "xml"}); err == nil {
+		t.Error("unsupported format accepted")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() ticks.Tick {
+		return ticks.Tick{Time: time.Unix(1559347200, 0), Open: dec(t, "2"), High: dec(t, "3"), Low: dec(t, "1"),
+			Close: dec(t, "2"), Volume: dec(t, "2")}
+	}
+	tests := []struct {
+		name   string
+		modify func(*ticks.Tick)
+		ok     bool
+	}{
+		{"valid", func(*ticks.Tick) {}, true},
+		{"zero volume", func(tick *ticks.Tick) { tick.Volume = dec(t, "0") }, true},
+		{"zero time", func(tick *ticks.Tick) { tick.Time = time.Time{} }, false},
+		{"future", func(tick *ticks.Tick) { tick.Time = time.Now().Add(2 * hour).Truncate(hour) }, false},
+		{"unaligned", func(tick *ticks.Tick) { tick.Time = tick.Time.Add(time.Minute) }, false},
+		{"zero price", func(tick *ticks.Tick) { tick.Open = dec(t, "0") }, false},
+		{"missing price", func(tick *ticks.Tick) { tick.Close = nil }, false},
+		{"negative volume", func(tick *ticks.Tick) { tick.Volume = dec(t, "-1") }, false},
+		{"high below low", func(tick *ticks.Tick) { tick.High, tick.Low = tick.Low, tick.High }, false},
+		{"close above high", func(tick *ticks.Tick) { tick.Close = dec(t, "4") }, false},
+		{"open below low", func(tick *ticks.Tick) { tick.Open = dec(t, "0.5") }, false},
+	}
+	for _, test := range tests {
+		tick := valid()
+		test.modify(&tick)
+		if err := Validate(tick, hour); (err == nil) != test.ok {
+			t.Errorf("%s: error %v", test.name, err)
+		}
+	}
+	if err := Validate(valid(), 0); err == nil {
+		t.Error("zero interval accepted")
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	at := func(hours int64, price string) ticks.Tick {
+		d := dec(t, price)
+		return ticks.Tick{Time: time.Unix(1559347200+hours*3600, 0), Open: d, High: d, Low: d, Close: d, Volume: d}
+	}
+	data := []ticks.Tick{at(2, "1"), at(0, "1"), at(1, "1"), at(0, "2"), at(2, "2")}
+
+	var result Result
+	prepared := Prepare(data, &result)
+	if len(prepared) != 3 || result.Duplicates != 2 {
+		t.Fatalf("prepared %+v with %d duplicates", prepared, result.Duplicates)
+	}
+	for i, tick := range prepared {
+		if tick.Time.Unix() != 1559347200+int64(i)*3600 || tick.Close.String() != "1" {
+			t.Errorf("tick %d is %+v", i, tick)
+		}
+	}
+	if result.First.Unix() != 1559347200 || result.Last.Unix() != 1559354400 {
+		t.Errorf("result %+v", result)
+	}
+	// The read ticks are left in their order
+	if data[0].Time.Unix() != 1559354400 {
+		t.Error("read ticks reordered")
+	}
+}
+
+// memoryStore skips the ticks at times it already stored
+type memoryStore struct {
+	stored map[int64]bool
+}
+
+func (s *memoryStore) RegisterExchange(ctx context.Context, exchange ticks.ExchangeData, pair string) (time.Time, time.Time, time.Time, error) {
+	return time.Time{}, time.Time{}, time.Time{}, nil
+}
+
+func (s *memoryStore) StoreExchangeTicks(ctx context.Context, exchange string, interval int, pair string, data []ticks.Tick) (time.Time, int, error) {
+	added := 0
+	for _, tick := range data {
+		if !s.stored[tick.Time.Unix()] {
+			s.stored[tick.Time.Unix()] = true
+			added++
+		}
+	}
+	return data[len(data)-1].Time, added, nil
+}
+
+func TestStoreCountsInserted(t *testing.T) {
+	data := make([]ticks.Tick, batchSize+10)
+	for i := range data {
+		data[i].Time = time.Unix(1559347200+int64(i)*3600, 0)
+	}
+	store := &memoryStore{stored: map[int64]bool{data[0].Time.Unix(): true, data[batchSize].Time.Unix(): true}}
+	stored, err := Store(context.Background(), store, ticks.ExchangeData{Name: "test"}, hour, "BTC/DCR", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != len(data)-2 {
+		t.Errorf("stored %d, expected %d", stored, len(data)-2)
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ericlagergren/decimal"
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+	"github.com/raedahgroup/dcrextdata/helpers"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+
+	TimeUnix   = helpers.TimeUnix
+	TimeUnixMs = helpers.TimeUnixMs
+
+	FieldTime   = "time"
+	FieldOpen   = "open"
+	FieldHigh   = "high"
+	FieldLow    = "low"
+	FieldClose  = "close"
+	FieldVolume = "volume"
+)
+
+var fields = []string{FieldTime, FieldOpen, FieldHigh, FieldLow, FieldClose, FieldVolume}
+
+// Options describes the layout of a candle file. Columns maps each candle
+// field to a column, given either as a header name or a zero based index.
+// Fields without a mapping are read from the column of the same name. Records
+// are validated as candles of Interval.
+type Options struct {
+	Interval   time.Duration
+	Format     string
+	Columns    map[string]string
+	TimeFormat string
+	NoHeader   bool
+	Delimiter  rune
+}
+
+// RowError reports a record that could not be read or failed validation
+type RowError struct {
+	Row    int
+	Reason string
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Reason)
+}
+
+type record func(field string) (interface{}, bool)
+
+// Read parses the candles in r. Records that cannot be parsed or fail
+// validation are skipped and reported as row errors, other errors abort the
+// read.
+func Read(r io.Reader, opts Options) ([]ticks.Tick, []RowError, error) {
+	for field := range opts.Columns {
+		if !isField(field) {
+			return nil, nil, fmt.Errorf("Unknown candle field %q, expected one of %v", field, fields)
+		}
+	}
+
+	switch strings.ToLower(opts.Format) {
+	case FormatCSV:
+		return readCSV(r, opts)
+	case FormatJSON:
+		return readJSON(r, opts)
+	default:
+		return nil, nil, fmt.Errorf("Unsupported file format %q", opts.Format)
+	}
+}
+
+func isField(name string) bool {
+	for _, field := range fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (opts Options) column(field string) string {
+	if column, ok := opts.Columns[field]; ok {
+		return column
+	}
+	return field
+}
+
+func readCSV(r io.Reader, opts Options) ([]ticks.Tick, []RowError, error) {
+	reader := csv.NewReader(r)
+	if opts.Delimiter != 0 {
+		reader.Comma = opts.Delimiter
+	}
+	reader.TrimLeadingSpace = true
+
+	indices := make(map[string]int, len(fields))
+	row := 0
+	if !opts.NoHeader {
+		header, err := reader.Read()
+		if err != nil {
+			return nil, nil, fmt.Errorf("Unable to read header: %v", err)
+		}
+		row++
+		names := make(map[string]int, len(header))
+		for i, name := range header {
+			names[strings.TrimSpace(name)] = i
+		}
+		for _, field := range fields {
+			column := opts.column(field)
+			if i, ok := names[column]; ok {
+				indices[field] = i
+			} else if i, err := strconv.Atoi(column); err == nil {
+				indices[field] = i
+			} else {
+				return nil, nil, fmt.Errorf("Column %q for %s not found in header", column, field)
+			}
+		}
+	} else {
+		for _, field := range fields {
+			column := opts.column(field)
+			i, err := strconv.Atoi(column)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Column for %s must be an index when the file has no header, got %q", field, column)
+			}
+			indices[field] = i
+		}
+	}
+
+	var data []ticks.Tick
+	var rowErrs []RowError
+	for {
+		values, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row++
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); ok {
+				rowErrs = append(rowErrs, RowError{Row: row, Reason: err.Error()})
+				continue
+			}
+			return nil, nil, err
+		}
+
+		tick, err := parseRecord(func(field string) (interface{}, bool) {
+			i := indices[field]
+			if i < 0 || i >= len(values) {
+				return nil, false
+			}
+			return values[i], true
+		}, opts)
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Row: row, Reason: err.Error()})
+			continue
+		}
+		data = append(data, tick)
+	}
+	return data, rowErrs, nil
+}
+
+// readJSON reads a JSON array whose elements are either objects keyed by
+// column name or arrays addressed by index.
+func readJSON(r io.Reader, opts Options) ([]ticks.Tick, []RowError, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var elements []interface{}
+	if err := decoder.Decode(&elements); err != nil {
+		return nil, nil, fmt.Errorf("Unable to decode JSON array: %v", err)
+	}
+
+	var data []ticks.Tick
+	var rowErrs []RowError
+	for i, element := range elements {
+		row := i + 1
+		var rec record
+		switch e := element.(type) {
+		case map[string]interface{}:
+			rec = func(field string) (interface{}, bool) {
+				v, ok := e[opts.column(field)]
+				return v, ok
+			}
+		case []interface{}:
+			rec = func(field string) (interface{}, bool) {
+				index, err := strconv.Atoi(opts.column(field))
+				if err != nil || index < 0 || index >= len(e) {
+					return nil, false
+				}
+				return e[index], true
+			}
+		default:
+			rowErrs = append(rowErrs, RowError{Row: row, Reason: "element is neither an object nor an array"})
+			continue
+		}
+
+		tick, err := parseRecord(rec, opts)
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Row: row, Reason: err.Error()})
+			continue
+		}
+		data = append(data, tick)
+	}
+	return data, rowErrs, nil
+}
+
+func parseRecord(rec record, opts Options) (ticks.Tick, error) {
+	var tick ticks.Tick
+	prices := map[string]**decimal.Big{
+		FieldOpen:   &tick.Open,
+		FieldHigh:   &tick.High,
+		FieldLow:    &tick.Low,
+		FieldClose:  &tick.Close,
+		FieldVolume: &tick.Volume,
+	}
+	for field, dest := range prices {
+		value, ok := rec(field)
+		if !ok {
+			return tick, fmt.Errorf("missing %s", field)
+		}
+		d, err := toDecimal(value)
+		if err != nil {
+			return tick, fmt.Errorf("invalid %s: %v", field, err)
+		}
+		*dest = d
+	}
+
+	value, ok := rec(FieldTime)
+	if !ok {
+		return tick, fmt.Errorf("missing %s", FieldTime)
+	}
+	t, err := parseTime(value, opts.TimeFormat)
+	if err != nil {
+		return tick, fmt.Errorf("invalid %s: %v", FieldTime, err)
+	}
+	tick.Time = t
+	return tick, Validate(tick, opts.Interval)
+}
+
+// toDecimal parses the text of a CSV field or JSON number or string without
+// rounding it
+func toDecimal(value interface{}) (*decimal.Big, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return helpers.ParseDecimal(v.String())
+	case string:
+		return helpers.ParseDecimal(v)
+	default:
+		return nil, fmt.Errorf("unexpected value %v", value)
+	}
+}
+
+func parseTime(value interface{}, format string) (time.Time, error) {
+	var str string
+	switch v := value.(type) {
+	case json.Number:
+		str = v.String()
+	case string:
+		str = v
+	default:
+		return time.Time{}, fmt.Errorf("unexpected value %v", value)
+	}
+
+	return helpers.ParseTime(str, format)
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// BackfillStatus is the state of a backfill job
+type BackfillStatus string
+
+const (
+	// BackfillPending jobs wait to be run
+	BackfillPending BackfillStatus = "pending"
+	// BackfillRunning jobs are being run, or were interrupted by a shutdown
+	// and continue from their checkpoint on the next start
+	BackfillRunning BackfillStatus = "running"
+	// BackfillPaused jobs are skipped until they are resumed
+	BackfillPaused BackfillStatus = "paused"
+	// BackfillCancelled jobs are never run again
+	BackfillCancelled BackfillStatus = "cancelled"
+	// BackfillDone jobs have collected their whole range
+	BackfillDone BackfillStatus = "done"
+	// BackfillFailed jobs stopped on an error and can be resumed
+	BackfillFailed BackfillStatus = "failed"
+)
+
+// Backfill actions change the status of a job
+const (
+	BackfillPause  = "pause"
+	BackfillResume = "resume"
+	BackfillCancel = "cancel"
+)
+
+// backfillTransitions maps each action to the status it sets and the
+// statuses it applies to
+var backfillTransitions = map[string]struct {
+	to   BackfillStatus
+	from []BackfillStatus
+}{
+	BackfillPause:  {BackfillPaused, []BackfillStatus{BackfillPending, BackfillRunning}},
+	BackfillResume: {BackfillPending, []BackfillStatus{BackfillPaused, BackfillFailed}},
+	BackfillCancel: {BackfillCancelled, []BackfillStatus{BackfillPending, BackfillRunning, BackfillPaused, BackfillFailed}},
+}
+
+// BackfillTransition returns the status set by action and the statuses of
+// the jobs it applies to
+func BackfillTransition(action string) (BackfillStatus, []BackfillStatus, error) {
+	transition, ok := backfillTransitions[action]
+	if !ok {
+		return "", nil, fmt.Errorf("Unknown backfill action %q, expected %s, %s or %s", action,
+			BackfillPause, BackfillResume, BackfillCancel)
+	}
+	return transition.to, transition.from, nil
+}
+
+// BackfillJob collects the candles of a market at an interval, in minutes,
+// from Start to End. Checkpoint is the newest candle collected so far.
+// ResumedAt and ResumedFrom are the time and checkpoint at which the job
+// last started running, nil before it first ran.
+type BackfillJob struct {
+	ID           int            `json:"id"`
+	Exchange     string         `json:"exchange"`
+	CurrencyPair string         `json:"currency_pair"`
+	Interval     int            `json:"interval"`
+	Start        time.Time      `json:"start"`
+	End          time.Time      `json:"end"`
+	Checkpoint   time.Time      `json:"checkpoint"`
+	Status       BackfillStatus `json:"status"`
+	Error        string         `json:"error"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	ResumedAt    *time.Time     `json:"resumed_at"`
+	ResumedFrom  *time.Time     `json:"resumed_from"`
+}
+
+// Progress returns the percentage of the job's range collected
+func (job BackfillJob) Progress() float64 {
+	total := job.End.Sub(job.Start)
+	if total <= 0 || !job.Checkpoint.Before(job.End) {
+		return 100
+	}
+	return 100 * float64(job.Checkpoint.Sub(job.Start)) / float64(total)
+}
+
+// ETA estimates how long a running job takes to complete, from the pace of
+// its current run. It returns false when there is no estimate.
+func (job BackfillJob) ETA() (time.Duration, bool) {
+	if job.Status != BackfillRunning || job.ResumedAt == nil || job.ResumedFrom == nil {
+		return 0, false
+	}
+	covered := job.Checkpoint.Sub(*job.ResumedFrom)
+	elapsed := job.UpdatedAt.Sub(*job.ResumedAt)
+	if covered <= 0 || elapsed <= 0 {
+		return 0, false
+	}
+	remaining := job.End.Sub(job.Checkpoint)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return time.Duration(float64(remaining) / float64(covered) * float64(elapsed)), true
+}
+
+// BackfillStore keeps the backfill jobs and the candles they collect
+type BackfillStore interface {
+	Store
+	CreateBackfillJob(ctx context.Context, job BackfillJob) (int, error)
+	BackfillJobs(ctx context.Context) ([]BackfillJob, error)
+	// NextBackfillJob returns the oldest running or pending job, nil when
+	// there is none
+	NextBackfillJob(ctx context.Context) (*BackfillJob, error)
+	// SetBackfillStatus changes the status of a job whose status is one of
+	// from and returns whether it did. Jobs set running record when and
+	// from which checkpoint they resumed.
+	SetBackfillStatus(ctx context.Context, id int, status BackfillStatus, errMsg string, from ...BackfillStatus) (bool, error)
+	// UpdateBackfillCheckpoint stores the checkpoint of a job and returns its
+	// current status
+	UpdateBackfillCheckpoint(ctx context.Context, id int, checkpoint time.Time) (BackfillStatus, error)
+}
+
+// NewBackfillJob validates a job collecting the market of the exchange
+// named name at interval between start and end
+func NewBackfillJob(name, pair string, interval time.Duration, start, end time.Time) (BackfillJob, error) {
+	market, err := ParsePair(pair)
+	if err != nil {
+		return BackfillJob{}, err
+	}
+	pair = market.Pair()
+	if _, err = backfillExchange(nil, name, pair, interval); err != nil {
+		return BackfillJob{}, err
+	}
+	if !start.Before(end) {
+		return BackfillJob{}, fmt.Errorf("Backfill start %s is not before its end %s",
+			start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339))
+	}
+	return BackfillJob{
+		Exchange:     name,
+		CurrencyPair: pair,
+		Interval:     int(interval.Minutes()),
+		Start:        start,
+		End:          end,
+		Checkpoint:   start,
+		Status:       BackfillPending,
+	}, nil
+}
+
+// Backfill collects the candles of the job's market after its checkpoint up
+// to its end. progress is called with the new checkpoint after every page
+// and stops the backfill with its error.
+func Backfill(ctx context.Context, store Store, job BackfillJob, progress func(time.Time) error) (time.Time, error) {
+	interval := time.Duration(job.Interval) * time.Minute
+	xc, err := backfillExchange(store, job.Exchange, job.CurrencyPair, interval)
+	if err != nil {
+		return job.Checkpoint, err
+	}
+	last := job.Checkpoint
+	err = xc.fetch(ctx, &last, interval, job.End, progress)
+	return last, err
+}
+
+// backfillExchange returns a collector of the market of the exchange named
+// name that is not registered with store, after checking that the exchange
+// serves historic candles of the market at interval
+func backfillExchange(store Store, name, pair string, interval time.Duration) (*commonExchange, error) {
+	exchange, err := findExchange(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exchange.apiLimited {
+		return nil, fmt.Errorf("%s only serves its latest candles", name)
+	}
+	if _, ok := exchange.markets[pair]; !ok {
+		return nil, fmt.Errorf("%s has no %s market", name, pair)
+	}
+	if interval != exchange.ShortInterval && interval != exchange.LongInterval && interval != exchange.HistoricInterval {
+		return nil, fmt.Errorf("%s candles are collected at %v, %v and %v, not %v", name,
+			exchange.ShortInterval, exchange.LongInterval, exchange.HistoricInterval, interval)
+	}
+	source, ok := discoverySources[name]
+	if !ok {
+		return nil, fmt.Errorf("%s does not support backfills", name)
+	}
+	return &commonExchange{
+		ExchangeData: exchange,
+		client:       &http.Client{Timeout: clientTimeout},
+		store:        store,
+		apiResp:      source.newTickResp(),
+		currencyPair: pair,
+	}, nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewBackfillJob(t *testing.T) {
+	start, end := time.Unix(1540353600, 0), time.Unix(1560000000, 0)
+	job, err := NewBackfillJob(Binance, "btc/dcr", fiveMin, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.CurrencyPair != btcdcrPair || job.Interval != 5 || job.Checkpoint != start || job.Status != BackfillPending {
+		t.Errorf("unexpected job %+v", job)
+	}
+
+	invalid := []struct {
+		name, exchange, pair string
+		interval             time.Duration
+		start, end           time.Time
+	}{
+		{"latest candles only", Huobi, btcdcrPair, fiveMin, start, end},
+		{"unknown market", Binance, "USDT/DCR", fiveMin, start, end},
+		{"uncollected interval", Binance, btcdcrPair, time.Minute, start, end},
+		{"empty range", Binance, btcdcrPair, fiveMin, end, start},
+	}
+	for _, test := range invalid {
+		if _, err := NewBackfillJob(test.exchange, test.pair, test.interval, test.start, test.end); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestBackfillProgress(t *testing.T) {
+	start := time.Unix(1540000000, 0)
+	resumedAt := time.Unix(1560000000, 0)
+	resumedFrom := start.Add(10 * oneDay)
+	job := BackfillJob{
+		Start:       start,
+		End:         start.Add(100 * oneDay),
+		Checkpoint:  start.Add(40 * oneDay),
+		Status:      BackfillRunning,
+		UpdatedAt:   resumedAt.Add(time.Minute),
+		ResumedAt:   &resumedAt,
+		ResumedFrom: &resumedFrom,
+	}
+	if progress := job.Progress(); progress != 40 {
+		t.Errorf("progress: got %v, expected 40", progress)
+	}
+	// 30 days were collected in a minute, 60 days are left
+	if eta, ok := job.ETA(); !ok || eta != 2*time.Minute {
+		t.Errorf("eta: got %v %v, expected 2m0s", eta, ok)
+	}
+
+	job.Status = BackfillPaused
+	if _, ok := job.ETA(); ok {
+		t.Error("expected no eta for a paused job")
+	}
+}
@@ -6,6 +6,7 @@ package ticks
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"sync"
 	"time"
@@ -16,13 +17,21 @@ import (
 const (
 	Bittrex         = "bittrex"
 	Bittrexusd      = Bittrex + "usd"
-	bittrexAPIURL   = "https://bittrex.com/Api/v2.0/pub/market/GetTicks"
+	bittrexAPIURL   = "https://api.bittrex.com/v3/markets/%s/candles/%s"
 	Poloniex        = "poloniex"
 	poloniexAPIURL  = "https://poloniex.com/public"
 	Bleutrade       = "bleutrade"
 	bleutradeAPIURL = "https://bleutrade.com/api/v3/public/getcandles"
 	Binance         = "binance"
 	binanceAPIURL   = "https://api.binance.com/api/v1/klines"
+	Huobi           = "huobi"
+	huobiAPIURL     = "https://api.huobi.pro/market/history/kline"
+	Kucoin          = "kucoin"
+	kucoinAPIURL    = "https://api.kucoin.com/api/v1/market/candles"
+	Okex            = "okex"
+	okexAPIURL      = "https://www.okex.com/api/spot/v3/instruments/%s/candles"
+	Upbit           = "upbit"
+	upbitAPIURL     = "https://api.upbit.com/v1/candles/%s"
 
 	btcdcrPair = "BTC/DCR"
 	usdbtcPair = "USD/BTC"
@@ -33,9 +42,23 @@ const (
 	apprxBinanceStart  int64 = 1540353600
 	binanceVolumeLimit int64 = 1000
 
+	apprxBittrexStart    int64 = 1462060800
+	apprxBittrexUSDStart int64 = 1546300800
+
 	apprxPoloniexStart  int64 = 1463364000
 	poloniexVolumeLimit int64 = 20000
 
+	huobiVolumeLimit int64 = 2000
+
+	apprxKucoinStart  int64 = 1546300800
+	kucoinVolumeLimit int64 = 1500
+
+	apprxOkexStart  int64 = 1546300800
+	okexVolumeLimit int64 = 200
+
+	apprxUpbitStart  int64 = 1546300800
+	upbitVolumeLimit int64 = 200
+
 	clientTimeout = time.Minute
 
 	IntervalShort    = "short"
@@ -52,13 +75,41 @@ var (
 		Poloniex:   NewPoloniexCollector,
 		Bleutrade:  NewBleutradeCollector,
 		Binance:    NewBinanceCollector,
+		Huobi:      NewHuobiCollector,
+		Kucoin:     NewKucoinCollector,
+		Okex:       NewOkexCollector,
+		Upbit:      NewUpbitCollector,
 	}
 
 	bittrexIntervals = map[float64]string{
-		300:   "fiveMin",
-		1800:  "thirtyMin",
-		3600:  "hour",
-		86400: "day",
+		60:    "MINUTE_1",
+		300:   "MINUTE_5",
+		3600:  "HOUR_1",
+		86400: "DAY_1",
+	}
+
+	// bittrexRecentSpans is how far back the recent candles of each interval
+	// reach. Older candles are served a day, month or year at a time by the
+	// historical endpoint, addressed with the date layout of the interval.
+	bittrexRecentSpans = map[float64]time.Duration{
+		60:    oneDay,
+		300:   oneDay,
+		3600:  31 * oneDay,
+		86400: 366 * oneDay,
+	}
+	bittrexHistoricalLayouts = map[float64]string{
+		60:    "2006/1/2",
+		300:   "2006/1/2",
+		3600:  "2006/1",
+		86400: "2006",
+	}
+	// bittrexHistoricalSpans is the years, months and days served by a
+	// historical request of each interval
+	bittrexHistoricalSpans = map[float64][3]int{
+		60:    {0, 0, 1},
+		300:   {0, 0, 1},
+		3600:  {0, 1, 0},
+		86400: {1, 0, 0},
 	}
 
 	bleutradeIntervals = map[float64]string{
@@ -73,12 +124,31 @@ var (
 		86400: "1d",
 	}
 
+	huobiIntervals = map[float64]string{
+		300:   "5min",
+		3600:  "60min",
+		86400: "1day",
+	}
+
+	kucoinIntervals = map[float64]string{
+		300:   "5min",
+		3600:  "1hour",
+		86400: "1day",
+	}
+
+	upbitIntervals = map[float64]string{
+		300:   "minutes/5",
+		3600:  "minutes/60",
+		86400: "days",
+	}
+
 	poloniexData = ExchangeData{
 		Name:       Poloniex,
 		WebsiteURL: "https://poloniex.com",
-		availableCPairs: map[string]string{
-			btcdcrPair: "BTC_DCR",
+		markets: map[string]Market{
+			btcdcrPair: {Base: "DCR", Quote: "BTC", Symbol: "BTC_DCR"},
 		},
+		volumeSide:       QuoteVolume,
 		apiLimited:       true,
 		ShortInterval:    fiveMin,
 		LongInterval:     2 * time.Hour,
@@ -97,10 +167,12 @@ var (
 	binanceData = ExchangeData{
 		Name:       Binance,
 		WebsiteURL: "https://binance.com",
-		availableCPairs: map[string]string{
-			btcdcrPair: "DCRBTC",
+		markets: map[string]Market{
+			btcdcrPair: {Base: "DCR", Quote: "BTC", Symbol: "DCRBTC"},
 		},
+		volumeSide:       BaseVolume,
 		apiLimited:       true,
+		pageLimit:        binanceVolumeLimit,
 		ShortInterval:    fiveMin,
 		LongInterval:     time.Hour,
 		HistoricInterval: oneDay,
@@ -120,28 +192,45 @@ var (
 	bittrexData = ExchangeData{
 		Name:       Bittrex,
 		WebsiteURL: "https://bittrex.com",
-		availableCPairs: map[string]string{
-			btcdcrPair: "BTC-DCR",
-			usdbtcPair: "USD-BTC",
+		markets: map[string]Market{
+			btcdcrPair: {Base: "DCR", Quote: "BTC", Symbol: "DCR-BTC"},
+			usdbtcPair: {Base: "BTC", Quote: "USD", Symbol: "BTC-USD"},
 		},
-		apiLimited:       false,
+		volumeSide:       QuoteVolume,
+		apiLimited:       true,
 		ShortInterval:    fiveMin,
 		LongInterval:     time.Hour,
 		HistoricInterval: oneDay,
 		requester: func(last time.Time, interval time.Duration, cpair string) (string, error) {
-			return helpers.AddParams(bittrexAPIURL, map[string]interface{}{
-				"marketName":   cpair,
-				"tickInterval": bittrexIntervals[interval.Seconds()],
-			})
+			base := fmt.Sprintf(bittrexAPIURL, cpair, bittrexIntervals[interval.Seconds()])
+			next := last.Add(interval).UTC()
+			if time.Since(next) < bittrexRecentSpans[interval.Seconds()] {
+				return base + "/recent", nil
+			}
+			return base + "/historical/" + next.Format(bittrexHistoricalLayouts[interval.Seconds()]), nil
+		},
+		periodEnd: func(last time.Time, interval time.Duration) time.Time {
+			next := last.Add(interval).UTC()
+			if time.Since(next) < bittrexRecentSpans[interval.Seconds()] {
+				return zeroTime
+			}
+			layout := bittrexHistoricalLayouts[interval.Seconds()]
+			start, err := time.Parse(layout, next.Format(layout))
+			if err != nil {
+				return zeroTime
+			}
+			span := bittrexHistoricalSpans[interval.Seconds()]
+			return start.AddDate(span[0], span[1], span[2])
 		},
 	}
 
 	bleutradeData = ExchangeData{
 		Name:       Bleutrade,
 		WebsiteURL: "https://bleutrade.com",
-		availableCPairs: map[string]string{
-			btcdcrPair: "DCR_BTC",
+		markets: map[string]Market{
+			btcdcrPair: {Base: "DCR", Quote: "BTC", Symbol: "DCR_BTC"},
 		},
+		volumeSide:       BaseVolume,
 		apiLimited:       false,
 		ShortInterval:    time.Hour,
 		LongInterval:     4 * time.Hour,
@@ -153,6 +242,103 @@ var (
 			})
 		},
 	}
+
+	huobiData = ExchangeData{
+		Name:       Huobi,
+		WebsiteURL: "https://www.huobi.com",
+		markets: map[string]Market{
+			btcdcrPair: {Base: "DCR", Quote: "BTC", Symbol: "dcrbtc"},
+		},
+		volumeSide:       BaseVolume,
+		apiLimited:       false,
+		ShortInterval:    fiveMin,
+		LongInterval:     time.Hour,
+		HistoricInterval: oneDay,
+		requester: func(last time.Time, interval time.Duration, cpair string) (string, error) {
+			// Only the latest candles are served, up to the size limit
+			return helpers.AddParams(huobiAPIURL, map[string]interface{}{
+				"symbol": cpair,
+				"period": huobiIntervals[interval.Seconds()],
+				"size":   huobiVolumeLimit,
+			})
+		},
+	}
+
+	kucoinData = ExchangeData{
+		Name:       Kucoin,
+		WebsiteURL: "https://www.kucoin.com",
+		markets: map[string]Market{
+			btcdcrPair: {Base: "DCR", Quote: "BTC", Symbol: "DCR-BTC"},
+		},
+		volumeSide:       BaseVolume,
+		apiLimited:       true,
+		pageLimit:        kucoinVolumeLimit,
+		ShortInterval:    fiveMin,
+		LongInterval:     time.Hour,
+		HistoricInterval: oneDay,
+		requester: func(last time.Time, interval time.Duration, cpair string) (string, error) {
+			start := last.Unix()
+			return helpers.AddParams(kucoinAPIURL, map[string]interface{}{
+				"symbol":  cpair,
+				"type":    kucoinIntervals[interval.Seconds()],
+				"startAt": start,
+				"endAt":   start + kucoinVolumeLimit*int64(interval.Seconds()),
+			})
+		},
+	}
+
+	okexData = ExchangeData{
+		Name:       Okex,
+		WebsiteURL: "https://www.okex.com",
+		markets: map[string]Market{
+			btcdcrPair: {Base: "DCR", Quote: "BTC", Symbol: "DCR-BTC"},
+		},
+		volumeSide:       BaseVolume,
+		apiLimited:       true,
+		pageLimit:        okexVolumeLimit,
+		ShortInterval:    fiveMin,
+		LongInterval:     time.Hour,
+		HistoricInterval: oneDay,
+		requester: func(last time.Time, interval time.Duration, cpair string) (string, error) {
+			// The start time is exclusive
+			start := last.Add(-time.Second).UTC()
+			end := start.Add(time.Duration(okexVolumeLimit) * interval)
+			return helpers.AddParams(fmt.Sprintf(okexAPIURL, cpair), map[string]interface{}{
+				"granularity": int(interval.Seconds()),
+				"start":       start.Format(okexTimeFormat),
+				"end":         end.Format(okexTimeFormat),
+			})
+		},
+	}
+
+	upbitData = ExchangeData{
+		Name:       Upbit,
+		WebsiteURL: "https://upbit.com",
+		markets: map[string]Market{
+			btcdcrPair: {Base: "DCR", Quote: "BTC", Symbol: "BTC-DCR"},
+		},
+		volumeSide:       BaseVolume,
+		apiLimited:       true,
+		pageLimit:        upbitVolumeLimit,
+		ShortInterval:    fiveMin,
+		LongInterval:     time.Hour,
+		HistoricInterval: oneDay,
+		requester: func(last time.Time, interval time.Duration, cpair string) (string, error) {
+			// Candles are served backwards from the exclusive to time
+			to := last.Add(time.Duration(upbitVolumeLimit) * interval)
+			if now := time.Now(); to.After(now) {
+				to = now
+			}
+			return helpers.AddParams(fmt.Sprintf(upbitAPIURL, upbitIntervals[interval.Seconds()]), map[string]interface{}{
+				"market": cpair,
+				"count":  upbitVolumeLimit,
+				"to":     to.UTC().Format(upbitTimeFormat),
+			})
+		},
+	}
+
+	exchanges = []*ExchangeData{&poloniexData, &binanceData, &bittrexData, &bleutradeData,
+		&huobiData, &kucoinData, &okexData, &upbitData}
 )
 
 type commonExchange struct {
@@ -167,6 +353,14 @@ type commonExchange struct {
 	apiResp      tickable
 }
 
+func (xc *commonExchange) Exchange() string {
+	return xc.Name
+}
+
+func (xc *commonExchange) CurrencyPair() string {
+	return xc.currencyPair
+}
+
 func (xc *commonExchange) GetShort(ctx context.Context) error {
 	return xc.Get(ctx, &xc.lastShort, xc.ShortInterval, IntervalShort)
 }
@@ -185,8 +379,27 @@ func (xc *commonExchange) Get(ctx context.Context, last *time.Time, interval tim
 	}
 	xc.respLock.Lock()
 	defer xc.respLock.Unlock()
-	for time.Now().Add(-interval).Unix() > last.Unix() {
-		requestURL, err := xc.requester(*last, interval, xc.availableCPairs[xc.currencyPair])
+	return xc.fetch(ctx, last, interval, zeroTime, nil)
+}
+
+// fetch stores the candles after last up to the one before until, or before
+// the current time when until is zero, advancing last to the newest stored
+// candle. progress, if not nil, is called with last after every page and
+// stops the fetch with its error.
+func (xc *commonExchange) fetch(ctx context.Context, last *time.Time, interval time.Duration, until time.Time, progress func(time.Time) error) error {
+	for {
+		end := until
+		if end == zeroTime {
+			end = time.Now()
+		}
+		if end.Add(-interval).Unix() <= last.Unix() {
+			break
+		}
+		var periodEnd time.Time
+		if xc.periodEnd != nil {
+			periodEnd = xc.periodEnd(*last, interval)
+		}
+		requestURL, err := xc.requester(*last, interval, xc.markets[xc.currencyPair].Symbol)
 		if err != nil {
 			return err
 		}
@@ -195,17 +408,71 @@ func (xc *commonExchange) Get(ctx context.Context, last *time.Time, interval tim
 		if err != nil {
 			return err
 		}
+		if resp, ok := xc.apiResp.(failable); ok {
+			if err = resp.err(); err != nil {
+				return fmt.Errorf("%s: %v", xc.Name, err)
+			}
+		}
 
 		ticks := xc.apiResp.toTicks(last.Unix())
+		if len(ticks) == 0 {
+			if periodEnd != zeroTime {
+				// The period has no candles, such as one before the market
+				// was listed, so the next request is for the period after it
+				*last = periodEnd.Add(-interval)
+				if progress != nil {
+					if err = progress(*last); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if xc.pageLimit == 0 {
+				// No new candle yet, which the health monitor reports
+				// once it lasts
+				break
+			}
+			// Skip past periods without trades, such as those before the
+			// market was listed, but not past the end, where the candles
+			// still to come will be
+			next := last.Add(time.Duration(xc.pageLimit) * interval)
+			reachedEnd := !next.Before(end.Add(-interval))
+			if reachedEnd {
+				next = end.Add(-interval)
+			}
+			*last = next
+			if progress != nil {
+				if err = progress(*last); err != nil {
+					return err
+				}
+			}
+			if reachedEnd {
+				break
+			}
+			continue
+		}
+		DeriveVolumes(ticks, xc.volumeSide)
 
-		newLast, err := xc.store.StoreExchangeTicks(ctx, xc.Name, int(interval.Minutes()), xc.currencyPair, ticks)
+		newLast, _, err := xc.store.StoreExchangeTicks(ctx, xc.Name, int(interval.Minutes()), xc.currencyPair, ticks)
 		if err != nil {
 			return err
 		}
 		if newLast != zeroTime {
 			*last = newLast
 		}
-		if !xc.apiLimited || len(ticks) == 1 {
+		// The candles missing at the end of the period, such as during an
+		// outage, will not be published, so the next request is for the
+		// period after it
+		skipped := periodEnd != zeroTime && last.Before(periodEnd.Add(-interval))
+		if skipped {
+			*last = periodEnd.Add(-interval)
+		}
+		if progress != nil {
+			if err = progress(*last); err != nil {
+				return err
+			}
+		}
+		if !skipped && (!xc.apiLimited || len(ticks) == 1) {
 			break
 		}
 	}
@@ -213,7 +480,7 @@ func (xc *commonExchange) Get(ctx context.Context, last *time.Time, interval tim
 }
 
 func newCollector(ctx context.Context, store Store, exchange ExchangeData, currencyPair string, historicStart time.Time, response tickable) (Collector, error) {
-	lastShort, lastLong, lastHistoric, err := store.RegisterExchange(ctx, exchange)
+	lastShort, lastLong, lastHistoric, err := store.RegisterExchange(ctx, exchange, currencyPair)
 	if err != nil {
 		return nil, err
 	}
@@ -246,11 +513,11 @@ func NewPoloniexCollector(ctx context.Context, store Store) (Collector, error) {
 }
 
 func NewBittrexCollector(ctx context.Context, store Store) (Collector, error) {
-	return newCollector(ctx, store, bittrexData, btcdcrPair, zeroTime, new(bittrexAPIResponse))
+	return newCollector(ctx, store, bittrexData, btcdcrPair, time.Unix(apprxBittrexStart, 0), new(bittrexAPIResponse))
 }
 
 func NewBittrexUSDCollector(ctx context.Context, store Store) (Collector, error) {
-	return newCollector(ctx, store, bittrexData, usdbtcPair, zeroTime, new(bittrexAPIResponse))
+	return newCollector(ctx, store, bittrexData, usdbtcPair, time.Unix(apprxBittrexUSDStart, 0), new(bittrexAPIResponse))
 }
 
 func NewBleutradeCollector(ctx context.Context, store Store) (Collector, error) {
@@ -260,3 +527,19 @@ func NewBleutradeCollector(ctx context.Context, store Store) (Collector, error)
 func NewBinanceCollector(ctx context.Context, store Store) (Collector, error) {
 	return newCollector(ctx, store, binanceData, btcdcrPair, time.Unix(apprxBinanceStart, 0), new(binanceAPIResponse))
 }
+
+func NewHuobiCollector(ctx context.Context, store Store) (Collector, error) {
+	return newCollector(ctx, store, huobiData, btcdcrPair, zeroTime, new(huobiAPIResponse))
+}
+
+func NewKucoinCollector(ctx context.Context, store Store) (Collector, error) {
+	return newCollector(ctx, store, kucoinData, btcdcrPair, time.Unix(apprxKucoinStart, 0), new(kucoinAPIResponse))
+}
+
+func NewOkexCollector(ctx context.Context, store Store) (Collector, error) {
+	return newCollector(ctx, store, okexData, btcdcrPair, time.Unix(apprxOkexStart, 0), new(okexAPIResponse))
+}
+
+func NewUpbitCollector(ctx context.Context, store Store) (Collector, error) {
+	return newCollector(ctx, store, upbitData, btcdcrPair, time.Unix(apprxUpbitStart, 0), new(upbitAPIResponse))
+}
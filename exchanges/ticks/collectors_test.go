@@ -0,0 +1,185 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/internal/fixtures"
+)
+
+type memoryStore struct {
+	ticks []Tick
+}
+
+func (store *memoryStore) RegisterExchange(ctx context.Context, exchange ExchangeData, pair string) (time.Time, time.Time, time.Time, error) {
+	return zeroTime, zeroTime, zeroTime, nil
+}
+
+func (store *memoryStore) StoreExchangeTicks(ctx context.Context, exchange string, interval int, pair string, data []Tick) (time.Time, int, error) {
+	store.ticks = append(store.ticks, data...)
+	return data[len(data)-1].Time, len(data), nil
+}
+
+func TestBittrexEmptyHistoricalPeriod(t *testing.T) {
+	const base = "/v3/markets/DCR-BTC/candles/MINUTE_5/historical/"
+	server, requested := fixtures.Server(t, map[string]string{
+		base + "2019/4/28": "bittrex_empty.json",
+		base + "2019/4/29": "bittrex_empty.json",
+		base + "2019/4/30": "bittrex.json",
+	})
+
+	exchange := bittrexData
+	exchange.requester = func(last time.Time, interval time.Duration, cpair string) (string, error) {
+		requestURL, err := bittrexData.requester(last, interval, cpair)
+		return strings.Replace(requestURL, "https://api.bittrex.com", server.URL, 1), err
+	}
+	store := new(memoryStore)
+	xc := &commonExchange{
+		ExchangeData: &exchange,
+		client:       server.Client(),
+		store:        store,
+		apiResp:      new(bittrexAPIResponse),
+		currencyPair: btcdcrPair,
+	}
+
+	// Collecting from before the listing skips the days without candles
+	last := time.Date(2019, 4, 27, 23, 55, 0, 0, time.UTC)
+	var checkpoints []time.Time
+	progress := func(checkpoint time.Time) error {
+		checkpoints = append(checkpoints, checkpoint)
+		return nil
+	}
+	until := time.Date(2019, 5, 1, 0, 5, 0, 0, time.UTC)
+	if err := xc.fetch(context.Background(), &last, fiveMin, until, progress); err != nil {
+		t.Fatal(err)
+	}
+
+	wantPaths := []string{base + "2019/4/28", base + "2019/4/29", base + "2019/4/30"}
+	if got := requested(); strings.Join(got, ",") != strings.Join(wantPaths, ",") {
+		t.Errorf("requested %v, expected %v", got, wantPaths)
+	}
+	if len(store.ticks) != 3 {
+		t.Errorf("stored %d ticks, expected 3", len(store.ticks))
+	}
+	if want := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC); !last.Equal(want) {
+		t.Errorf("last %v, expected %v", last, want)
+	}
+	if len(checkpoints) != 3 || !checkpoints[0].Equal(time.Date(2019, 4, 28, 23, 55, 0, 0, time.UTC)) {
+		t.Errorf("checkpoints %v, expected one per page starting at 2019-04-28 23:55", checkpoints)
+	}
+}
+
+func TestBittrexHistoricalOutage(t *testing.T) {
+	const base = "/v3/markets/DCR-BTC/candles/MINUTE_5/historical/"
+	server, requested := fixtures.Server(t, map[string]string{
+		base + "2019/4/29": "bittrex_outage.json",
+		base + "2019/4/30": "bittrex.json",
+	})
+
+	exchange := bittrexData
+	exchange.requester = func(last time.Time, interval time.Duration, cpair string) (string, error) {
+		requestURL, err := bittrexData.requester(last, interval, cpair)
+		return strings.Replace(requestURL, "https://api.bittrex.com", server.URL, 1), err
+	}
+	store := new(memoryStore)
+	xc := &commonExchange{
+		ExchangeData: &exchange,
+		client:       server.Client(),
+		store:        store,
+		apiResp:      new(bittrexAPIResponse),
+		currencyPair: btcdcrPair,
+	}
+
+	// The day of the last stored candle has no candle after it, the next
+	// day is requested instead of the same one again
+	last := time.Date(2019, 4, 29, 12, 0, 0, 0, time.UTC)
+	until := time.Date(2019, 5, 1, 0, 5, 0, 0, time.UTC)
+	if err := xc.fetch(context.Background(), &last, fiveMin, until, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	wantPaths := []string{base + "2019/4/29", base + "2019/4/30"}
+	if got := requested(); strings.Join(got, ",") != strings.Join(wantPaths, ",") {
+		t.Errorf("requested %v, expected %v", got, wantPaths)
+	}
+	if len(store.ticks) != 4 {
+		t.Errorf("stored %d ticks, expected 4", len(store.ticks))
+	}
+	if want := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC); !last.Equal(want) {
+		t.Errorf("last %v, expected %v", last, want)
+	}
+}
+
+func TestEmptyPagesStopAtTheEnd(t *testing.T) {
+	server, requested := fixtures.Server(t, map[string]string{
+		"/page1": "kucoin_empty.json",
+		"/page2": "kucoin_empty.json",
+		"/page3": "kucoin.json",
+	})
+
+	exchange := kucoinData
+	exchange.requester = func(time.Time, time.Duration, string) (string, error) {
+		return fmt.Sprintf("%s/page%d", server.URL, len(requested())+1), nil
+	}
+	store := new(memoryStore)
+	xc := &commonExchange{
+		ExchangeData: &exchange,
+		client:       server.Client(),
+		store:        store,
+		apiResp:      new(kucoinAPIResponse),
+		currencyPair: btcdcrPair,
+	}
+
+	// A market without candles yet skips the empty pages up to the end
+	last := time.Date(2019, 4, 24, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2019, 4, 30, 0, 0, 0, 0, time.UTC)
+	if err := xc.fetch(context.Background(), &last, fiveMin, until, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := until.Add(-fiveMin); !last.Equal(want) || len(requested()) != 2 {
+		t.Fatalf("last %v after %d requests, expected %v after 2", last, len(requested()), want)
+	}
+
+	// The candles that come after are all collected
+	until = time.Date(2019, 5, 1, 0, 5, 0, 0, time.UTC)
+	if err := xc.fetch(context.Background(), &last, fiveMin, until, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.ticks) != 3 {
+		t.Errorf("stored %d ticks, expected 3", len(store.ticks))
+	}
+	if want := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC); !last.Equal(want) {
+		t.Errorf("last %v, expected %v", last, want)
+	}
+}
+
+func TestBittrexPeriodEnd(t *testing.T) {
+	tests := []struct {
+		name     string
+		last     time.Time
+		interval time.Duration
+		want     time.Time
+	}{
+		{"day", time.Date(2019, 4, 30, 12, 0, 0, 0, time.UTC), fiveMin,
+			time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"last candle of the day", time.Date(2019, 4, 30, 23, 55, 0, 0, time.UTC), fiveMin,
+			time.Date(2019, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{"month", time.Date(2019, 12, 10, 5, 0, 0, 0, time.UTC), time.Hour,
+			time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"year", time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC), oneDay,
+			time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"recent", time.Now().Add(-time.Hour), fiveMin, zeroTime},
+	}
+	for _, test := range tests {
+		if got := bittrexData.periodEnd(test.last, test.interval); !got.Equal(test.want) {
+			t.Errorf("%s: got %v, expected %v", test.name, got, test.want)
+		}
+	}
+}
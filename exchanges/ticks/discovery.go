@@ -0,0 +1,316 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/helpers"
+)
+
+const (
+	dcrAsset = "DCR"
+
+	poloniexMarketsURL  = "https://poloniex.com/public?command=returnTicker"
+	bittrexMarketsURL   = "https://api.bittrex.com/v3/markets"
+	binanceMarketsURL   = "https://api.binance.com/api/v1/exchangeInfo"
+	bleutradeMarketsURL = "https://bleutrade.com/api/v3/public/getmarkets"
+	huobiMarketsURL     = "https://api.huobi.pro/v1/common/symbols"
+	kucoinMarketsURL    = "https://api.kucoin.com/api/v1/symbols"
+	okexMarketsURL      = "https://www.okex.com/api/spot/v3/instruments"
+	upbitMarketsURL     = "https://api.upbit.com/v1/market/all"
+)
+
+// marketLister is implemented by market list responses
+type marketLister interface {
+	toMarkets() []Market
+}
+
+// discoverySource is where the markets of an exchange are listed and how the
+// candles of its markets are decoded
+type discoverySource struct {
+	url         string
+	markets     func() marketLister
+	newTickResp func() tickable
+}
+
+var discoverySources = map[string]discoverySource{
+	Poloniex: {poloniexMarketsURL,
+		func() marketLister { return new(poloniexMarketsResponse) },
+		func() tickable { return new(poloniexAPIResponse) }},
+	Bittrex: {bittrexMarketsURL,
+		func() marketLister { return new(bittrexMarketsResponse) },
+		func() tickable { return new(bittrexAPIResponse) }},
+	Binance: {binanceMarketsURL,
+		func() marketLister { return new(binanceMarketsResponse) },
+		func() tickable { return new(binanceAPIResponse) }},
+	Bleutrade: {bleutradeMarketsURL,
+		func() marketLister { return new(bleutradeMarketsResponse) },
+		func() tickable { return new(bleutradeAPIResponse) }},
+	Huobi: {huobiMarketsURL,
+		func() marketLister { return new(huobiMarketsResponse) },
+		func() tickable { return new(huobiAPIResponse) }},
+	Kucoin: {kucoinMarketsURL,
+		func() marketLister { return new(kucoinMarketsResponse) },
+		func() tickable { return new(kucoinAPIResponse) }},
+	Okex: {okexMarketsURL,
+		func() marketLister { return new(okexMarketsResponse) },
+		func() tickable { return new(okexAPIResponse) }},
+	Upbit: {upbitMarketsURL,
+		func() marketLister { return new(upbitMarketsResponse) },
+		func() tickable { return new(upbitAPIResponse) }},
+}
+
+func findExchange(name string) (*ExchangeData, error) {
+	for _, exchange := range exchanges {
+		if exchange.Name == name {
+			return exchange, nil
+		}
+	}
+	return nil, fmt.Errorf("Unknown exchange %s", name)
+}
+
+// DiscoveryExchanges returns the names of the exchanges whose markets can be
+// discovered
+func DiscoveryExchanges() []string {
+	var names []string
+	for name, source := range discoverySources {
+		if source.url != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DiscoverMarkets returns the markets of the exchange that involve DCR but
+// are not collected by its built in or defined collectors
+func DiscoverMarkets(ctx context.Context, client *http.Client, name string) ([]Market, error) {
+	exchange, err := findExchange(name)
+	if err != nil {
+		return nil, err
+	}
+	source, ok := discoverySources[name]
+	if !ok || source.url == "" {
+		return nil, fmt.Errorf("%s does not list its markets", name)
+	}
+
+	resp := source.markets()
+	if err = helpers.GetResponse(ctx, client, source.url, resp); err != nil {
+		return nil, err
+	}
+	if resp, ok := resp.(failable); ok {
+		if err = resp.err(); err != nil {
+			return nil, err
+		}
+	}
+
+	var markets []Market
+	for _, market := range resp.toMarkets() {
+		market.Base, market.Quote = strings.ToUpper(market.Base), strings.ToUpper(market.Quote)
+		if market.Base != dcrAsset && market.Quote != dcrAsset {
+			continue
+		}
+		if _, configured := exchange.markets[market.Pair()]; configured {
+			continue
+		}
+		markets = append(markets, market)
+	}
+	return markets, nil
+}
+
+// NewMarketCollector creates a collector for a market of the exchange that
+// is not among its configured markets, such as a discovered market. The
+// historic ticks of the market are collected from historicStart.
+func NewMarketCollector(ctx context.Context, store Store, name string, market Market, historicStart time.Time) (Collector, error) {
+	exchange, err := findExchange(name)
+	if err != nil {
+		return nil, err
+	}
+	source, ok := discoverySources[name]
+	if !ok {
+		return nil, fmt.Errorf("%s does not support additional markets", name)
+	}
+
+	data := *exchange
+	data.markets = make(map[string]Market, len(exchange.markets)+1)
+	for pair, m := range exchange.markets {
+		data.markets[pair] = m
+	}
+	data.markets[market.Pair()] = market
+	return newCollector(ctx, store, data, market.Pair(), historicStart, source.newTickResp())
+}
+
+// MarketCollectorName returns the name a collector of an additional market
+// of the exchange is known by
+func MarketCollectorName(exchange, pair string) string {
+	return exchange + ":" + pair
+}
+
+// splitSymbol returns the markets of symbols made of two assets joined by
+// sep, with the base asset first unless quoteFirst is set
+func splitSymbol(symbol, sep string, quoteFirst bool) (Market, bool) {
+	parts := strings.Split(symbol, sep)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return Market{}, false
+	}
+	if quoteFirst {
+		return Market{Base: parts[1], Quote: parts[0], Symbol: symbol}, true
+	}
+	return Market{Base: parts[0], Quote: parts[1], Symbol: symbol}, true
+}
+
+// poloniexMarketsResponse is the ticker of every market, keyed by QUOTE_BASE
+type poloniexMarketsResponse map[string]json.RawMessage
+
+func (resp poloniexMarketsResponse) toMarkets() []Market {
+	markets := make([]Market, 0, len(resp))
+	for symbol := range resp {
+		if market, ok := splitSymbol(symbol, "_", true); ok {
+			markets = append(markets, market)
+		}
+	}
+	return markets
+}
+
+type bittrexMarketsResponse []struct {
+	Symbol string `json:"symbol"`
+	Base   string `json:"baseCurrencySymbol"`
+	Quote  string `json:"quoteCurrencySymbol"`
+	Status string `json:"status"`
+}
+
+func (resp bittrexMarketsResponse) toMarkets() []Market {
+	markets := make([]Market, 0, len(resp))
+	for _, m := range resp {
+		if m.Status == "ONLINE" {
+			markets = append(markets, Market{Base: m.Base, Quote: m.Quote, Symbol: m.Symbol})
+		}
+	}
+	return markets
+}
+
+type binanceMarketsResponse struct {
+	Symbols []struct {
+		Symbol string `json:"symbol"`
+		Base   string `json:"baseAsset"`
+		Quote  string `json:"quoteAsset"`
+		Status string `json:"status"`
+	} `json:"symbols"`
+}
+
+func (resp binanceMarketsResponse) toMarkets() []Market {
+	markets := make([]Market, 0, len(resp.Symbols))
+	for _, m := range resp.Symbols {
+		if m.Status == "TRADING" {
+			markets = append(markets, Market{Base: m.Base, Quote: m.Quote, Symbol: m.Symbol})
+		}
+	}
+	return markets
+}
+
+type bleutradeMarketsResponse struct {
+	Result []struct {
+		Symbol   string `json:"MarketName"`
+		Base     string `json:"MarketAsset"`
+		Quote    string `json:"BaseAsset"`
+		IsActive bool   `json:"IsActive"`
+	} `json:"result"`
+}
+
+func (resp bleutradeMarketsResponse) toMarkets() []Market {
+	markets := make([]Market, 0, len(resp.Result))
+	for _, m := range resp.Result {
+		if m.IsActive {
+			markets = append(markets, Market{Base: m.Base, Quote: m.Quote, Symbol: m.Symbol})
+		}
+	}
+	return markets
+}
+
+type huobiMarketsResponse struct {
+	Status string `json:"status"`
+	Data   []struct {
+		Symbol string `json:"symbol"`
+		Base   string `json:"base-currency"`
+		Quote  string `json:"quote-currency"`
+		State  string `json:"state"`
+	} `json:"data"`
+	Error string `json:"err-msg"`
+}
+
+func (resp huobiMarketsResponse) err() error {
+	return huobiAPIResponse{Status: resp.Status, Error: resp.Error}.err()
+}
+
+func (resp huobiMarketsResponse) toMarkets() []Market {
+	markets := make([]Market, 0, len(resp.Data))
+	for _, m := range resp.Data {
+		if m.State == "online" {
+			markets = append(markets, Market{Base: m.Base, Quote: m.Quote, Symbol: m.Symbol})
+		}
+	}
+	return markets
+}
+
+type kucoinMarketsResponse struct {
+	Code string `json:"code"`
+	Data []struct {
+		Symbol        string `json:"symbol"`
+		Base          string `json:"baseCurrency"`
+		Quote         string `json:"quoteCurrency"`
+		EnableTrading bool   `json:"enableTrading"`
+	} `json:"data"`
+	Msg string `json:"msg"`
+}
+
+func (resp kucoinMarketsResponse) err() error {
+	return kucoinAPIResponse{Code: resp.Code, Msg: resp.Msg}.err()
+}
+
+func (resp kucoinMarketsResponse) toMarkets() []Market {
+	markets := make([]Market, 0, len(resp.Data))
+	for _, m := range resp.Data {
+		if m.EnableTrading {
+			markets = append(markets, Market{Base: m.Base, Quote: m.Quote, Symbol: m.Symbol})
+		}
+	}
+	return markets
+}
+
+type okexMarketsResponse []struct {
+	Symbol string `json:"instrument_id"`
+	Base   string `json:"base_currency"`
+	Quote  string `json:"quote_currency"`
+}
+
+func (resp okexMarketsResponse) toMarkets() []Market {
+	markets := make([]Market, 0, len(resp))
+	for _, m := range resp {
+		markets = append(markets, Market{Base: m.Base, Quote: m.Quote, Symbol: m.Symbol})
+	}
+	return markets
+}
+
+// upbitMarketsResponse lists markets as QUOTE-BASE
+type upbitMarketsResponse []struct {
+	Symbol string `json:"market"`
+}
+
+func (resp upbitMarketsResponse) toMarkets() []Market {
+	markets := make([]Market, 0, len(resp))
+	for _, m := range resp {
+		if market, ok := splitSymbol(m.Symbol, "-", true); ok {
+			markets = append(markets, market)
+		}
+	}
+	return markets
+}
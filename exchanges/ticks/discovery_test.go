@@ -0,0 +1,42 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticks
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarketLists(t *testing.T) {
+	tests := []struct {
+		exchange string
+		body     string
+		want     Market
+	}{
+		{Poloniex, `{"BTC_DCR": {"last": "0.0045"}}`,
+			Market{Base: "DCR", Quote: "BTC", Symbol: "BTC_DCR"}},
+		{Bittrex, `[{"symbol": "DCR-USDT", "baseCurrencySymbol": "DCR", "quoteCurrencySymbol": "USDT", "status": "ONLINE"},
+			{"symbol": "DCR-ETH", "baseCurrencySymbol": "DCR", "quoteCurrencySymbol": "ETH", "status": "OFFLINE"}]`,
+			Market{Base: "DCR", Quote: "USDT", Symbol: "DCR-USDT"}},
+		{Binance, `{"symbols": [{"symbol": "DCRUSDT", "status": "TRADING", "baseAsset": "DCR", "quoteAsset": "USDT"},
+			{"symbol": "DCRBNB", "status": "BREAK", "baseAsset": "DCR", "quoteAsset": "BNB"}]}`,
+			Market{Base: "DCR", Quote: "USDT", Symbol: "DCRUSDT"}},
+		{Huobi, `{"status": "ok", "data": [{"symbol": "dcrusdt", "base-currency": "dcr", "quote-currency": "usdt", "state": "online"}]}`,
+			Market{Base: "dcr", Quote: "usdt", Symbol: "dcrusdt"}},
+		{Upbit, `[{"market": "BTC-DCR", "english_name": "Decred"}, {"market": "KRW"}]`,
+			Market{Base: "DCR", Quote: "BTC", Symbol: "BTC-DCR"}},
+	}
+
+	for _, test := range tests {
+		resp := discoverySources[test.exchange].markets()
+		if err := json.Unmarshal([]byte(test.body), resp); err != nil {
+			t.Fatalf("%s: %v", test.exchange, err)
+		}
+		markets := resp.toMarkets()
+		if len(markets) != 1 || markets[0] != test.want {
+			t.Errorf("%s: got markets %v, expected %v", test.exchange, markets, test.want)
+		}
+	}
+}
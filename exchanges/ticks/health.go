@@ -0,0 +1,80 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticks
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Health is how reliable the collected series of a market currently is
+type Health string
+
+const (
+	// HealthHealthy markets are answered by the exchange and have recent
+	// candles
+	HealthHealthy Health = "healthy"
+	// HealthStale markets are answered by the exchange but have had no new
+	// candle for a while
+	HealthStale Health = "stale"
+	// HealthDown markets have failed every request for a while
+	HealthDown Health = "down"
+)
+
+// MarketHealth is the collection state of a market of an exchange. LastTick
+// is the time of the newest stored candle, LastSuccess and LastFailure the
+// times of the last successful and failed request cycles, nil when there
+// were none. Health is the last classification, in effect since Since.
+type MarketHealth struct {
+	Exchange     string     `json:"exchange"`
+	CurrencyPair string     `json:"currency_pair"`
+	LastTick     *time.Time `json:"last_tick"`
+	LastSuccess  *time.Time `json:"last_success"`
+	LastFailure  *time.Time `json:"last_failure"`
+	LastError    string     `json:"last_error"`
+	Health       Health     `json:"health"`
+	Since        time.Time  `json:"since"`
+}
+
+// Classify returns the health of the market at now and the reason for it. A
+// market is down when its last request failed and none succeeded within
+// downAfter, and stale when it had no new candle within staleAfter.
+func (m MarketHealth) Classify(now time.Time, staleAfter, downAfter time.Duration) (Health, string) {
+	if m.LastFailure != nil && (m.LastSuccess == nil ||
+		(m.LastFailure.After(*m.LastSuccess) && now.Sub(*m.LastSuccess) > downAfter)) {
+		return HealthDown, m.LastError
+	}
+	if m.LastTick == nil {
+		return HealthStale, "no candles stored"
+	}
+	if now.Sub(*m.LastTick) > staleAfter {
+		return HealthStale, fmt.Sprintf("no new candle since %s", m.LastTick.UTC().Format(time.RFC3339))
+	}
+	return HealthHealthy, ""
+}
+
+// Outage is a period in which a market was stale or down. End is nil while
+// the outage lasts.
+type Outage struct {
+	Exchange     string     `json:"exchange"`
+	CurrencyPair string     `json:"currency_pair"`
+	Health       Health     `json:"health"`
+	Start        time.Time  `json:"start"`
+	End          *time.Time `json:"end"`
+	Reason       string     `json:"reason"`
+}
+
+// HealthStore keeps the collection state of markets and their outages
+type HealthStore interface {
+	// RecordCollection stores the outcome of a request cycle of a market,
+	// a failure when err is not nil
+	RecordCollection(ctx context.Context, exchange, pair string, at time.Time, err error) error
+	// ExchangeHealth returns the collection state of all recorded markets
+	ExchangeHealth(ctx context.Context) ([]MarketHealth, error)
+	// SetMarketHealth changes the health of a market from at, ending its
+	// current outage and starting a new one unless health is HealthHealthy
+	SetMarketHealth(ctx context.Context, exchange, pair string, health Health, at time.Time, reason string) error
+}
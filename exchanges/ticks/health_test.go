@@ -0,0 +1,53 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClassifyHealth(t *testing.T) {
+	now := time.Unix(1560000000, 0)
+	at := func(ago time.Duration) *time.Time {
+		t := now.Add(-ago)
+		return &t
+	}
+	tests := []struct {
+		name   string
+		market MarketHealth
+		health Health
+	}{
+		{"healthy", MarketHealth{LastTick: at(10 * time.Minute), LastSuccess: at(time.Minute)}, HealthHealthy},
+		{"recent failure", MarketHealth{LastTick: at(10 * time.Minute), LastSuccess: at(10 * time.Minute),
+			LastFailure: at(time.Minute)}, HealthHealthy},
+		{"failing", MarketHealth{LastTick: at(10 * time.Minute), LastSuccess: at(time.Hour),
+			LastFailure: at(time.Minute)}, HealthDown},
+		{"never answered", MarketHealth{LastFailure: at(time.Minute)}, HealthDown},
+		{"recovered", MarketHealth{LastTick: at(10 * time.Minute), LastSuccess: at(time.Minute),
+			LastFailure: at(time.Hour)}, HealthHealthy},
+		{"no candles", MarketHealth{LastSuccess: at(time.Minute)}, HealthStale},
+		{"stale", MarketHealth{LastTick: at(3 * time.Hour), LastSuccess: at(time.Minute)}, HealthStale},
+	}
+	for _, test := range tests {
+		health, _ := test.market.Classify(now, 2*time.Hour, 30*time.Minute)
+		if health != test.health {
+			t.Errorf("%s: got %s, expected %s", test.name, health, test.health)
+		}
+	}
+}
+
+func TestCollectedIntervals(t *testing.T) {
+	intervals := CollectedIntervals()
+	want := []int{5, 60, 120, 240, 1440}
+	if len(intervals) != len(want) {
+		t.Fatalf("collected intervals %v, expected %v", intervals, want)
+	}
+	for i := range want {
+		if intervals[i] != want[i] {
+			t.Errorf("collected intervals %v, expected %v", intervals, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ericlagergren/decimal"
+)
+
+// VolumeSide is the asset of a market that a volume is denominated in
+type VolumeSide int
+
+const (
+	BaseVolume VolumeSide = iota
+	QuoteVolume
+)
+
+func (side VolumeSide) String() string {
+	if side == QuoteVolume {
+		return "quote"
+	}
+	return "base"
+}
+
+// ParseVolumeSide returns the volume side named "base" or "quote"
+func ParseVolumeSide(name string) (VolumeSide, error) {
+	switch strings.ToLower(name) {
+	case "base":
+		return BaseVolume, nil
+	case "quote":
+		return QuoteVolume, nil
+	default:
+		return BaseVolume, fmt.Errorf("Unknown volume side %q, expected base or quote", name)
+	}
+}
+
+// Market is a currency pair as traded on an exchange. Base is the asset being
+// bought and sold and Quote the asset it is priced in, so DCR traded for BTC
+// has base DCR and quote BTC. Symbol is the exchange's own name of the market.
+type Market struct {
+	Base   string `json:"base"`
+	Quote  string `json:"quote"`
+	Symbol string `json:"symbol"`
+}
+
+// Pair returns the canonical QUOTE/BASE name the market's ticks are stored under
+func (m Market) Pair() string {
+	return m.Quote + "/" + m.Base
+}
+
+// ParsePair returns the market of a canonical QUOTE/BASE pair. The exchange
+// symbol of the returned market is empty.
+func ParsePair(pair string) (Market, error) {
+	parts := strings.Split(strings.ToUpper(pair), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return Market{}, fmt.Errorf("Invalid currency pair %q, expected QUOTE/BASE", pair)
+	}
+	return Market{Base: parts[1], Quote: parts[0]}, nil
+}
+
+// DeriveVolumes sets the base and quote volume of the ticks from the Volume
+// reported on side. The other side is converted at the typical price of the
+// candle, the average of its high, low and close. Ticks that already have both
+// volumes, from exchanges that report them, are left as they are.
+func DeriveVolumes(data []Tick, side VolumeSide) {
+	three := decimal.New(3, 0)
+	for i := range data {
+		tick := &data[i]
+		if tick.BaseVolume != nil && tick.QuoteVolume != nil {
+			continue
+		}
+		price := new(decimal.Big).Add(tick.High, tick.Low)
+		price.Add(price, tick.Close).Quo(price, three)
+		if side == QuoteVolume {
+			tick.QuoteVolume = tick.Volume
+			tick.BaseVolume = new(decimal.Big)
+			if price.Sign() > 0 {
+				tick.BaseVolume.Quo(tick.Volume, price).Reduce()
+			}
+		} else {
+			tick.BaseVolume = tick.Volume
+			tick.QuoteVolume = new(decimal.Big).Mul(tick.Volume, price).Reduce()
+		}
+	}
+}
+
+// ReportedVolumeSides returns the volume side reported by each exchange
+func ReportedVolumeSides() map[string]VolumeSide {
+	sides := make(map[string]VolumeSide, len(exchanges))
+	for _, exchange := range exchanges {
+		sides[exchange.Name] = exchange.volumeSide
+	}
+	return sides
+}
+
+// CollectedIntervals returns the candle intervals, in minutes, collected from
+// any exchange, shortest first
+func CollectedIntervals() []int {
+	seen := make(map[int]bool)
+	var intervals []int
+	for _, exchange := range exchanges {
+		for _, interval := range []time.Duration{exchange.ShortInterval, exchange.LongInterval,
+			exchange.HistoricInterval} {
+			minutes := int(interval.Minutes())
+			if minutes > 0 && !seen[minutes] {
+				seen[minutes] = true
+				intervals = append(intervals, minutes)
+			}
+		}
+	}
+	sort.Ints(intervals)
+	return intervals
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/helpers"
+)
+
+// ExchangeStatus is the lifecycle state of an exchange in the registry
+type ExchangeStatus string
+
+const (
+	// StatusActive exchanges are collected from
+	StatusActive ExchangeStatus = "active"
+	// StatusSuspended exchanges are temporarily not collected from
+	StatusSuspended ExchangeStatus = "suspended"
+	// StatusDelisted exchanges no longer trade DCR and are not collected
+	// from, their stored ticks are kept
+	StatusDelisted ExchangeStatus = "delisted"
+)
+
+// ParseExchangeStatus returns the status named name
+func ParseExchangeStatus(name string) (ExchangeStatus, error) {
+	status := ExchangeStatus(strings.ToLower(name))
+	switch status {
+	case StatusActive, StatusSuspended, StatusDelisted:
+		return status, nil
+	default:
+		return "", fmt.Errorf("Unknown exchange status %q, expected %s, %s or %s", name,
+			StatusActive, StatusSuspended, StatusDelisted)
+	}
+}
+
+// ExchangeInfo is the registry entry of an exchange. Intervals are the candle
+// intervals collected, in minutes.
+type ExchangeInfo struct {
+	Name       string         `json:"name"`
+	WebsiteURL string         `json:"website"`
+	Status     ExchangeStatus `json:"status"`
+	Intervals  []int          `json:"intervals"`
+	Notes      string         `json:"notes"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	Markets    []MarketInfo   `json:"markets"`
+}
+
+// MarketInfo is the registry entry of a market of an exchange. ListedAt and
+// DelistedAt are nil when unknown. DiscoveredAt is set for markets found by
+// market discovery rather than configured, which are only collected when
+// Collect is set.
+type MarketInfo struct {
+	Market
+	Pair         string     `json:"pair"`
+	ListedAt     *time.Time `json:"listed_at"`
+	DelistedAt   *time.Time `json:"delisted_at"`
+	DiscoveredAt *time.Time `json:"discovered_at"`
+	Collect      bool       `json:"collect"`
+	Notes        string     `json:"notes"`
+}
+
+// Delisted returns whether the market was delisted by the given time
+func (m MarketInfo) Delisted(at time.Time) bool {
+	return m.DelistedAt != nil && !m.DelistedAt.After(at)
+}
+
+// ParseMarketDate parses a listing date of a MarketUpdate, given as
+// 2006-01-02 or RFC3339. An empty value or none clears the date.
+func ParseMarketDate(value string) (*time.Time, error) {
+	if value == "" || strings.EqualFold(value, "none") {
+		return new(time.Time), nil
+	}
+	t, err := helpers.ParseDate(value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ExchangeUpdate holds the registry fields of an exchange to change, nil
+// fields are left as they are
+type ExchangeUpdate struct {
+	Status *ExchangeStatus `json:"status"`
+	Notes  *string         `json:"notes"`
+}
+
+// MarketUpdate holds the registry fields of a market to change, nil fields
+// are left as they are and zero times clear the date
+type MarketUpdate struct {
+	ListedAt   *time.Time `json:"listed_at"`
+	DelistedAt *time.Time `json:"delisted_at"`
+	Collect    *bool      `json:"collect"`
+	Notes      *string    `json:"notes"`
+}
+
+// Registry stores the lifecycle of exchanges and their markets
+type Registry interface {
+	SyncExchangeRegistry(ctx context.Context, exchanges []ExchangeInfo) error
+	ExchangeRegistry(ctx context.Context) ([]ExchangeInfo, error)
+	UpdateExchange(ctx context.Context, name string, update ExchangeUpdate) error
+	UpdateExchangeMarket(ctx context.Context, name, pair string, update MarketUpdate) error
+	RecordDiscoveredMarkets(ctx context.Context, name string, markets []Market, collect bool) ([]Market, error)
+}
+
+// KnownExchanges returns the registry entries of the built in exchanges and
+// the loaded REST exchanges, as active exchanges with their configured
+// markets and intervals
+func KnownExchanges() []ExchangeInfo {
+	infos := make([]ExchangeInfo, 0, len(exchanges))
+	for _, exchange := range exchanges {
+		info := ExchangeInfo{
+			Name:       exchange.Name,
+			WebsiteURL: exchange.WebsiteURL,
+			Status:     StatusActive,
+			Intervals: []int{int(exchange.ShortInterval.Minutes()), int(exchange.LongInterval.Minutes()),
+				int(exchange.HistoricInterval.Minutes())},
+		}
+		for pair, market := range exchange.markets {
+			info.Markets = append(info.Markets, MarketInfo{Market: market, Pair: pair, Collect: true})
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExchangeStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status ExchangeStatus
+		err    bool
+	}{
+		{"active", StatusActive, false},
+		{"Suspended", StatusSuspended, false},
+		{"DELISTED", StatusDelisted, false},
+		{"paused", "", true},
+		{"", "", true},
+	}
+	for _, test := range tests {
+		status, err := ParseExchangeStatus(test.name)
+		if (err != nil) != test.err {
+			t.Errorf("%q: unexpected error %v", test.name, err)
+		}
+		if status != test.status {
+			t.Errorf("%q: got %q, expected %q", test.name, status, test.status)
+		}
+	}
+}
+
+func TestParseMarketDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+		err   bool
+	}{
+		{"", time.Time{}, false},
+		{"none", time.Time{}, false},
+		{"None", time.Time{}, false},
+		{"2019-05-01", time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC), false},
+		{"2019-05-01T12:30:00Z", time.Date(2019, 5, 1, 12, 30, 0, 0, time.UTC), false},
+		{"2019-05-01T14:30:00+02:00", time.Date(2019, 5, 1, 12, 30, 0, 0, time.UTC), false},
+		{"01/05/2019", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+	}
+	for _, test := range tests {
+		date, err := ParseMarketDate(test.value)
+		if test.err {
+			if err == nil {
+				t.Errorf("%q: expected an error", test.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.value, err)
+			continue
+		}
+		if date == nil || !date.Equal(test.want) {
+			t.Errorf("%q: got %v, expected %v", test.value, date, test.want)
+		}
+	}
+}
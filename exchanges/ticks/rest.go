@@ -0,0 +1,359 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/helpers"
+)
+
+const (
+	// PaginationNone requests the latest candles once per collection
+	PaginationNone = "none"
+	// PaginationStart requests candles from the last stored time until the
+	// present, Limit candles at a time
+	PaginationStart = "start"
+)
+
+var restFields = []string{"time", "open", "high", "low", "close", "volume"}
+
+// RESTInterval is a candle interval and its name in an exchange's API
+type RESTInterval struct {
+	Minutes int    `json:"minutes"`
+	Name    string `json:"name"`
+}
+
+func (i RESTInterval) duration() time.Duration {
+	return time.Duration(i.Minutes) * time.Minute
+}
+
+// RESTDiscovery declares the endpoint listing the markets of a REST exchange.
+// Markets is the dot separated path of the market array in the response, and
+// Symbol, Base and Quote the paths of those fields within a market.
+type RESTDiscovery struct {
+	URL     string `json:"url"`
+	Markets string `json:"markets"`
+	Symbol  string `json:"symbol"`
+	Base    string `json:"base"`
+	Quote   string `json:"quote"`
+}
+
+// RESTExchange declares an exchange whose candles are served as JSON by a
+// REST endpoint.
+//
+// URL and the values of Params may contain the placeholders {symbol},
+// {interval}, {start}, {start_ms}, {end}, {end_ms} and {limit}. Candles is the
+// dot separated path of the candle array in the response, empty when the
+// response is the array. Fields maps time, open, high, low, close and volume
+// to their path within a candle, using indices for candles that are arrays.
+// Discovery optionally declares where new markets of the exchange are found.
+type RESTExchange struct {
+	Name          string            `json:"name"`
+	WebsiteURL    string            `json:"website"`
+	URL           string            `json:"url"`
+	Params        map[string]string `json:"params"`
+	Markets       []Market          `json:"markets"`
+	VolumeSide    string            `json:"volume_side"`
+	Short         RESTInterval      `json:"short"`
+	Long          RESTInterval      `json:"long"`
+	Historic      RESTInterval      `json:"historic"`
+	Candles       string            `json:"candles"`
+	Fields        map[string]string `json:"fields"`
+	TimeFormat    string            `json:"time_format"`
+	Pagination    string            `json:"pagination"`
+	Limit         int               `json:"limit"`
+	HistoricStart int64             `json:"historic_start"`
+	Discovery     *RESTDiscovery    `json:"discovery"`
+}
+
+// LoadRESTExchanges reads a JSON array of exchange definitions from a file
+func LoadRESTExchanges(path string) ([]RESTExchange, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var defs []RESTExchange
+	if err = json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("Unable to decode exchange definitions in %s: %v", path, err)
+	}
+	return defs, nil
+}
+
+func (def *RESTExchange) validate() error {
+	if def.Name == "" {
+		return fmt.Errorf("Exchange definition without a name")
+	}
+	if def.URL == "" {
+		return fmt.Errorf("%s: missing url", def.Name)
+	}
+	if len(def.Markets) == 0 {
+		return fmt.Errorf("%s: no markets", def.Name)
+	}
+	for _, market := range def.Markets {
+		if market.Base == "" || market.Quote == "" || market.Symbol == "" {
+			return fmt.Errorf("%s: markets need a base, quote and symbol", def.Name)
+		}
+	}
+	if _, err := ParseVolumeSide(def.VolumeSide); err != nil {
+		return fmt.Errorf("%s: %v", def.Name, err)
+	}
+	for kind, interval := range map[string]RESTInterval{
+		IntervalShort: def.Short, IntervalLong: def.Long, IntervalHistoric: def.Historic} {
+		if interval.Minutes <= 0 || interval.Name == "" {
+			return fmt.Errorf("%s: the %s interval needs minutes and a name", def.Name, kind)
+		}
+	}
+	for _, field := range restFields {
+		if _, ok := def.Fields[field]; !ok {
+			return fmt.Errorf("%s: no path for the %s field", def.Name, field)
+		}
+	}
+	if def.Discovery != nil && (def.Discovery.URL == "" || def.Discovery.Symbol == "" ||
+		def.Discovery.Base == "" || def.Discovery.Quote == "") {
+		return fmt.Errorf("%s: market discovery needs a url and symbol, base and quote paths", def.Name)
+	}
+	switch def.Pagination {
+	case PaginationNone:
+	case PaginationStart:
+		if def.Limit <= 0 {
+			return fmt.Errorf("%s: start pagination needs a limit", def.Name)
+		}
+	default:
+		return fmt.Errorf("%s: unknown pagination %q, expected %s or %s", def.Name, def.Pagination,
+			PaginationNone, PaginationStart)
+	}
+	return nil
+}
+
+func (def *RESTExchange) intervalName(interval time.Duration) string {
+	for _, i := range []RESTInterval{def.Short, def.Long, def.Historic} {
+		if i.duration() == interval {
+			return i.Name
+		}
+	}
+	return ""
+}
+
+func (def *RESTExchange) requester(last time.Time, interval time.Duration, symbol string) (string, error) {
+	end := time.Now()
+	if def.Limit > 0 {
+		if limitEnd := last.Add(time.Duration(def.Limit) * interval); limitEnd.Before(end) {
+			end = limitEnd
+		}
+	}
+	toMillis := func(t time.Time) string {
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	}
+	replacer := strings.NewReplacer(
+		"{symbol}", symbol,
+		"{interval}", def.intervalName(interval),
+		"{start}", strconv.FormatInt(last.Unix(), 10),
+		"{start_ms}", toMillis(last),
+		"{end}", strconv.FormatInt(end.Unix(), 10),
+		"{end_ms}", toMillis(end),
+		"{limit}", strconv.Itoa(def.Limit),
+	)
+
+	base := replacer.Replace(def.URL)
+	if len(def.Params) == 0 {
+		return base, nil
+	}
+	params := make(map[string]interface{}, len(def.Params))
+	for param, value := range def.Params {
+		params[param] = url.QueryEscape(replacer.Replace(value))
+	}
+	return helpers.AddParams(base, params)
+}
+
+func (def *RESTExchange) parseCandle(candle interface{}) (Tick, error) {
+	values := make(map[string]string, len(restFields))
+	for _, field := range restFields {
+		value, ok := jsonPath(candle, def.Fields[field])
+		if !ok {
+			return Tick{}, fmt.Errorf("missing %s", field)
+		}
+		values[field] = jsonString(value)
+	}
+
+	format := def.TimeFormat
+	if format == "" {
+		format = helpers.TimeUnix
+	}
+	t, err := helpers.ParseTime(values["time"], format)
+	if err != nil {
+		return Tick{}, err
+	}
+	return newTick(t, values["open"], values["high"], values["low"], values["close"], values["volume"])
+}
+
+// jsonPath follows a dot separated path of object keys and array indices
+func jsonPath(value interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return value, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func jsonString(value interface{}) string {
+	switch v := value.(type) {
+	case json.Number:
+		return v.String()
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// restAPIResponse decodes a response of a REST exchange, keeping numbers in
+// their published form
+type restAPIResponse struct {
+	exchange *RESTExchange
+	body     interface{}
+}
+
+func (resp *restAPIResponse) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	resp.body = nil
+	return decoder.Decode(&resp.body)
+}
+
+func (resp *restAPIResponse) toTicks(start int64) []Tick {
+	candles, _ := jsonPath(resp.body, resp.exchange.Candles)
+	res, _ := candles.([]interface{})
+	dataTicks := make([]Tick, 0, len(res))
+	for _, candle := range res {
+		tick, err := resp.exchange.parseCandle(candle)
+		if err != nil || tick.Time.Unix() < start {
+			continue
+		}
+		dataTicks = append(dataTicks, tick)
+	}
+	sort.Slice(dataTicks, func(i, j int) bool {
+		return dataTicks[i].Time.Before(dataTicks[j].Time)
+	})
+	return dataTicks
+}
+
+// restMarketsResponse decodes the market list of a REST exchange
+type restMarketsResponse struct {
+	restAPIResponse
+}
+
+func (resp *restMarketsResponse) toMarkets() []Market {
+	discovery := resp.exchange.Discovery
+	list, _ := jsonPath(resp.body, discovery.Markets)
+	res, _ := list.([]interface{})
+	markets := make([]Market, 0, len(res))
+	for _, item := range res {
+		var market Market
+		for _, field := range []struct {
+			path  string
+			value *string
+		}{{discovery.Symbol, &market.Symbol}, {discovery.Base, &market.Base}, {discovery.Quote, &market.Quote}} {
+			if value, ok := jsonPath(item, field.path); ok {
+				*field.value = jsonString(value)
+			}
+		}
+		if market.Symbol != "" && market.Base != "" && market.Quote != "" {
+			markets = append(markets, market)
+		}
+	}
+	return markets
+}
+
+// RegisterRESTExchange adds a collector constructor for every market of the
+// exchange to CollectorConstructors and returns their names. The BTC/DCR
+// market is collected under the exchange name, other markets under the name
+// followed by their quote asset, e.g. bittrexusd.
+func RegisterRESTExchange(def RESTExchange) ([]string, error) {
+	if err := def.validate(); err != nil {
+		return nil, err
+	}
+	side, _ := ParseVolumeSide(def.VolumeSide)
+
+	markets := make(map[string]Market, len(def.Markets))
+	for _, market := range def.Markets {
+		markets[market.Pair()] = market
+	}
+	exchange := ExchangeData{
+		Name:             def.Name,
+		WebsiteURL:       def.WebsiteURL,
+		apiLimited:       def.Pagination == PaginationStart,
+		markets:          markets,
+		volumeSide:       side,
+		ShortInterval:    def.Short.duration(),
+		LongInterval:     def.Long.duration(),
+		HistoricInterval: def.Historic.duration(),
+		requester:        def.requester,
+	}
+	var historicStart time.Time
+	if def.HistoricStart > 0 {
+		historicStart = time.Unix(def.HistoricStart, 0)
+	}
+
+	names := make([]string, len(def.Markets))
+	seen := make(map[string]bool, len(def.Markets))
+	for i, market := range def.Markets {
+		names[i] = def.Name
+		if market.Pair() != btcdcrPair {
+			names[i] += strings.ToLower(market.Quote)
+		}
+		if seen[names[i]] {
+			return nil, fmt.Errorf("%s: two markets would be collected as %s", def.Name, names[i])
+		}
+		seen[names[i]] = true
+		if _, exists := CollectorConstructors[names[i]]; exists {
+			return nil, fmt.Errorf("An exchange collector named %s already exists", names[i])
+		}
+	}
+
+	for i, market := range def.Markets {
+		pair := market.Pair()
+		CollectorConstructors[names[i]] = func(ctx context.Context, store Store) (Collector, error) {
+			return newCollector(ctx, store, exchange, pair, historicStart, &restAPIResponse{exchange: &def})
+		}
+	}
+	exchanges = append(exchanges, &exchange)
+
+	source := discoverySource{newTickResp: func() tickable { return &restAPIResponse{exchange: &def} }}
+	if def.Discovery != nil {
+		source.url = def.Discovery.URL
+		source.markets = func() marketLister {
+			return &restMarketsResponse{restAPIResponse{exchange: &def}}
+		}
+	}
+	discoverySources[def.Name] = source
+	return names, nil
+}
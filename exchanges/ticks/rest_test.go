@@ -0,0 +1,357 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticks
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/helpers"
+	"github.com/raedahgroup/dcrextdata/internal/fixtures"
+)
+
+func testRESTExchange() RESTExchange {
+	return RESTExchange{
+		Name:       "resttest",
+		URL:        "https://api.example.com/candles/{symbol}/{interval}",
+		Markets:    []Market{{Base: "DCR", Quote: "BTC", Symbol: "DCR-BTC"}},
+		VolumeSide: "base",
+		Short:      RESTInterval{Minutes: 5, Name: "5m"},
+		Long:       RESTInterval{Minutes: 60, Name: "1h"},
+		Historic:   RESTInterval{Minutes: 1440, Name: "1d"},
+		Candles:    "data.candles",
+		Fields: map[string]string{
+			"time": "t", "open": "o", "high": "h", "low": "l", "close": "c", "volume": "v"},
+		TimeFormat: time.RFC3339,
+		Pagination: PaginationNone,
+	}
+}
+
+func TestRESTExchangeValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(def *RESTExchange)
+		err    string
+	}{
+		{"valid", func(def *RESTExchange) {}, ""},
+		{"no name", func(def *RESTExchange) { def.Name = "" }, "without a name"},
+		{"no url", func(def *RESTExchange) { def.URL = "" }, "missing url"},
+		{"no markets", func(def *RESTExchange) { def.Markets = nil }, "no markets"},
+		{"market without symbol", func(def *RESTExchange) {
+			def.Markets = []Market{{Base: "DCR", Quote: "BTC"}}
+		}, "markets need a base, quote and symbol"},
+		{"volume side", func(def *RESTExchange) { def.VolumeSide = "both" }, "Unknown volume side"},
+		{"interval without name", func(def *RESTExchange) { def.Long.Name = "" }, "long interval"},
+		{"interval without minutes", func(def *RESTExchange) { def.Historic.Minutes = 0 }, "historic interval"},
+		{"missing field", func(def *RESTExchange) { delete(def.Fields, "volume") }, "volume field"},
+		{"discovery without paths", func(def *RESTExchange) {
+			def.Discovery = &RESTDiscovery{URL: "https://api.example.com/markets", Symbol: "s"}
+		}, "market discovery needs"},
+		{"start pagination without limit", func(def *RESTExchange) { def.Pagination = PaginationStart }, "needs a limit"},
+		{"unknown pagination", func(def *RESTExchange) { def.Pagination = "cursor" }, "unknown pagination"},
+	}
+
+	for _, test := range tests {
+		def := testRESTExchange()
+		test.modify(&def)
+		err := def.validate()
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", test.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: got error %v, expected %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestRESTExchangeRequester(t *testing.T) {
+	last := time.Unix(1556668200, 0)
+	tests := []struct {
+		name     string
+		url      string
+		params   map[string]string
+		limit    int
+		interval time.Duration
+		path     string
+		query    map[string]string
+	}{
+		{
+			name:     "url placeholders",
+			url:      "https://api.example.com/candles/{symbol}/{interval}",
+			interval: time.Hour,
+			path:     "/candles/DCR-BTC/1h",
+		},
+		{
+			name: "param placeholders",
+			url:  "https://api.example.com/candles",
+			params: map[string]string{
+				"market": "{symbol}", "period": "{interval}", "from": "{start}", "from_ms": "{start_ms}",
+				"to": "{end}", "to_ms": "{end_ms}", "size": "{limit}",
+			},
+			limit:    100,
+			interval: fiveMin,
+			path:     "/candles",
+			query: map[string]string{
+				"market": "DCR-BTC", "period": "5m", "from": "1556668200", "from_ms": "1556668200000",
+				"to": "1556698200", "to_ms": "1556698200000", "size": "100",
+			},
+		},
+		{
+			name:     "escaped params",
+			url:      "https://api.example.com/candles",
+			params:   map[string]string{"pair": "{symbol}/x y"},
+			interval: oneDay,
+			path:     "/candles",
+			query:    map[string]string{"pair": "DCR-BTC/x y"},
+		},
+	}
+
+	for _, test := range tests {
+		def := testRESTExchange()
+		def.URL = test.url
+		def.Params = test.params
+		def.Limit = test.limit
+		requestURL, err := def.requester(last, test.interval, "DCR-BTC")
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		u, err := url.Parse(requestURL)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if u.Path != test.path {
+			t.Errorf("%s: path %s, expected %s", test.name, u.Path, test.path)
+		}
+		query := u.Query()
+		for param, want := range test.query {
+			if got := query.Get(param); got != want {
+				t.Errorf("%s: %s=%s, expected %s", test.name, param, got, want)
+			}
+		}
+	}
+}
+
+func TestRESTExchangeRequesterEndsNow(t *testing.T) {
+	def := testRESTExchange()
+	def.URL = "https://api.example.com/candles?to={end}"
+	def.Limit = 1000
+	before := time.Now().Unix()
+	requestURL, err := def.requester(time.Now().Add(-time.Hour), fiveMin, "DCR-BTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	end, err := helpers.ParseTime(u.Query().Get("to"), helpers.TimeUnix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if end.Unix() < before || end.Unix() > time.Now().Unix() {
+		t.Errorf("end %d, expected the present when the limit reaches past it", end.Unix())
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(`{"data": {"list": [{"t": 1}, [2, "x"]]}, "ok": true}`), &value); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path  string
+		found bool
+		want  string
+	}{
+		{"ok", true, "true"},
+		{"data.list.0.t", true, "1"},
+		{"data.list.1.1", true, "x"},
+		{"data.list.2", false, ""},
+		{"data.list.-1", false, ""},
+		{"data.list.first", false, ""},
+		{"data.missing", false, ""},
+		{"ok.value", false, ""},
+	}
+	for _, test := range tests {
+		got, found := jsonPath(value, test.path)
+		if found != test.found {
+			t.Errorf("%s: found %v, expected %v", test.path, found, test.found)
+			continue
+		}
+		if found && jsonString(got) != test.want {
+			t.Errorf("%s: got %s, expected %s", test.path, jsonString(got), test.want)
+		}
+	}
+	if got, found := jsonPath(value, ""); !found || got == nil {
+		t.Errorf("the empty path should resolve to the value itself")
+	}
+}
+
+func TestRESTExchangeParseCandle(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		time   string
+		want   int64
+		err    bool
+	}{
+		{"default", "", "1556668500", 1556668500, false},
+		{"unix", helpers.TimeUnix, "1556668500", 1556668500, false},
+		{"unixms", helpers.TimeUnixMs, "1556668500000", 1556668500, false},
+		{"layout", time.RFC3339, "2019-05-01T00:35:00Z", 1556670900, false},
+		{"custom layout", "2006-01-02 15:04", "2019-05-01 00:35", 1556670900, false},
+		{"bad unix", helpers.TimeUnix, "yesterday", 0, true},
+		{"bad layout", time.RFC3339, "1556668500", 0, true},
+	}
+
+	for _, test := range tests {
+		def := testRESTExchange()
+		def.TimeFormat = test.format
+		candle := map[string]interface{}{
+			"t": test.time, "o": "1", "h": "2", "l": "0.5", "c": "1.5", "v": "10"}
+		tick, err := def.parseCandle(candle)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error for %s", test.name, test.time)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		checkTick(t, test.name, tick, fixtureTick{test.want, "1", "2", "0.5", "1.5", "10"})
+	}
+
+	def := testRESTExchange()
+	if _, err := def.parseCandle(map[string]interface{}{"t": "2019-05-01T00:35:00Z"}); err == nil ||
+		!strings.Contains(err.Error(), "missing open") {
+		t.Errorf("got error %v for a candle without prices, expected missing open", err)
+	}
+}
+
+func TestRESTExchangeFixtures(t *testing.T) {
+	arrayDef := testRESTExchange()
+	arrayDef.Candles = ""
+	arrayDef.Fields = map[string]string{
+		"time": "0", "open": "1", "high": "2", "low": "3", "close": "4", "volume": "5"}
+	arrayDef.TimeFormat = helpers.TimeUnixMs
+
+	tests := []struct {
+		fixture string
+		def     RESTExchange
+		start   int64
+		ticks   []fixtureTick
+	}{
+		{
+			// The unparseable candles are skipped and the rest ordered by time
+			fixture: "rest_object.json",
+			def:     testRESTExchange(),
+			ticks: []fixtureTick{
+				{1556669100, "0.00269900", "0.00270200", "0.00269800", "0.00270100", "87.1"},
+				{1556669400, "0.00270100", "0.00271000", "0.00269500", "0.00270800", "152.33"},
+			},
+		},
+		{
+			fixture: "rest_array.json",
+			def:     arrayDef,
+			ticks: []fixtureTick{
+				{1556668800, "0.0027", "0.0027", "0.0026985", "0.002699", "12.5"},
+				{1556669100, "0.002699", "0.002702", "0.002698", "0.002701", "87.1"},
+				{1556669400, "0.002708", "0.00271", "0.002695", "0.0027011", "152.33"},
+			},
+		},
+		{
+			// Candles before the start are left out
+			fixture: "rest_array.json",
+			def:     arrayDef,
+			start:   1556669100,
+			ticks: []fixtureTick{
+				{1556669100, "0.002699", "0.002702", "0.002698", "0.002701", "87.1"},
+				{1556669400, "0.002708", "0.00271", "0.002695", "0.0027011", "152.33"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		def := test.def
+		resp := &restAPIResponse{exchange: &def}
+		fixtures.Load(t, test.fixture, resp)
+		ticks := resp.toTicks(test.start)
+		if len(ticks) != len(test.ticks) {
+			t.Errorf("%s: %d ticks, expected %d", test.fixture, len(ticks), len(test.ticks))
+			continue
+		}
+		for i, want := range test.ticks {
+			checkTick(t, test.fixture, ticks[i], want)
+		}
+	}
+}
+
+func TestRegisterRESTExchange(t *testing.T) {
+	tests := []struct {
+		name    string
+		markets []Market
+		names   []string
+		err     string
+	}{
+		{
+			name: "resttestvalid",
+			markets: []Market{
+				{Base: "DCR", Quote: "BTC", Symbol: "DCR-BTC"},
+				{Base: "DCR", Quote: "USDT", Symbol: "DCR-USDT"},
+			},
+			names: []string{"resttestvalid", "resttestvalidusdt"},
+		},
+		{
+			name: "resttestduplicate",
+			markets: []Market{
+				{Base: "DCR", Quote: "USDT", Symbol: "DCR-USDT"},
+				{Base: "DCR", Quote: "usdt", Symbol: "dcr_usdt"},
+			},
+			err: "two markets would be collected as resttestduplicateusdt",
+		},
+	}
+
+	for _, test := range tests {
+		def := testRESTExchange()
+		def.Name = test.name
+		def.Markets = test.markets
+		names, err := RegisterRESTExchange(def)
+		for _, name := range names {
+			delete(CollectorConstructors, name)
+		}
+		delete(discoverySources, test.name)
+		for i, exchange := range exchanges {
+			if exchange.Name == test.name {
+				exchanges = append(exchanges[:i], exchanges[i+1:]...)
+				break
+			}
+		}
+
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: got error %v, expected %q", test.name, err, test.err)
+			}
+			if _, exists := CollectorConstructors[test.name+"usdt"]; exists {
+				t.Errorf("%s: a collector was registered for the rejected definition", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if strings.Join(names, ",") != strings.Join(test.names, ",") {
+			t.Errorf("%s: registered %v, expected %v", test.name, names, test.names)
+		}
+	}
+}
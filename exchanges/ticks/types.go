@@ -6,71 +6,119 @@ package ticks
 
 import (
 	"context"
-	"strconv"
+	"encoding/json"
+	"fmt"
 	"time"
+
+	"github.com/ericlagergren/decimal"
+	"github.com/raedahgroup/dcrextdata/helpers"
 )
 
 type Collector interface {
 	GetShort(context.Context) error
 	GetLong(context.Context) error
 	GetHistoric(context.Context) error
+	// Exchange returns the name of the exchange the collector collects from
+	Exchange() string
+	// CurrencyPair returns the QUOTE/BASE pair of the market collected
+	CurrencyPair() string
 }
 
 type Store interface {
-	RegisterExchange(ctx context.Context, exchange ExchangeData) (lastShort, lastLong, lastHistoric time.Time, err error)
-	StoreExchangeTicks(ctx context.Context, exchange string, interval int, pair string, data []Tick) (time.Time, error)
+	RegisterExchange(ctx context.Context, exchange ExchangeData, pair string) (lastShort, lastLong, lastHistoric time.Time, err error)
+	// StoreExchangeTicks stores the ticks the store does not hold yet and
+	// returns the time of the last tick and the number of ticks inserted
+	StoreExchangeTicks(ctx context.Context, exchange string, interval int, pair string, data []Tick) (time.Time, int, error)
 }
 
 type urlRequester func(time.Time, time.Duration, string) (string, error)
 
+// periodEnder returns the end of the period a request for the candles after
+// last covers, zero when the request is for the latest candles
+type periodEnder func(last time.Time, interval time.Duration) time.Time
+
 type ExchangeData struct {
 	Name             string
 	WebsiteURL       string
 	apiLimited       bool
-	availableCPairs  map[string]string
+	pageLimit        int64
+	markets          map[string]Market
+	volumeSide       VolumeSide
 	ShortInterval    time.Duration
 	LongInterval     time.Duration
 	HistoricInterval time.Duration
 	requester        urlRequester
+	periodEnd        periodEnder
 }
 
 type tickable interface {
 	toTicks(int64) []Tick
 }
 
-// Tick represents an exchange data tick
+// Tick represents an exchange data tick. Prices and volumes keep the exact
+// digits published by the exchange. Volume is the volume as reported by the
+// exchange, BaseVolume and QuoteVolume are denominated in the base and quote
+// asset of the market.
 type Tick struct {
-	High   float64
-	Low    float64
-	Open   float64
-	Close  float64
-	Volume float64
-	Time   time.Time
+	High        *decimal.Big
+	Low         *decimal.Big
+	Open        *decimal.Big
+	Close       *decimal.Big
+	Volume      *decimal.Big
+	BaseVolume  *decimal.Big
+	QuoteVolume *decimal.Big
+	Time        time.Time
 }
 
-// TickDto represents an exchange data, formatted for presentation
+// newTick parses the exchange's string representation of a candle
+func newTick(t time.Time, open, high, low, close, volume string) (Tick, error) {
+	tick := Tick{Time: t.UTC()}
+	values := []struct {
+		dest  **decimal.Big
+		value string
+	}{
+		{&tick.Open, open},
+		{&tick.High, high},
+		{&tick.Low, low},
+		{&tick.Close, close},
+		{&tick.Volume, volume},
+	}
+	for _, v := range values {
+		d, err := helpers.ParseDecimal(v.value)
+		if err != nil {
+			return tick, err
+		}
+		*v.dest = d
+	}
+	return tick, nil
+}
+
+// TickDto represents an exchange data, formatted for presentation. Prices and
+// volumes are encoded as JSON strings to keep their precision.
 type TickDto struct {
-	ExchangeID   int       `json:"exchange_id"`
-	ExchangeName string    `json:"exchange_name"`
-	High         float64   `json:"high"`
-	Low          float64   `json:"low"`
-	Open         float64   `json:"open"`
-	Close        float64   `json:"close"`
-	Volume       float64   `json:"volume"`
-	Time         time.Time `json:"time"`
-	Interval     int       `json:"interval"`
-	CurrencyPair string    `json:"currency_pair"`
+	ExchangeID   int          `json:"exchange_id"`
+	ExchangeName string       `json:"exchange_name"`
+	High         *decimal.Big `json:"high"`
+	Low          *decimal.Big `json:"low"`
+	Open         *decimal.Big `json:"open"`
+	Close        *decimal.Big `json:"close"`
+	Volume       *decimal.Big `json:"volume"`
+	BaseVolume   *decimal.Big `json:"base_volume"`
+	QuoteVolume  *decimal.Big `json:"quote_volume"`
+	Time         time.Time    `json:"time"`
+	Interval     int          `json:"interval"`
+	CurrencyPair string       `json:"currency_pair"`
 }
 
 type poloniexAPIResponse []poloniexDataTick
 
 type poloniexDataTick struct {
-	High   float64 `json:"high"`
-	Low    float64 `json:"low"`
-	Open   float64 `json:"open"`
-	Close  float64 `json:"close"`
-	Volume float64 `json:"volume"`
-	Time   int64   `json:"date"`
+	High   json.Number `json:"high"`
+	Low    json.Number `json:"low"`
+	Open   json.Number `json:"open"`
+	Close  json.Number `json:"close"`
+	Volume json.Number `json:"volume"`
+	Time   int64       `json:"date"`
 }
 
 func (resp poloniexAPIResponse) toTicks(start int64) []Tick {
@@ -80,58 +128,58 @@ func (resp poloniexAPIResponse) toTicks(start int64) []Tick {
 		if v.Time < start {
 			continue
 		}
-		dataTicks = append(dataTicks, Tick{
-			High:   v.High,
-			Low:    v.Low,
-			Open:   v.Open,
-			Close:  v.Close,
-			Volume: v.Volume,
-			Time:   time.Unix(v.Time, 0).UTC(),
-		})
+		tick, err := newTick(time.Unix(v.Time, 0), v.Open.String(), v.High.String(), v.Low.String(),
+			v.Close.String(), v.Volume.String())
+		if err != nil {
+			continue
+		}
+		dataTicks = append(dataTicks, tick)
 	}
 	return dataTicks
 }
 
 type bittrexDataTick struct {
-	High   float64 `json:"H"`
-	Low    float64 `json:"L"`
-	Open   float64 `json:"O"`
-	Close  float64 `json:"C"`
-	Volume float64 `json:"BV"`
-	Time   string  `json:"T"`
+	Time        string      `json:"startsAt"`
+	Open        json.Number `json:"open"`
+	High        json.Number `json:"high"`
+	Low         json.Number `json:"low"`
+	Close       json.Number `json:"close"`
+	Volume      json.Number `json:"volume"`
+	QuoteVolume json.Number `json:"quoteVolume"`
 }
 
-type bittrexAPIResponse struct {
-	Result []bittrexDataTick `json:"result"`
-}
+type bittrexAPIResponse []bittrexDataTick
 
 func (resp bittrexAPIResponse) toTicks(start int64) []Tick {
-	bTicks := resp.Result
+	bTicks := []bittrexDataTick(resp)
 	dataTicks := make([]Tick, 0, len(bTicks))
 	for _, v := range bTicks {
-		t, err := time.Parse("2006-01-02T15:04:05", v.Time)
+		t, err := time.Parse(time.RFC3339, v.Time)
 		if err != nil || t.Unix() < start {
 			continue
 		}
-		dataTicks = append(dataTicks, Tick{
-			High:   v.High,
-			Low:    v.Low,
-			Open:   v.Open,
-			Close:  v.Close,
-			Volume: v.Volume,
-			Time:   t.UTC(),
-		})
+		// Volume stays in the quote asset, as reported by the retired v2 API
+		tick, err := newTick(t, v.Open.String(), v.High.String(), v.Low.String(), v.Close.String(),
+			v.QuoteVolume.String())
+		if err != nil {
+			continue
+		}
+		if tick.BaseVolume, err = helpers.ParseDecimal(v.Volume.String()); err != nil {
+			continue
+		}
+		tick.QuoteVolume = tick.Volume
+		dataTicks = append(dataTicks, tick)
 	}
 	return dataTicks
 }
 
 type bleutradeDataTick struct {
-	High   float64 `json:"High"`
-	Low    float64 `json:"Low"`
-	Open   float64 `json:"Open"`
-	Close  float64 `json:"Close"`
-	Volume float64 `json:"Volume"`
-	Time   string  `json:"TimeStamp"`
+	High   json.Number `json:"High"`
+	Low    json.Number `json:"Low"`
+	Open   json.Number `json:"Open"`
+	Close  json.Number `json:"Close"`
+	Volume json.Number `json:"Volume"`
+	Time   string      `json:"TimeStamp"`
 }
 
 type bleutradeAPIResponse struct {
@@ -147,14 +195,11 @@ func (resp bleutradeAPIResponse) toTicks(start int64) []Tick {
 		if err != nil || t.Unix() < start {
 			continue
 		}
-		dataTicks = append(dataTicks, Tick{
-			High:   v.High,
-			Low:    v.Low,
-			Open:   v.Open,
-			Close:  v.Close,
-			Volume: v.Volume,
-			Time:   t.UTC(),
-		})
+		tick, err := newTick(t, v.Open.String(), v.High.String(), v.Low.String(), v.Close.String(), v.Volume.String())
+		if err != nil {
+			continue
+		}
+		dataTicks = append(dataTicks, tick)
 	}
 	return dataTicks
 }
@@ -166,43 +211,188 @@ func (resp binanceAPIResponse) toTicks(start int64) []Tick {
 	res := []binanceDataTick(resp)
 	dataTicks := make([]Tick, 0, len(res))
 	for _, j := range res {
+		if len(j) < 6 {
+			continue
+		}
+		ms, ok := j[0].(float64)
+		if !ok {
+			continue
+		}
 		// Converting unix time from milliseconds to seconds
-		secs := int64(j[0].(float64) / 1000)
-		t := time.Unix(secs, 0)
-
+		secs := int64(ms / 1000)
 		if secs < start {
 			continue
 		}
 
-		high, err := strconv.ParseFloat(j[2].(string), 64)
-		if err != nil {
+		// Prices and volume are published as strings
+		values := make([]string, 5)
+		for i := range values {
+			values[i], ok = j[i+1].(string)
+			if !ok {
+				break
+			}
+		}
+		if !ok {
 			continue
 		}
-		low, err := strconv.ParseFloat(j[3].(string), 64)
+
+		tick, err := newTick(time.Unix(secs, 0), values[0], values[1], values[2], values[3], values[4])
 		if err != nil {
 			continue
 		}
-		open, err := strconv.ParseFloat(j[1].(string), 64)
+		dataTicks = append(dataTicks, tick)
+	}
+	return dataTicks
+}
+
+const (
+	okexTimeFormat        = "2006-01-02T15:04:05.000Z"
+	upbitTimeFormat       = "2006-01-02T15:04:05Z"
+	upbitCandleTimeFormat = "2006-01-02T15:04:05"
+
+	kucoinSuccess = "200000"
+)
+
+// failable is implemented by responses that report request errors in their
+// body rather than with an HTTP status
+type failable interface {
+	err() error
+}
+
+type huobiDataTick struct {
+	Time   int64       `json:"id"`
+	Open   json.Number `json:"open"`
+	High   json.Number `json:"high"`
+	Low    json.Number `json:"low"`
+	Close  json.Number `json:"close"`
+	Volume json.Number `json:"amount"`
+}
+
+type huobiAPIResponse struct {
+	Status string          `json:"status"`
+	Data   []huobiDataTick `json:"data"`
+	Error  string          `json:"err-msg"`
+}
+
+func (resp huobiAPIResponse) err() error {
+	if resp.Status != "ok" {
+		return fmt.Errorf("request failed with status %s: %s", resp.Status, resp.Error)
+	}
+	return nil
+}
+
+func (resp huobiAPIResponse) toTicks(start int64) []Tick {
+	res := resp.Data
+	dataTicks := make([]Tick, 0, len(res))
+	// Candles are listed newest first
+	for i := len(res) - 1; i >= 0; i-- {
+		v := res[i]
+		if v.Time < start {
+			continue
+		}
+		tick, err := newTick(time.Unix(v.Time, 0), v.Open.String(), v.High.String(), v.Low.String(),
+			v.Close.String(), v.Volume.String())
 		if err != nil {
 			continue
 		}
-		close, err := strconv.ParseFloat(j[4].(string), 64)
+		dataTicks = append(dataTicks, tick)
+	}
+	return dataTicks
+}
+
+// kucoinDataTick is a candle of time, open, close, high, low, volume and
+// turnover, all published as strings
+type kucoinDataTick []string
+
+type kucoinAPIResponse struct {
+	Code string           `json:"code"`
+	Data []kucoinDataTick `json:"data"`
+	Msg  string           `json:"msg"`
+}
+
+func (resp kucoinAPIResponse) err() error {
+	if resp.Code != kucoinSuccess {
+		return fmt.Errorf("request failed with code %s: %s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+func (resp kucoinAPIResponse) toTicks(start int64) []Tick {
+	res := resp.Data
+	dataTicks := make([]Tick, 0, len(res))
+	// Candles are listed newest first
+	for i := len(res) - 1; i >= 0; i-- {
+		v := res[i]
+		if len(v) < 6 {
+			continue
+		}
+		t, err := helpers.ParseTime(v[0], helpers.TimeUnix)
+		if err != nil || t.Unix() < start {
+			continue
+		}
+		tick, err := newTick(t, v[1], v[3], v[4], v[2], v[5])
 		if err != nil {
 			continue
 		}
-		volume, err := strconv.ParseFloat(j[5].(string), 64)
+		dataTicks = append(dataTicks, tick)
+	}
+	return dataTicks
+}
+
+// okexDataTick is a candle of time, open, high, low, close and volume, all
+// published as strings
+type okexDataTick []string
+
+type okexAPIResponse []okexDataTick
+
+func (resp okexAPIResponse) toTicks(start int64) []Tick {
+	res := []okexDataTick(resp)
+	dataTicks := make([]Tick, 0, len(res))
+	// Candles are listed newest first
+	for i := len(res) - 1; i >= 0; i-- {
+		v := res[i]
+		if len(v) < 6 {
+			continue
+		}
+		t, err := time.Parse(okexTimeFormat, v[0])
+		if err != nil || t.Unix() < start {
+			continue
+		}
+		tick, err := newTick(t, v[1], v[2], v[3], v[4], v[5])
 		if err != nil {
 			continue
 		}
+		dataTicks = append(dataTicks, tick)
+	}
+	return dataTicks
+}
 
-		dataTicks = append(dataTicks, Tick{
-			High:   high,
-			Low:    low,
-			Open:   open,
-			Close:  close,
-			Volume: volume,
-			Time:   t.UTC(),
-		})
+type upbitDataTick struct {
+	Time   string      `json:"candle_date_time_utc"`
+	Open   json.Number `json:"opening_price"`
+	High   json.Number `json:"high_price"`
+	Low    json.Number `json:"low_price"`
+	Close  json.Number `json:"trade_price"`
+	Volume json.Number `json:"candle_acc_trade_volume"`
+}
+
+type upbitAPIResponse []upbitDataTick
+
+func (resp upbitAPIResponse) toTicks(start int64) []Tick {
+	res := []upbitDataTick(resp)
+	dataTicks := make([]Tick, 0, len(res))
+	// Candles are listed newest first
+	for i := len(res) - 1; i >= 0; i-- {
+		v := res[i]
+		t, err := time.Parse(upbitCandleTimeFormat, v.Time)
+		if err != nil || t.Unix() < start {
+			continue
+		}
+		tick, err := newTick(t, v.Open.String(), v.High.String(), v.Low.String(), v.Close.String(), v.Volume.String())
+		if err != nil {
+			continue
+		}
+		dataTicks = append(dataTicks, tick)
 	}
 	return dataTicks
 }
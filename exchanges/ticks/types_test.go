@@ -0,0 +1,191 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticks
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/internal/fixtures"
+)
+
+type fixtureTick struct {
+	time                           int64
+	open, high, low, close, volume string
+}
+
+func checkTick(t *testing.T, name string, got Tick, want fixtureTick) {
+	t.Helper()
+	if got.Time.Unix() != want.time {
+		t.Errorf("%s: time %d, expected %d", name, got.Time.Unix(), want.time)
+	}
+	for _, field := range []struct {
+		name, got, want string
+	}{
+		{"open", got.Open.String(), want.open},
+		{"high", got.High.String(), want.high},
+		{"low", got.Low.String(), want.low},
+		{"close", got.Close.String(), want.close},
+		{"volume", got.Volume.String(), want.volume},
+	} {
+		if field.got != field.want {
+			t.Errorf("%s: %s %s, expected %s", name, field.name, field.got, field.want)
+		}
+	}
+}
+
+func TestExchangeFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		resp    tickable
+		first   fixtureTick
+		last    fixtureTick
+	}{
+		{
+			fixture: "bittrex.json",
+			resp:    new(bittrexAPIResponse),
+			first:   fixtureTick{1556668200, "0.00448100", "0.00448300", "0.00447500", "0.00447700", "0.18452191"},
+			last:    fixtureTick{1556668800, "0.00447200", "0.00447400", "0.00446600", "0.00446700", "0.05381560"},
+		},
+		{
+			fixture: "huobi.json",
+			resp:    new(huobiAPIResponse),
+			first:   fixtureTick{1556668200, "0.004480", "0.004482", "0.004474", "0.004475", "12.5"},
+			last:    fixtureTick{1556668800, "0.004471", "0.004473", "0.004465", "0.004468", "21.8641"},
+		},
+		{
+			fixture: "kucoin.json",
+			resp:    new(kucoinAPIResponse),
+			first:   fixtureTick{1556668200, "0.004478", "0.004479", "0.004471", "0.004473", "7.31"},
+			last:    fixtureTick{1556668800, "0.004469", "0.004471", "0.004462", "0.004466", "48.2931"},
+		},
+		{
+			fixture: "okex.json",
+			resp:    new(okexAPIResponse),
+			first:   fixtureTick{1556668200, "0.004481", "0.004483", "0.004475", "0.004477", "3.05"},
+			last:    fixtureTick{1556668800, "0.004472", "0.004474", "0.004466", "0.004467", "61.429"},
+		},
+		{
+			fixture: "upbit.json",
+			resp:    new(upbitAPIResponse),
+			first:   fixtureTick{1556668200, "0.004479", "0.00448", "0.004474", "0.004476", "2"},
+			last:    fixtureTick{1556668800, "0.00447", "0.004475", "0.004465", "0.004468", "20.05848301"},
+		},
+	}
+
+	for _, test := range tests {
+		fixtures.Load(t, test.fixture, test.resp)
+		if resp, ok := test.resp.(failable); ok {
+			if err := resp.err(); err != nil {
+				t.Errorf("%s: unexpected error: %v", test.fixture, err)
+			}
+		}
+
+		ticks := test.resp.toTicks(0)
+		if len(ticks) != 3 {
+			t.Fatalf("%s: got %d ticks, expected 3", test.fixture, len(ticks))
+		}
+		for i := 1; i < len(ticks); i++ {
+			if !ticks[i-1].Time.Before(ticks[i].Time) {
+				t.Errorf("%s: ticks are not in ascending order", test.fixture)
+			}
+		}
+		checkTick(t, test.fixture, ticks[0], test.first)
+		checkTick(t, test.fixture, ticks[len(ticks)-1], test.last)
+
+		// Candles before the start are dropped
+		ticks = test.resp.toTicks(test.last.time)
+		if len(ticks) != 1 || ticks[0].Time.Unix() != test.last.time {
+			t.Errorf("%s: got %d ticks from the last candle, expected 1", test.fixture, len(ticks))
+		}
+	}
+}
+
+func TestExchangeFixtureErrors(t *testing.T) {
+	tests := []struct {
+		fixture string
+		resp    failable
+		msg     string
+	}{
+		{"huobi_error.json", new(huobiAPIResponse), "invalid symbol"},
+		{"kucoin_error.json", new(kucoinAPIResponse), "Too Many Requests"},
+	}
+	for _, test := range tests {
+		fixtures.Load(t, test.fixture, test.resp)
+		err := test.resp.err()
+		if err == nil || !strings.Contains(err.Error(), test.msg) {
+			t.Errorf("%s: got error %v, expected %q", test.fixture, err, test.msg)
+		}
+	}
+}
+
+func TestExchangeRequests(t *testing.T) {
+	last := time.Unix(1556668200, 0)
+	tests := []struct {
+		exchange ExchangeData
+		interval time.Duration
+		path     string
+		params   map[string]string
+	}{
+		{bittrexData, fiveMin, "/v3/markets/DCR-BTC/candles/MINUTE_5/historical/2019/4/30", nil},
+		{bittrexData, time.Hour, "/v3/markets/DCR-BTC/candles/HOUR_1/historical/2019/5", nil},
+		{bittrexData, oneDay, "/v3/markets/DCR-BTC/candles/DAY_1/historical/2019", nil},
+		{huobiData, fiveMin, "/market/history/kline", map[string]string{
+			"symbol": "dcrbtc", "period": "5min", "size": "2000"}},
+		{kucoinData, time.Hour, "/api/v1/market/candles", map[string]string{
+			"symbol": "DCR-BTC", "type": "1hour", "startAt": "1556668200", "endAt": "1562068200"}},
+		{okexData, oneDay, "/api/spot/v3/instruments/DCR-BTC/candles", map[string]string{
+			"granularity": "86400", "start": "2019-04-30T23:49:59.000Z", "end": "2019-11-16T23:49:59.000Z"}},
+		{upbitData, fiveMin, "/v1/candles/minutes/5", map[string]string{
+			"market": "BTC-DCR", "count": "200", "to": "2019-05-01T16:30:00Z"}},
+	}
+
+	for _, test := range tests {
+		symbol := test.exchange.markets[btcdcrPair].Symbol
+		requestURL, err := test.exchange.requester(last, test.interval, symbol)
+		if err != nil {
+			t.Fatalf("%s: %v", test.exchange.Name, err)
+		}
+		u, err := url.Parse(requestURL)
+		if err != nil {
+			t.Fatalf("%s: %v", test.exchange.Name, err)
+		}
+		if u.Path != test.path {
+			t.Errorf("%s: path %s, expected %s", test.exchange.Name, u.Path, test.path)
+		}
+		query := u.Query()
+		for param, want := range test.params {
+			if got := query.Get(param); got != want {
+				t.Errorf("%s: %s=%s, expected %s", test.exchange.Name, param, got, want)
+			}
+		}
+	}
+}
+
+func TestBittrexRecentRequests(t *testing.T) {
+	last := time.Now().Add(-2 * time.Hour)
+	requestURL, err := bittrexData.requester(last, fiveMin, "DCR-BTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://api.bittrex.com/v3/markets/DCR-BTC/candles/MINUTE_5/recent"; requestURL != want {
+		t.Errorf("got %s, expected %s", requestURL, want)
+	}
+}
+
+func TestBittrexVolumes(t *testing.T) {
+	var resp bittrexAPIResponse
+	fixtures.Load(t, "bittrex.json", &resp)
+	ticks := resp.toTicks(0)
+	DeriveVolumes(ticks, bittrexData.volumeSide)
+	if got := ticks[0].BaseVolume.String(); got != "41.20383215" {
+		t.Errorf("base volume %s, expected the reported 41.20383215", got)
+	}
+	if got := ticks[0].QuoteVolume.String(); got != "0.18452191" {
+		t.Errorf("quote volume %s, expected the reported 0.18452191", got)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package transfers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Collector checks the DCR deposit and withdrawal status of the exchanges
+// and stores the changes
+type Collector struct {
+	sources []Source
+	store   Store
+}
+
+func NewCollector(disabledExchanges []string, store Store) (*Collector, error) {
+	disabledMap := make(map[string]struct{})
+	for _, exchange := range disabledExchanges {
+		disabledMap[exchange] = struct{}{}
+	}
+
+	names := make([]string, 0, len(SourceConstructors))
+	for name := range SourceConstructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	client := &http.Client{Timeout: time.Minute}
+	sources := make([]Source, 0, len(names))
+	for _, name := range names {
+		if _, disabled := disabledMap[name]; disabled {
+			continue
+		}
+		source, err := SourceConstructors[name](client)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("No transfer status sources")
+	}
+
+	return &Collector{
+		sources: sources,
+		store:   store,
+	}, nil
+}
+
+func (c *Collector) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for _, source := range c.sources {
+		if ctx.Err() != nil {
+			return
+		}
+		status, err := source.Fetch(ctx)
+		if err != nil {
+			log.Errorf("Unable to check the %s transfer status on %s: %v", Asset, source.Name(), err)
+			continue
+		}
+		changed, err := c.store.StoreTransferStatus(ctx, status)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		if changed {
+			log.Infof("%s %s deposits %s, withdrawals %s", source.Name(), Asset,
+				enabledString(status.Deposit), enabledString(status.Withdrawal))
+		}
+	}
+}
+
+func enabledString(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
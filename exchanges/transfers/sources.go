@@ -0,0 +1,156 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package transfers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+	"github.com/raedahgroup/dcrextdata/helpers"
+)
+
+const (
+	poloniexURL = "https://poloniex.com/public?command=returnCurrencies"
+	bittrexURL  = "https://api.bittrex.com/v3/currencies/DCR"
+	binanceURL  = "https://www.binance.com/assetWithdraw/getAllAsset.html"
+	huobiURL    = "https://api.huobi.pro/v2/reference/currencies?currency=dcr"
+	kucoinURL   = "https://api.kucoin.com/api/v1/currencies/DCR"
+)
+
+var (
+	nilClientError = errors.New("Cannot use a nil http client.")
+
+	errNoAsset = fmt.Errorf("%s is not listed", Asset)
+)
+
+var SourceConstructors = map[string]func(*http.Client) (Source, error){
+	ticks.Poloniex: NewPoloniex,
+	ticks.Bittrex:  NewBittrex,
+	ticks.Binance:  NewBinance,
+	ticks.Huobi:    NewHuobi,
+	ticks.Kucoin:   NewKucoin,
+}
+
+// Source checks whether DCR deposits and withdrawals are enabled on an
+// exchange
+type Source interface {
+	Fetch(ctx context.Context) (Status, error)
+	Name() string
+}
+
+type commonSource struct {
+	client  *http.Client
+	baseUrl string
+}
+
+func newCommonSource(client *http.Client, baseUrl string) (commonSource, error) {
+	if client == nil {
+		return commonSource{}, nilClientError
+	}
+	return commonSource{client: client, baseUrl: baseUrl}, nil
+}
+
+type PoloniexSource struct {
+	commonSource
+}
+
+func NewPoloniex(client *http.Client) (Source, error) {
+	common, err := newCommonSource(client, poloniexURL)
+	return &PoloniexSource{common}, err
+}
+
+// Fetch reports both transfers as disabled while the DCR wallet is disabled
+// or frozen, Poloniex does not tell them apart
+func (src *PoloniexSource) Fetch(ctx context.Context) (Status, error) {
+	res := make(poloniexAPIResponse)
+	if err := helpers.GetResponse(ctx, src.client, src.baseUrl, &res); err != nil {
+		return Status{}, err
+	}
+	return res.toStatus()
+}
+
+func (*PoloniexSource) Name() string { return ticks.Poloniex }
+
+type BittrexSource struct {
+	commonSource
+}
+
+func NewBittrex(client *http.Client) (Source, error) {
+	common, err := newCommonSource(client, bittrexURL)
+	return &BittrexSource{common}, err
+}
+
+// Fetch reports both transfers as disabled while the DCR wallet is offline
+func (src *BittrexSource) Fetch(ctx context.Context) (Status, error) {
+	res := new(bittrexAPIResponse)
+	if err := helpers.GetResponse(ctx, src.client, src.baseUrl, res); err != nil {
+		return Status{}, err
+	}
+	return res.toStatus()
+}
+
+func (*BittrexSource) Name() string { return ticks.Bittrex }
+
+type BinanceSource struct {
+	commonSource
+}
+
+func NewBinance(client *http.Client) (Source, error) {
+	common, err := newCommonSource(client, binanceURL)
+	return &BinanceSource{common}, err
+}
+
+func (src *BinanceSource) Fetch(ctx context.Context) (Status, error) {
+	var res binanceAPIResponse
+	if err := helpers.GetResponse(ctx, src.client, src.baseUrl, &res); err != nil {
+		return Status{}, err
+	}
+	return res.toStatus()
+}
+
+func (*BinanceSource) Name() string { return ticks.Binance }
+
+type HuobiSource struct {
+	commonSource
+}
+
+func NewHuobi(client *http.Client) (Source, error) {
+	common, err := newCommonSource(client, huobiURL)
+	return &HuobiSource{common}, err
+}
+
+// Fetch reports a transfer as enabled when it is allowed on any chain of the
+// currency
+func (src *HuobiSource) Fetch(ctx context.Context) (Status, error) {
+	res := new(huobiAPIResponse)
+	if err := helpers.GetResponse(ctx, src.client, src.baseUrl, res); err != nil {
+		return Status{}, err
+	}
+	return res.toStatus()
+}
+
+func (*HuobiSource) Name() string { return ticks.Huobi }
+
+type KucoinSource struct {
+	commonSource
+}
+
+func NewKucoin(client *http.Client) (Source, error) {
+	common, err := newCommonSource(client, kucoinURL)
+	return &KucoinSource{common}, err
+}
+
+func (src *KucoinSource) Fetch(ctx context.Context) (Status, error) {
+	res := new(kucoinAPIResponse)
+	if err := helpers.GetResponse(ctx, src.client, src.baseUrl, res); err != nil {
+		return Status{}, err
+	}
+	return res.toStatus()
+}
+
+func (*KucoinSource) Name() string { return ticks.Kucoin }
@@ -0,0 +1,147 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package transfers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+)
+
+// Asset is the currency whose transfers are monitored
+const Asset = "DCR"
+
+// Status is whether DCR can be deposited to and withdrawn from Exchange, as
+// checked at Time
+type Status struct {
+	Exchange   string
+	Time       time.Time
+	Deposit    bool
+	Withdrawal bool
+}
+
+// StatusDto is a transfer status of an exchange, in effect from Since
+type StatusDto struct {
+	Exchange   string    `json:"exchange"`
+	Since      time.Time `json:"since"`
+	Deposit    bool      `json:"deposit"`
+	Withdrawal bool      `json:"withdrawal"`
+}
+
+type Store interface {
+	// StoreTransferStatus stores status if it differs from the last stored
+	// status of the exchange and returns whether it did
+	StoreTransferStatus(ctx context.Context, status Status) (bool, error)
+}
+
+type poloniexCurrency struct {
+	Disabled int `json:"disabled"`
+	Delisted int `json:"delisted"`
+	Frozen   int `json:"frozen"`
+}
+
+type poloniexAPIResponse map[string]poloniexCurrency
+
+func (resp poloniexAPIResponse) toStatus() (Status, error) {
+	currency, ok := resp[Asset]
+	if !ok {
+		return Status{}, errNoAsset
+	}
+	enabled := currency.Disabled == 0 && currency.Delisted == 0 && currency.Frozen == 0
+	return Status{Exchange: ticks.Poloniex, Time: time.Now(), Deposit: enabled, Withdrawal: enabled}, nil
+}
+
+type bittrexAPIResponse struct {
+	Symbol string `json:"symbol"`
+	Status string `json:"status"`
+}
+
+func (resp *bittrexAPIResponse) toStatus() (Status, error) {
+	if resp.Symbol != Asset {
+		return Status{}, errNoAsset
+	}
+	enabled := resp.Status == "ONLINE"
+	return Status{Exchange: ticks.Bittrex, Time: time.Now(), Deposit: enabled, Withdrawal: enabled}, nil
+}
+
+type binanceAsset struct {
+	AssetCode      string `json:"assetCode"`
+	EnableWithdraw bool   `json:"enableWithdraw"`
+	EnableCharge   bool   `json:"enableCharge"`
+}
+
+type binanceAPIResponse []binanceAsset
+
+func (resp binanceAPIResponse) toStatus() (Status, error) {
+	for _, asset := range resp {
+		if asset.AssetCode == Asset {
+			return Status{Exchange: ticks.Binance, Time: time.Now(), Deposit: asset.EnableCharge,
+				Withdrawal: asset.EnableWithdraw}, nil
+		}
+	}
+	return Status{}, errNoAsset
+}
+
+type huobiChain struct {
+	Chain          string `json:"chain"`
+	DepositStatus  string `json:"depositStatus"`
+	WithdrawStatus string `json:"withdrawStatus"`
+}
+
+type huobiAPIResponse struct {
+	Code int `json:"code"`
+	Data []struct {
+		Currency   string       `json:"currency"`
+		Chains     []huobiChain `json:"chains"`
+		InstStatus string       `json:"instStatus"`
+	} `json:"data"`
+	Message string `json:"message"`
+}
+
+func (resp *huobiAPIResponse) toStatus() (Status, error) {
+	if resp.Code != http.StatusOK {
+		return Status{}, fmt.Errorf("request failed with code %d: %s", resp.Code, resp.Message)
+	}
+	for _, currency := range resp.Data {
+		if !strings.EqualFold(currency.Currency, Asset) {
+			continue
+		}
+		status := Status{Exchange: ticks.Huobi, Time: time.Now()}
+		if currency.InstStatus != "normal" {
+			return status, nil
+		}
+		for _, chain := range currency.Chains {
+			status.Deposit = status.Deposit || chain.DepositStatus == "allowed"
+			status.Withdrawal = status.Withdrawal || chain.WithdrawStatus == "allowed"
+		}
+		return status, nil
+	}
+	return Status{}, errNoAsset
+}
+
+type kucoinAPIResponse struct {
+	Code string `json:"code"`
+	Data struct {
+		Currency          string `json:"currency"`
+		IsWithdrawEnabled bool   `json:"isWithdrawEnabled"`
+		IsDepositEnabled  bool   `json:"isDepositEnabled"`
+	} `json:"data"`
+	Msg string `json:"msg"`
+}
+
+func (resp *kucoinAPIResponse) toStatus() (Status, error) {
+	if resp.Code != "200000" {
+		return Status{}, fmt.Errorf("request failed with code %s: %s", resp.Code, resp.Msg)
+	}
+	if resp.Data.Currency != Asset {
+		return Status{}, errNoAsset
+	}
+	return Status{Exchange: ticks.Kucoin, Time: time.Now(), Deposit: resp.Data.IsDepositEnabled,
+		Withdrawal: resp.Data.IsWithdrawEnabled}, nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package transfers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+	"github.com/raedahgroup/dcrextdata/internal/fixtures"
+)
+
+type statusable interface {
+	toStatus() (Status, error)
+}
+
+func TestStatusFixtures(t *testing.T) {
+	tests := []struct {
+		fixture    string
+		resp       statusable
+		exchange   string
+		deposit    bool
+		withdrawal bool
+		err        string
+	}{
+		{"poloniex.json", new(poloniexAPIResponse), ticks.Poloniex, true, true, ""},
+		{"poloniex_frozen.json", new(poloniexAPIResponse), ticks.Poloniex, false, false, ""},
+		{"poloniex_no_asset.json", new(poloniexAPIResponse), "", false, false, "DCR is not listed"},
+		{"bittrex.json", new(bittrexAPIResponse), ticks.Bittrex, true, true, ""},
+		{"bittrex_offline.json", new(bittrexAPIResponse), ticks.Bittrex, false, false, ""},
+		{"bittrex_no_asset.json", new(bittrexAPIResponse), "", false, false, "DCR is not listed"},
+		{"binance.json", new(binanceAPIResponse), ticks.Binance, true, false, ""},
+		{"binance_no_asset.json", new(binanceAPIResponse), "", false, false, "DCR is not listed"},
+		{"huobi.json", new(huobiAPIResponse), ticks.Huobi, true, false, ""},
+		{"huobi_delisted.json", new(huobiAPIResponse), ticks.Huobi, false, false, ""},
+		{"huobi_no_asset.json", new(huobiAPIResponse), "", false, false, "DCR is not listed"},
+		{"huobi_error.json", new(huobiAPIResponse), "", false, false, "code 40000: invalid currency"},
+		{"kucoin.json", new(kucoinAPIResponse), ticks.Kucoin, true, true, ""},
+		{"kucoin_no_asset.json", new(kucoinAPIResponse), "", false, false, "DCR is not listed"},
+		{"kucoin_error.json", new(kucoinAPIResponse), "", false, false, "code 900003: Currency not exists"},
+	}
+
+	for _, test := range tests {
+		fixtures.Load(t, test.fixture, test.resp)
+		status, err := test.resp.toStatus()
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: got error %v, expected %q", test.fixture, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.fixture, err)
+			continue
+		}
+		if status.Exchange != test.exchange {
+			t.Errorf("%s: exchange %s, expected %s", test.fixture, status.Exchange, test.exchange)
+		}
+		if status.Time.IsZero() {
+			t.Errorf("%s: no status time", test.fixture)
+		}
+		if status.Deposit != test.deposit || status.Withdrawal != test.withdrawal {
+			t.Errorf("%s: deposit %v withdrawal %v, expected %v and %v", test.fixture, status.Deposit,
+				status.Withdrawal, test.deposit, test.withdrawal)
+		}
+	}
+}
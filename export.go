@@ -0,0 +1,75 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/raedahgroup/dcrextdata/export"
+	"github.com/raedahgroup/dcrextdata/helpers"
+	"github.com/raedahgroup/dcrextdata/postgres"
+)
+
+// exportData streams the data set selected in opts to the output file
+func exportData(ctx context.Context, db *postgres.PgDb, opts ExportOptions) error {
+	from, err := helpers.ParseDate(opts.From)
+	if err != nil {
+		return err
+	}
+	to, err := helpers.ParseDate(opts.To)
+	if err != nil {
+		return err
+	}
+	filter := export.Filter{
+		From:     from,
+		To:       to,
+		Sources:  opts.Sources,
+		Interval: opts.Interval,
+	}
+	if err = filter.Validate(opts.Data); err != nil {
+		return err
+	}
+
+	var prototype export.Record
+	var run func(context.Context, export.Filter, func(export.Record) error) error
+	switch opts.Data {
+	case export.ExchangeTicks:
+		prototype, run = export.ExchangeTick{}, db.ExportExchangeTicks
+	case export.VSPTicks:
+		prototype, run = export.VSPTick{}, db.ExportVSPTicks
+	case export.PowData:
+		prototype, run = export.Pow{}, db.ExportPowData
+	default:
+		return fmt.Errorf("Unknown data set %q", opts.Data)
+	}
+
+	file, err := os.Create(opts.Out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer, err := export.NewWriter(opts.Format, file, prototype)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	err = run(ctx, filter, func(record export.Record) error {
+		count++
+		return writer.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	log.Infof("Exported %d %s rows as %s", count, opts.Data, opts.Format)
+	return nil
+}
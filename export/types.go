@@ -0,0 +1,232 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ericlagergren/decimal"
+	"github.com/raedahgroup/dcrextdata/helpers"
+)
+
+const (
+	ExchangeTicks = "exchange"
+	VSPTicks      = "vsp"
+	PowData       = "pow"
+)
+
+// Filter selects the rows of an export. Zero values do not filter. Interval
+// only applies to exchange ticks.
+type Filter struct {
+	From     time.Time
+	To       time.Time
+	Sources  []string
+	Interval int
+}
+
+// Validate checks that the filter applies to the data set
+func (f Filter) Validate(data string) error {
+	if f.Interval != 0 && data != ExchangeTicks {
+		return fmt.Errorf("The interval filter only applies to %s data, not %s", ExchangeTicks, data)
+	}
+	return nil
+}
+
+// Record is a single exported row
+type Record interface {
+	header() []string
+	values() []string
+	parquetRow() interface{}
+}
+
+// ExchangeTick is an exchange tick joined with its exchange name
+type ExchangeTick struct {
+	Exchange     string       `json:"exchange"`
+	CurrencyPair string       `json:"currency_pair"`
+	Interval     int          `json:"interval"`
+	Time         time.Time    `json:"time"`
+	Open         *decimal.Big `json:"open"`
+	High         *decimal.Big `json:"high"`
+	Low          *decimal.Big `json:"low"`
+	Close        *decimal.Big `json:"close"`
+	Volume       *decimal.Big `json:"volume"`
+	BaseVolume   *decimal.Big `json:"base_volume"`
+	QuoteVolume  *decimal.Big `json:"quote_volume"`
+}
+
+func (ExchangeTick) header() []string {
+	return []string{"exchange", "currency_pair", "interval", "time", "open", "high", "low", "close", "volume",
+		"base_volume", "quote_volume"}
+}
+
+func (t ExchangeTick) values() []string {
+	return []string{t.Exchange, t.CurrencyPair, strconv.Itoa(t.Interval), formatTime(t.Time),
+		formatDecimal(t.Open), formatDecimal(t.High), formatDecimal(t.Low), formatDecimal(t.Close),
+		formatDecimal(t.Volume), formatDecimal(t.BaseVolume), formatDecimal(t.QuoteVolume)}
+}
+
+// exchangeTickRow is the parquet row of an exchange tick. Prices and volumes
+// are written as strings so that they keep their exact stored values.
+type exchangeTickRow struct {
+	Exchange     string  `parquet:"name=exchange, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	CurrencyPair string  `parquet:"name=currency_pair, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	Interval     int32   `parquet:"name=interval, type=INT32"`
+	Time         int64   `parquet:"name=time, type=TIMESTAMP_MILLIS"`
+	Open         *string `parquet:"name=open, type=UTF8, repetitiontype=OPTIONAL"`
+	High         *string `parquet:"name=high, type=UTF8, repetitiontype=OPTIONAL"`
+	Low          *string `parquet:"name=low, type=UTF8, repetitiontype=OPTIONAL"`
+	Close        *string `parquet:"name=close, type=UTF8, repetitiontype=OPTIONAL"`
+	Volume       *string `parquet:"name=volume, type=UTF8, repetitiontype=OPTIONAL"`
+	BaseVolume   *string `parquet:"name=base_volume, type=UTF8, repetitiontype=OPTIONAL"`
+	QuoteVolume  *string `parquet:"name=quote_volume, type=UTF8, repetitiontype=OPTIONAL"`
+}
+
+func (t ExchangeTick) parquetRow() interface{} {
+	return &exchangeTickRow{
+		Exchange:     t.Exchange,
+		CurrencyPair: t.CurrencyPair,
+		Interval:     int32(t.Interval),
+		Time:         toMillis(t.Time),
+		Open:         optionalDecimal(t.Open),
+		High:         optionalDecimal(t.High),
+		Low:          optionalDecimal(t.Low),
+		Close:        optionalDecimal(t.Close),
+		Volume:       optionalDecimal(t.Volume),
+		BaseVolume:   optionalDecimal(t.BaseVolume),
+		QuoteVolume:  optionalDecimal(t.QuoteVolume),
+	}
+}
+
+// VSPTick is a vsp tick joined with its vsp name
+type VSPTick struct {
+	VSP              string    `json:"vsp"`
+	Time             time.Time `json:"time"`
+	Immature         int       `json:"immature"`
+	Live             int       `json:"live"`
+	Voted            int       `json:"voted"`
+	Missed           int       `json:"missed"`
+	PoolFees         float64   `json:"pool_fees"`
+	ProportionLive   float64   `json:"proportion_live"`
+	ProportionMissed float64   `json:"proportion_missed"`
+	UserCount        int       `json:"user_count"`
+	UsersActive      int       `json:"users_active"`
+}
+
+func (VSPTick) header() []string {
+	return []string{"vsp", "time", "immature", "live", "voted", "missed", "pool_fees", "proportion_live",
+		"proportion_missed", "user_count", "users_active"}
+}
+
+func (t VSPTick) values() []string {
+	return []string{t.VSP, formatTime(t.Time), strconv.Itoa(t.Immature), strconv.Itoa(t.Live),
+		strconv.Itoa(t.Voted), strconv.Itoa(t.Missed), formatFloat(t.PoolFees), formatFloat(t.ProportionLive),
+		formatFloat(t.ProportionMissed), strconv.Itoa(t.UserCount), strconv.Itoa(t.UsersActive)}
+}
+
+type vspTickRow struct {
+	VSP              string  `parquet:"name=vsp, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	Time             int64   `parquet:"name=time, type=TIMESTAMP_MILLIS"`
+	Immature         int32   `parquet:"name=immature, type=INT32"`
+	Live             int32   `parquet:"name=live, type=INT32"`
+	Voted            int32   `parquet:"name=voted, type=INT32"`
+	Missed           int32   `parquet:"name=missed, type=INT32"`
+	PoolFees         float64 `parquet:"name=pool_fees, type=DOUBLE"`
+	ProportionLive   float64 `parquet:"name=proportion_live, type=DOUBLE"`
+	ProportionMissed float64 `parquet:"name=proportion_missed, type=DOUBLE"`
+	UserCount        int32   `parquet:"name=user_count, type=INT32"`
+	UsersActive      int32   `parquet:"name=users_active, type=INT32"`
+}
+
+func (t VSPTick) parquetRow() interface{} {
+	return &vspTickRow{
+		VSP:              t.VSP,
+		Time:             toMillis(t.Time),
+		Immature:         int32(t.Immature),
+		Live:             int32(t.Live),
+		Voted:            int32(t.Voted),
+		Missed:           int32(t.Missed),
+		PoolFees:         t.PoolFees,
+		ProportionLive:   t.ProportionLive,
+		ProportionMissed: t.ProportionMissed,
+		UserCount:        int32(t.UserCount),
+		UsersActive:      int32(t.UsersActive),
+	}
+}
+
+// Pow is a row of PoW data
+type Pow struct {
+	Source            string       `json:"source"`
+	Time              time.Time    `json:"time"`
+	NetworkHashrate   float64      `json:"network_hashrate"`
+	PoolHashrate      float64      `json:"pool_hashrate"`
+	Workers           int64        `json:"workers"`
+	NetworkDifficulty float64      `json:"network_difficulty"`
+	CoinPrice         *decimal.Big `json:"coin_price"`
+	BtcPrice          *decimal.Big `json:"btc_price"`
+}
+
+func (Pow) header() []string {
+	return []string{"source", "time", "network_hashrate", "pool_hashrate", "workers", "network_difficulty",
+		"coin_price", "btc_price"}
+}
+
+func (p Pow) values() []string {
+	return []string{p.Source, formatTime(p.Time), formatFloat(p.NetworkHashrate), formatFloat(p.PoolHashrate),
+		strconv.FormatInt(p.Workers, 10), formatFloat(p.NetworkDifficulty), formatDecimal(p.CoinPrice),
+		formatDecimal(p.BtcPrice)}
+}
+
+// powRow is the parquet row of PoW data, with the prices written as strings
+// like those of exchange ticks
+type powRow struct {
+	Source            string  `parquet:"name=source, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	Time              int64   `parquet:"name=time, type=TIMESTAMP_MILLIS"`
+	NetworkHashrate   float64 `parquet:"name=network_hashrate, type=DOUBLE"`
+	PoolHashrate      float64 `parquet:"name=pool_hashrate, type=DOUBLE"`
+	Workers           int64   `parquet:"name=workers, type=INT64"`
+	NetworkDifficulty float64 `parquet:"name=network_difficulty, type=DOUBLE"`
+	CoinPrice         *string `parquet:"name=coin_price, type=UTF8, repetitiontype=OPTIONAL"`
+	BtcPrice          *string `parquet:"name=btc_price, type=UTF8, repetitiontype=OPTIONAL"`
+}
+
+func (p Pow) parquetRow() interface{} {
+	return &powRow{
+		Source:            p.Source,
+		Time:              toMillis(p.Time),
+		NetworkHashrate:   p.NetworkHashrate,
+		PoolHashrate:      p.PoolHashrate,
+		Workers:           p.Workers,
+		NetworkDifficulty: p.NetworkDifficulty,
+		CoinPrice:         optionalDecimal(p.CoinPrice),
+		BtcPrice:          optionalDecimal(p.BtcPrice),
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func formatDecimal(d *decimal.Big) string {
+	return helpers.DecimalString(d)
+}
+
+// optionalDecimal formats d like the CSV output, nil for a nil decimal
+func optionalDecimal(d *decimal.Big) *string {
+	if d == nil {
+		return nil
+	}
+	s := formatDecimal(d)
+	return &s
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	pqwriter "github.com/xitongsys/parquet-go-source/writer"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+
+	// parquetRowGroupSize bounds the rows buffered in memory before a row
+	// group is flushed to the output
+	parquetRowGroupSize = 16 * 1024 * 1024
+	parquetParallelism  = 4
+)
+
+// Writer writes records to an output as they are received. Close must be
+// called to flush the output.
+type Writer interface {
+	Write(Record) error
+	Close() error
+}
+
+// NewWriter creates a writer for the given format. prototype is a record of
+// the type that will be written and determines the header or schema.
+func NewWriter(format string, w io.Writer, prototype Record) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, prototype)
+	case FormatJSONL:
+		return newJSONLWriter(w), nil
+	case FormatParquet:
+		return newParquetWriter(w, prototype)
+	default:
+		return nil, fmt.Errorf("Unsupported export format %q", format)
+	}
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, prototype Record) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(prototype.header()); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: cw}, nil
+}
+
+func (w *csvWriter) Write(record Record) error {
+	return w.writer.Write(record.values())
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type jsonlWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	buffer := bufio.NewWriter(w)
+	return &jsonlWriter{
+		buffer:  buffer,
+		encoder: json.NewEncoder(buffer),
+	}
+}
+
+// Write encodes the record followed by a newline
+func (w *jsonlWriter) Write(record Record) error {
+	return w.encoder.Encode(record)
+}
+
+func (w *jsonlWriter) Close() error {
+	return w.buffer.Flush()
+}
+
+type parquetWriter struct {
+	writer *writer.ParquetWriter
+}
+
+func newParquetWriter(w io.Writer, prototype Record) (*parquetWriter, error) {
+	pw, err := writer.NewParquetWriter(pqwriter.NewWriterFile(w), prototype.parquetRow(), parquetParallelism)
+	if err != nil {
+		return nil, err
+	}
+	pw.RowGroupSize = parquetRowGroupSize
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+	return &parquetWriter{writer: pw}, nil
+}
+
+func (w *parquetWriter) Write(record Record) error {
+	return w.writer.Write(record.parquetRow())
+}
+
+// Close flushes the last row group and writes the file footer
+func (w *parquetWriter) Close() error {
+	return w.writer.WriteStop()
+}
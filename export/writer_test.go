@@ -0,0 +1,134 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ericlagergren/decimal"
+	"github.com/raedahgroup/dcrextdata/helpers"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+func testTicks(t *testing.T) []Record {
+	dec := func(s string) *decimal.Big {
+		d, err := helpers.ParseDecimal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	return []Record{
+		ExchangeTick{Exchange: "bittrex", CurrencyPair: "BTC/DCR", Interval: 60,
+			Time: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC), Open: dec("0.00251234567891"),
+			High: dec("0.0026"), Low: dec("0.0024"), Close: dec("0.0025"), Volume: dec("1234.123456789")},
+		ExchangeTick{Exchange: "binance", CurrencyPair: "BTC/DCR", Interval: 60,
+			Time: time.Date(2019, 6, 1, 1, 0, 0, 0, time.UTC), Open: dec("0.0025"),
+			High: dec("0.0026"), Low: dec("0.0024"), Close: dec("0.0025"), Volume: dec("10"),
+			BaseVolume: dec("10"), QuoteVolume: dec("0.025")},
+	}
+}
+
+func write(t *testing.T, format string, records []Record) []byte {
+	var out bytes.Buffer
+	w, err := NewWriter(format, &out, records[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range records {
+		if err = w.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	out := string(write(t, FormatCSV, testTicks(t)))
+	expected := "exchange,currency_pair,interval,time,open,high,low,close,volume,base_volume,quote_volume\n" +
+		"bittrex,BTC/DCR,60,2019-06-01T00:00:00Z,0.00251234567891,0.0026,0.0024,0.0025,1234.123456789,,\n" +
+		"binance,BTC/DCR,60,2019-06-01T01:00:00Z,0.0025,0.0026,0.0024,0.0025,10,10,0.025\n"
+	if out != expected {
+		t.Errorf("wrote\n%s\nexpected\n%s", out, expected)
+	}
+}
+
+func TestJSONLWriter(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(string(write(t, FormatJSONL, testTicks(t)))), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("wrote %d lines", len(lines))
+	}
+	var tick map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &tick); err != nil {
+		t.Fatal(err)
+	}
+	if tick["exchange"] != "bittrex" || tick["base_volume"] != nil {
+		t.Errorf("wrote %s", lines[0])
+	}
+}
+
+func TestParquetWriterKeepsDecimals(t *testing.T) {
+	file, err := buffer.NewBufferFile(write(t, FormatParquet, testTicks(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := reader.NewParquetReader(file, new(exchangeTickRow), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.ReadStop()
+	if pr.GetNumRows() != 2 {
+		t.Fatalf("wrote %d rows", pr.GetNumRows())
+	}
+	rows := make([]exchangeTickRow, 2)
+	if err = pr.Read(&rows); err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := rows[0], rows[1]
+	if first.Exchange != "bittrex" || first.Time != 1559347200000 || first.Interval != 60 {
+		t.Errorf("unexpected row %+v", first)
+	}
+	if first.Open == nil || *first.Open != "0.00251234567891" || *first.Volume != "1234.123456789" {
+		t.Errorf("prices not kept exactly: %v %v", first.Open, first.Volume)
+	}
+	if first.BaseVolume != nil || second.QuoteVolume == nil || *second.QuoteVolume != "0.025" {
+		t.Errorf("unexpected volumes %v %v", first.BaseVolume, second.QuoteVolume)
+	}
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	if _, err := NewWriter("xml", new(bytes.Buffer), Pow{}); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+func TestFilterValidate(t *testing.T) {
+	tests := []struct {
+		data     string
+		interval int
+		ok       bool
+	}{
+		{ExchangeTicks, 60, true},
+		{ExchangeTicks, 0, true},
+		{VSPTicks, 0, true},
+		{VSPTicks, 60, false},
+		{PowData, 5, false},
+	}
+	for _, test := range tests {
+		err := Filter{Interval: test.interval}.Validate(test.data)
+		if (err == nil) != test.ok {
+			t.Errorf("%s with interval %d: error %v", test.data, test.interval, err)
+		}
+	}
+}
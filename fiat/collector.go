@@ -0,0 +1,111 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package fiat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	availableSources = []string{
+		Coingecko,
+		Coinpaprika,
+		Blockchain,
+	}
+)
+
+type Collector struct {
+	sources    []Source
+	currencies []string
+	period     int64
+	store      RateStore
+}
+
+func NewCollector(disabledSources []string, currencies []string, period int64, store RateStore) (*Collector, error) {
+	if len(currencies) == 0 {
+		return nil, fmt.Errorf("No fiat currencies configured")
+	}
+	if period <= 0 {
+		return nil, fmt.Errorf("Fiat rate interval must be positive, got %d", period)
+	}
+
+	sources := make([]Source, 0, len(availableSources))
+	disabledMap := make(map[string]struct{})
+	for _, source := range disabledSources {
+		disabledMap[source] = struct{}{}
+	}
+
+	client := &http.Client{Timeout: time.Minute}
+	for _, name := range availableSources {
+		if _, disabled := disabledMap[name]; disabled {
+			continue
+		}
+		source, err := SourceConstructors[name](client)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("No fiat rate sources")
+	}
+
+	upper := make([]string, 0, len(currencies))
+	for _, currency := range currencies {
+		upper = append(upper, strings.ToUpper(currency))
+	}
+
+	return &Collector{
+		sources:    sources,
+		currencies: upper,
+		period:     period,
+		store:      store,
+	}, nil
+}
+
+// Run collects the rates every period seconds until ctx is cancelled
+func (fc *Collector) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	log.Infof("Collecting fiat rates in %v every %ds", fc.currencies, fc.period)
+
+	ticker := time.NewTicker(time.Duration(fc.period) * time.Second)
+	defer ticker.Stop()
+	for {
+		fc.collect(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (fc *Collector) collect(ctx context.Context) {
+	// All rates of a cycle share a timestamp so that rates from different
+	// sources can be averaged.
+	now := time.Now().UTC().Truncate(time.Minute)
+	for _, source := range fc.sources {
+		rates, err := source.Fetch(ctx, fc.currencies)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Errorf("Unable to fetch fiat rates from %s: %v", source.Name(), err)
+			}
+			continue
+		}
+		for i := range rates {
+			rates[i].Time = now
+		}
+		if err = fc.store.StoreFiatRates(ctx, rates); err != nil {
+			log.Error(err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package fiat
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryRateStore struct {
+	mtx   sync.Mutex
+	rates []Rate
+}
+
+func (s *memoryRateStore) StoreFiatRates(ctx context.Context, rates []Rate) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.rates = append(s.rates, rates...)
+	return nil
+}
+
+func (s *memoryRateStore) count() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.rates)
+}
+
+type staticSource struct{}
+
+func (staticSource) Fetch(ctx context.Context, currencies []string) ([]Rate, error) {
+	return []Rate{{Asset: BTC, Currency: currencies[0], Rate: 10000, Source: "static"}}, nil
+}
+
+func (staticSource) Name() string { return "static" }
+
+func TestNewCollectorValidation(t *testing.T) {
+	if _, err := NewCollector(nil, nil, 300, nil); err == nil {
+		t.Error("no currencies accepted")
+	}
+	if _, err := NewCollector(nil, []string{"USD"}, 0, nil); err == nil {
+		t.Error("zero interval accepted")
+	}
+	if _, err := NewCollector(availableSources, []string{"USD"}, 300, nil); err == nil {
+		t.Error("no sources accepted")
+	}
+}
+
+func TestCollectorRunsEveryPeriod(t *testing.T) {
+	store := new(memoryRateStore)
+	c := &Collector{sources: []Source{staticSource{}}, currencies: []string{"USD"}, period: 1, store: store}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	go c.Run(ctx, wg)
+
+	deadline := time.Now().Add(3 * time.Second)
+	for store.count() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	wg.Wait()
+
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+	if len(store.rates) < 2 {
+		t.Fatalf("collected %d times, expected at least 2", len(store.rates))
+	}
+	if first, second := store.rates[0].Time, store.rates[1].Time; !first.Equal(first.Truncate(time.Minute)) ||
+		second.Before(first) {
+		t.Errorf("unexpected times %v and %v", first, second)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package fiat
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// QuoteAsset returns the asset prices of the given currency pair are
+// denominated in, e.g. BTC for BTC/DCR and USD for USD/BTC
+func QuoteAsset(pair string) string {
+	return strings.ToUpper(strings.Split(pair, "/")[0])
+}
+
+// Converter converts BTC, DCR and fiat denominated values into a single fiat
+// currency using the collected rates
+type Converter struct {
+	query    RateQuery
+	currency string
+}
+
+func NewConverter(query RateQuery, currency string) *Converter {
+	return &Converter{
+		query:    query,
+		currency: strings.ToUpper(currency),
+	}
+}
+
+func (c *Converter) Currency() string {
+	return c.currency
+}
+
+// Rate returns the value of one unit of asset in the converter's currency at
+// the given time. Fiat assets are converted through their BTC rates.
+func (c *Converter) Rate(ctx context.Context, asset string, at time.Time) (float64, error) {
+	asset = strings.ToUpper(asset)
+	if asset == c.currency {
+		return 1, nil
+	}
+	if asset == BTC || asset == DCR {
+		return c.query.FiatRate(ctx, asset, c.currency, at)
+	}
+
+	from, err := c.query.FiatRate(ctx, BTC, asset, at)
+	if err != nil {
+		return 0, err
+	}
+	to, err := c.query.FiatRate(ctx, BTC, c.currency, at)
+	if err != nil {
+		return 0, err
+	}
+	if from == 0 {
+		return 0, ErrNoRate
+	}
+	return to / from, nil
+}
+
+// Convert converts value denominated in asset to the converter's currency
+func (c *Converter) Convert(ctx context.Context, value float64, asset string, at time.Time) (float64, error) {
+	rate, err := c.Rate(ctx, asset, at)
+	if err != nil {
+		return 0, err
+	}
+	return value * rate, nil
+}
+
+// ConvertPrice converts a price quoted on the given currency pair
+func (c *Converter) ConvertPrice(ctx context.Context, price float64, pair string, at time.Time) (float64, error) {
+	return c.Convert(ctx, price, QuoteAsset(pair), at)
+}
+
+// RateHistory answers rate queries from rates loaded beforehand, such as those
+// of the time range of a page, the same way the store does
+type RateHistory struct {
+	// rates are keyed by asset then currency, oldest first
+	rates map[string]map[string][]Rate
+}
+
+// NewRateHistory creates a history of the rates, of which there is at most
+// one per asset, currency and time
+func NewRateHistory(rates []Rate) *RateHistory {
+	h := &RateHistory{rates: make(map[string]map[string][]Rate)}
+	for _, rate := range rates {
+		asset, currency := strings.ToUpper(rate.Asset), strings.ToUpper(rate.Currency)
+		if h.rates[asset] == nil {
+			h.rates[asset] = make(map[string][]Rate)
+		}
+		h.rates[asset][currency] = append(h.rates[asset][currency], rate)
+	}
+	for _, currencies := range h.rates {
+		for _, series := range currencies {
+			sort.Slice(series, func(i, j int) bool {
+				return series[i].Time.Before(series[j].Time)
+			})
+		}
+	}
+	return h
+}
+
+// FiatRate returns the rate of asset in currency closest to but not after the
+// given time, or the earliest later rate if there is no such rate
+func (h *RateHistory) FiatRate(ctx context.Context, asset, currency string, at time.Time) (float64, error) {
+	series := h.rates[strings.ToUpper(asset)][strings.ToUpper(currency)]
+	i := sort.Search(len(series), func(i int) bool {
+		return series[i].Time.After(at)
+	})
+	if i > 0 {
+		return series[i-1].Rate, nil
+	}
+	if i < len(series) {
+		return series[i].Rate, nil
+	}
+	return 0, ErrNoRate
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package fiat
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+// memoryRates is a rate query of fixed rates, keyed by asset then currency
+type memoryRates map[string]map[string]float64
+
+func (m memoryRates) FiatRate(ctx context.Context, asset, currency string, at time.Time) (float64, error) {
+	rate, ok := m[asset][currency]
+	if !ok {
+		return 0, ErrNoRate
+	}
+	return rate, nil
+}
+
+func TestQuoteAsset(t *testing.T) {
+	tests := map[string]string{
+		"BTC/DCR":  BTC,
+		"btc/dcr":  BTC,
+		"USD/BTC":  "USD",
+		"usdt/dcr": "USDT",
+		"DCR":      DCR,
+	}
+	for pair, expected := range tests {
+		if asset := QuoteAsset(pair); asset != expected {
+			t.Errorf("%s: quote asset %s, expected %s", pair, asset, expected)
+		}
+	}
+}
+
+func TestConverterRate(t *testing.T) {
+	rates := memoryRates{
+		BTC: {"USD": 10000, "EUR": 8000, "JPY": 0},
+		DCR: {"USD": 25},
+	}
+	c := NewConverter(rates, "usd")
+	if c.Currency() != "USD" {
+		t.Errorf("currency %s", c.Currency())
+	}
+
+	tests := []struct {
+		asset    string
+		expected float64
+		err      error
+	}{
+		{"USD", 1, nil},
+		{"usd", 1, nil},
+		{BTC, 10000, nil},
+		{"dcr", 25, nil},
+		// Fiat currencies are converted through their BTC rates
+		{"EUR", 1.25, nil},
+		{"JPY", 0, ErrNoRate},
+		{"GBP", 0, ErrNoRate},
+	}
+	for _, test := range tests {
+		rate, err := c.Rate(context.Background(), test.asset, time.Now())
+		if err != test.err {
+			t.Errorf("%s: error %v, expected %v", test.asset, err, test.err)
+			continue
+		}
+		if math.Abs(rate-test.expected) > 1e-12 {
+			t.Errorf("%s: rate %f, expected %f", test.asset, rate, test.expected)
+		}
+	}
+
+	// The EUR rate is missing when converting to EUR from DCR
+	if _, err := NewConverter(rates, "EUR").Rate(context.Background(), DCR, time.Now()); err != ErrNoRate {
+		t.Errorf("DCR in EUR: error %v", err)
+	}
+}
+
+func TestConvertPrice(t *testing.T) {
+	c := NewConverter(memoryRates{BTC: {"USD": 10000}}, "USD")
+	price, err := c.ConvertPrice(context.Background(), 0.0025, "BTC/DCR", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(price-25) > 1e-9 {
+		t.Errorf("price %f, expected 25", price)
+	}
+}
+
+func TestRateHistory(t *testing.T) {
+	start := time.Unix(1560000000, 0)
+	history := NewRateHistory([]Rate{
+		{Time: start.Add(time.Hour), Asset: BTC, Currency: "USD", Rate: 10100},
+		{Time: start, Asset: BTC, Currency: "USD", Rate: 10000},
+		{Time: start, Asset: "dcr", Currency: "usd", Rate: 25},
+	})
+
+	tests := []struct {
+		name     string
+		asset    string
+		at       time.Time
+		expected float64
+		err      error
+	}{
+		{"earliest later rate", BTC, start.Add(-time.Minute), 10000, nil},
+		{"at a rate", BTC, start, 10000, nil},
+		{"closest rate before", BTC, start.Add(59 * time.Minute), 10000, nil},
+		{"latest rate", BTC, start.Add(24 * time.Hour), 10100, nil},
+		{"case insensitive", DCR, start, 25, nil},
+		{"unknown asset", "EUR", start, 0, ErrNoRate},
+	}
+	for _, test := range tests {
+		rate, err := history.FiatRate(context.Background(), test.asset, "USD", test.at)
+		if err != test.err || rate != test.expected {
+			t.Errorf("%s: rate %f and error %v, expected %f and %v", test.name, rate, err, test.expected, test.err)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package fiat
+
+import "errors"
+
+var (
+	nilClientError = errors.New("Cannot use a nil http client.")
+
+	// ErrNoRate is returned when no rate has been collected for an asset and currency
+	ErrNoRate = errors.New("fiat: no rate available")
+)
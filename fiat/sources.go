@@ -0,0 +1,204 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package fiat
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/helpers"
+)
+
+const (
+	Coingecko    = "coingecko"
+	CoingeckoUrl = "https://api.coingecko.com/api/v3/simple/price"
+
+	Coinpaprika    = "coinpaprika"
+	CoinpaprikaUrl = "https://api.coinpaprika.com/v1/tickers/"
+
+	Blockchain    = "blockchain"
+	BlockchainUrl = "https://blockchain.info/ticker"
+)
+
+var SourceConstructors = map[string]func(*http.Client) (Source, error){
+	Coingecko:   NewCoingecko,
+	Coinpaprika: NewCoinpaprika,
+	Blockchain:  NewBlockchain,
+}
+
+// Source fetches the current rates of the supported assets in the given
+// fiat currencies. Currencies a source does not quote are skipped.
+type Source interface {
+	Fetch(ctx context.Context, currencies []string) ([]Rate, error)
+	Name() string
+}
+
+type commonSource struct {
+	client  *http.Client
+	baseUrl string
+}
+
+type CoingeckoSource struct {
+	commonSource
+}
+
+var coingeckoIDs = map[string]string{
+	BTC: "bitcoin",
+	DCR: "decred",
+}
+
+func NewCoingecko(client *http.Client) (Source, error) {
+	if client == nil {
+		return nil, nilClientError
+	}
+	return &CoingeckoSource{
+		commonSource: commonSource{
+			client:  client,
+			baseUrl: CoingeckoUrl,
+		},
+	}, nil
+}
+
+func (src *CoingeckoSource) Fetch(ctx context.Context, currencies []string) ([]Rate, error) {
+	ids := make([]string, 0, len(coingeckoIDs))
+	for _, id := range coingeckoIDs {
+		ids = append(ids, id)
+	}
+	requestURL, err := helpers.AddParams(src.baseUrl, map[string]interface{}{
+		"ids":           strings.Join(ids, ","),
+		"vs_currencies": strings.ToLower(strings.Join(currencies, ",")),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(coingeckoAPIResponse)
+	if err = helpers.GetResponse(ctx, src.client, requestURL, &res); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rates := make([]Rate, 0, len(coingeckoIDs)*len(currencies))
+	for asset, id := range coingeckoIDs {
+		prices := res[id]
+		for _, currency := range currencies {
+			price, ok := prices[strings.ToLower(currency)]
+			if !ok {
+				continue
+			}
+			rates = append(rates, Rate{
+				Time:     now,
+				Asset:    asset,
+				Currency: currency,
+				Rate:     price,
+				Source:   Coingecko,
+			})
+		}
+	}
+	return rates, nil
+}
+
+func (*CoingeckoSource) Name() string { return Coingecko }
+
+type CoinpaprikaSource struct {
+	commonSource
+}
+
+var coinpaprikaIDs = map[string]string{
+	BTC: "btc-bitcoin",
+	DCR: "dcr-decred",
+}
+
+func NewCoinpaprika(client *http.Client) (Source, error) {
+	if client == nil {
+		return nil, nilClientError
+	}
+	return &CoinpaprikaSource{
+		commonSource: commonSource{
+			client:  client,
+			baseUrl: CoinpaprikaUrl,
+		},
+	}, nil
+}
+
+func (src *CoinpaprikaSource) Fetch(ctx context.Context, currencies []string) ([]Rate, error) {
+	now := time.Now()
+	rates := make([]Rate, 0, len(coinpaprikaIDs)*len(currencies))
+	for asset, id := range coinpaprikaIDs {
+		requestURL, err := helpers.AddParams(src.baseUrl+id, map[string]interface{}{
+			"quotes": strings.Join(currencies, ","),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		res := new(coinpaprikaAPIResponse)
+		if err = helpers.GetResponse(ctx, src.client, requestURL, res); err != nil {
+			return nil, err
+		}
+
+		for _, currency := range currencies {
+			quote, ok := res.Quotes[currency]
+			if !ok {
+				continue
+			}
+			rates = append(rates, Rate{
+				Time:     now,
+				Asset:    asset,
+				Currency: currency,
+				Rate:     quote.Price,
+				Source:   Coinpaprika,
+			})
+		}
+	}
+	return rates, nil
+}
+
+func (*CoinpaprikaSource) Name() string { return Coinpaprika }
+
+// BlockchainSource only provides BTC rates
+type BlockchainSource struct {
+	commonSource
+}
+
+func NewBlockchain(client *http.Client) (Source, error) {
+	if client == nil {
+		return nil, nilClientError
+	}
+	return &BlockchainSource{
+		commonSource: commonSource{
+			client:  client,
+			baseUrl: BlockchainUrl,
+		},
+	}, nil
+}
+
+func (src *BlockchainSource) Fetch(ctx context.Context, currencies []string) ([]Rate, error) {
+	res := make(blockchainAPIResponse)
+	if err := helpers.GetResponse(ctx, src.client, src.baseUrl, &res); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rates := make([]Rate, 0, len(currencies))
+	for _, currency := range currencies {
+		ticker, ok := res[currency]
+		if !ok {
+			continue
+		}
+		rates = append(rates, Rate{
+			Time:     now,
+			Asset:    BTC,
+			Currency: currency,
+			Rate:     ticker.Last,
+			Source:   Blockchain,
+		})
+	}
+	return rates, nil
+}
+
+func (*BlockchainSource) Name() string { return Blockchain }
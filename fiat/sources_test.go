@@ -0,0 +1,128 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package fiat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/raedahgroup/dcrextdata/internal/fixtures"
+)
+
+// fixtureSource returns a source of the given constructor that is served
+// the fixture file, and a function listing the paths it requested
+func fixtureSource(t *testing.T, constructor func(*http.Client) (Source, error), fixture string) (Source, func() []string) {
+	server, requested := fixtures.Server(t, map[string]string{"": fixture})
+	source, err := constructor(server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	switch s := source.(type) {
+	case *CoingeckoSource:
+		s.baseUrl = server.URL + "/"
+	case *CoinpaprikaSource:
+		s.baseUrl = server.URL + "/"
+	case *BlockchainSource:
+		s.baseUrl = server.URL + "/"
+	}
+	return source, requested
+}
+
+// sortRates orders rates by asset then currency
+func sortRates(rates []Rate) {
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].Asset != rates[j].Asset {
+			return rates[i].Asset < rates[j].Asset
+		}
+		return rates[i].Currency < rates[j].Currency
+	})
+}
+
+func TestSourceFetch(t *testing.T) {
+	tests := []struct {
+		name        string
+		constructor func(*http.Client) (Source, error)
+		fixture     string
+		expected    []Rate
+	}{
+		// The currencies a source does not quote are skipped
+		{"coingecko", NewCoingecko, "coingecko.json", []Rate{
+			{Asset: BTC, Currency: "EUR", Rate: 8500.25},
+			{Asset: BTC, Currency: "USD", Rate: 9500.5},
+			{Asset: DCR, Currency: "USD", Rate: 25.1},
+		}},
+		// The fixture is served for both assets
+		{"coinpaprika", NewCoinpaprika, "coinpaprika.json", []Rate{
+			{Asset: BTC, Currency: "EUR", Rate: 22.4},
+			{Asset: BTC, Currency: "USD", Rate: 25.1},
+			{Asset: DCR, Currency: "EUR", Rate: 22.4},
+			{Asset: DCR, Currency: "USD", Rate: 25.1},
+		}},
+		{"blockchain", NewBlockchain, "blockchain.json", []Rate{
+			{Asset: BTC, Currency: "EUR", Rate: 8500.25},
+			{Asset: BTC, Currency: "USD", Rate: 9500.5},
+		}},
+	}
+	for _, test := range tests {
+		source, _ := fixtureSource(t, test.constructor, test.fixture)
+		rates, err := source.Fetch(context.Background(), []string{"USD", "EUR", "JPY"})
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		sortRates(rates)
+		if len(rates) != len(test.expected) {
+			t.Errorf("%s: got rates %+v", test.name, rates)
+			continue
+		}
+		for i, rate := range rates {
+			expected := test.expected[i]
+			if rate.Asset != expected.Asset || rate.Currency != expected.Currency || rate.Rate != expected.Rate {
+				t.Errorf("%s: rate %d is %+v, expected %+v", test.name, i, rate, expected)
+			}
+			if rate.Source != source.Name() {
+				t.Errorf("%s: rate %d from %q", test.name, i, rate.Source)
+			}
+		}
+	}
+}
+
+func TestCoinpaprikaRequestsEachAsset(t *testing.T) {
+	source, requested := fixtureSource(t, NewCoinpaprika, "coinpaprika.json")
+	if _, err := source.Fetch(context.Background(), []string{"USD"}); err != nil {
+		t.Fatal(err)
+	}
+	paths := requested()
+	sort.Strings(paths)
+	if len(paths) != 2 || paths[0] != "/btc-bitcoin" || paths[1] != "/dcr-decred" {
+		t.Errorf("requested %v", paths)
+	}
+}
+
+func TestSourceFetchInvalidResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>"))
+	}))
+	defer server.Close()
+	source, err := NewBlockchain(server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.(*BlockchainSource).baseUrl = server.URL
+	if _, err = source.Fetch(context.Background(), []string{"USD"}); err == nil {
+		t.Error("invalid response parsed")
+	}
+}
+
+func TestNewSourceNilClient(t *testing.T) {
+	for name, constructor := range SourceConstructors {
+		if _, err := constructor(nil); err == nil {
+			t.Errorf("%s: nil client accepted", name)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package fiat
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	BTC = "BTC"
+	DCR = "DCR"
+)
+
+// Rate is the price of one unit of Asset in Currency as reported by Source
+type Rate struct {
+	Time     time.Time
+	Asset    string
+	Currency string
+	Rate     float64
+	Source   string
+}
+
+// RateDto represents a fiat rate, formatted for presentation
+type RateDto struct {
+	Time     time.Time `json:"time"`
+	Asset    string    `json:"asset"`
+	Currency string    `json:"currency"`
+	Rate     float64   `json:"rate"`
+	Sources  int       `json:"sources"`
+}
+
+type RateStore interface {
+	StoreFiatRates(context.Context, []Rate) error
+}
+
+// RateQuery returns the rate of asset in currency closest to the given time
+type RateQuery interface {
+	FiatRate(ctx context.Context, asset, currency string, at time.Time) (float64, error)
+}
+
+type coingeckoAPIResponse map[string]map[string]float64
+
+type coinpaprikaQuote struct {
+	Price float64 `json:"price"`
+}
+
+type coinpaprikaAPIResponse struct {
+	Quotes map[string]coinpaprikaQuote `json:"quotes"`
+}
+
+type blockchainTicker struct {
+	Last float64 `json:"last"`
+}
+
+type blockchainAPIResponse map[string]blockchainTicker
@@ -2,10 +2,12 @@ module github.com/raedahgroup/dcrextdata
 
 require (
 	github.com/BurntSushi/toml v0.3.1 // indirect
+	github.com/decred/dcrd/crypto/blake256 v1.0.0
 	github.com/decred/slog v1.0.0
-	github.com/ericlagergren/decimal v0.0.0-20190331172538-c787d12d14a5 // indirect
+	github.com/ericlagergren/decimal v0.0.0-20190331172538-c787d12d14a5
 	github.com/go-chi/chi v4.0.2+incompatible
 	github.com/gofrs/uuid v3.2.0+incompatible // indirect
+	github.com/gorilla/websocket v1.4.2
 	github.com/jessevdk/go-flags v1.4.0
 	github.com/jrick/logrotate v1.0.0
 	github.com/kat-co/vala v0.0.0-20170210184112-42e1d8b61f12
@@ -16,6 +18,8 @@ require (
 	github.com/volatiletech/inflect v0.0.0-20170731032912-e7201282ae8d // indirect
 	github.com/volatiletech/null v8.0.0+incompatible
 	github.com/volatiletech/sqlboiler v3.2.0+incompatible
+	github.com/xitongsys/parquet-go v1.5.1
+	github.com/xitongsys/parquet-go-source v0.0.0-20190524061010-2b72cbee77d5
 	golang.org/x/sys v0.0.0-20190312061237-fead79001313 // indirect
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 )
@@ -0,0 +1,40 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ericlagergren/decimal"
+)
+
+// ParseDecimal parses s into a decimal that keeps every digit of s
+func ParseDecimal(s string) (*decimal.Big, error) {
+	d, ok := new(decimal.Big).SetString(strings.TrimSpace(s))
+	if !ok || !d.IsFinite() {
+		return nil, fmt.Errorf("invalid decimal %q", s)
+	}
+	return d, nil
+}
+
+// DecimalFloat returns d as a float64 for use where rounding does not
+// matter, such as charts and ratios. A nil decimal is 0.
+func DecimalFloat(d *decimal.Big) float64 {
+	if d == nil {
+		return 0
+	}
+	f, _ := d.Float64()
+	return f
+}
+
+// DecimalString formats d without an exponent, or returns an empty string
+// for a nil decimal
+func DecimalString(d *decimal.Big) string {
+	if d == nil {
+		return ""
+	}
+	return fmt.Sprintf("%f", d)
+}
@@ -44,7 +44,11 @@ func GetResponse(ctx context.Context, client *http.Client, url string, destinati
 			if i == maxRetryAttempts {
 				return err
 			}
-			time.Sleep(retryDelay)
+			select {
+			case <-time.After(retryDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 			continue
 		}
 		resp = res
@@ -92,6 +96,50 @@ func AddParams(base string, params map[string]interface{}) (string, error) {
 	return str[:len(str)-1], nil
 }
 
+const (
+	TimeUnix   = "unix"
+	TimeUnixMs = "unixms"
+)
+
+// ParseTime parses a time given as unix seconds, unix milliseconds or in a Go
+// time layout, according to format
+func ParseTime(value string, format string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	switch format {
+	case TimeUnix, TimeUnixMs:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if format == TimeUnixMs {
+			n /= 1000
+		}
+		return time.Unix(int64(n), 0).UTC(), nil
+	default:
+		t, err := time.ParseInLocation(format, value, time.UTC)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return t.UTC(), nil
+	}
+}
+
+// ParseDate parses a time given as 2006-01-02 or RFC3339. An empty value is
+// the zero time.
+func ParseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return t, fmt.Errorf("Invalid time %q, expected 2006-01-02 or RFC3339", value)
+	}
+	return t, nil
+}
+
 func UnixTimeToString(t int64) string {
 	return time.Unix(t, 0).UTC().String()
 }
@@ -0,0 +1,109 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/raedahgroup/dcrextdata/exchanges/importer"
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+	"github.com/raedahgroup/dcrextdata/postgres"
+)
+
+// maxReportedRowErrors limits how many invalid rows are logged individually
+const maxReportedRowErrors = 20
+
+// importTicks reads the candles of the file given in opts and stores them as
+// ticks of the given exchange, pair and interval.
+func importTicks(ctx context.Context, db *postgres.PgDb, opts ImportOptions) error {
+	if opts.Interval <= 0 {
+		return fmt.Errorf("Invalid candle interval %d", opts.Interval)
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(opts.File)), ".")
+	}
+
+	market, err := ticks.ParsePair(opts.Pair)
+	if err != nil {
+		return err
+	}
+	volumeSide, err := ticks.ParseVolumeSide(opts.VolumeSide)
+	if err != nil {
+		return err
+	}
+
+	delimiter, _ := utf8.DecodeRuneInString(opts.Delimiter)
+	if delimiter == utf8.RuneError {
+		return fmt.Errorf("Invalid CSV delimiter %q", opts.Delimiter)
+	}
+
+	file, err := os.Open(opts.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	interval := time.Duration(opts.Interval) * time.Minute
+	data, rowErrs, err := importer.Read(file, importer.Options{
+		Interval:   interval,
+		Format:     format,
+		Columns:    opts.Columns,
+		TimeFormat: opts.TimeFormat,
+		NoHeader:   opts.NoHeader,
+		Delimiter:  delimiter,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, rowErr := range rowErrs {
+		if i == maxReportedRowErrors {
+			log.Warnf("... and %d more invalid rows", len(rowErrs)-maxReportedRowErrors)
+			break
+		}
+		log.Warnf("Skipping invalid %v", rowErr)
+	}
+
+	ticks.DeriveVolumes(data, volumeSide)
+
+	var result importer.Result
+	data = importer.Prepare(data, &result)
+	if len(data) == 0 {
+		return fmt.Errorf("No valid candles found in %s", opts.File)
+	}
+
+	log.Infof("Read %d valid candles from %s to %s, %d invalid rows, %d duplicate times",
+		len(data), result.First.Format(time.RFC3339), result.Last.Format(time.RFC3339), len(rowErrs), result.Duplicates)
+
+	if opts.DryRun {
+		log.Info("Dry run, nothing stored")
+		return nil
+	}
+
+	if err = createExchangeTables(db); err != nil {
+		return err
+	}
+
+	exchange := ticks.ExchangeData{
+		Name:       strings.ToLower(opts.Exchange),
+		WebsiteURL: opts.ExchangeURL,
+	}
+	stored, err := importer.Store(ctx, db, exchange, interval, market.Pair(), data)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Imported %d %s %s(%dm) candles, %d were already stored", stored, exchange.Name, market.Pair(),
+		opts.Interval, len(data)-stored)
+	return nil
+}
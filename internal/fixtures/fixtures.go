@@ -0,0 +1,69 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package fixtures serves and decodes the API responses recorded in the
+// testdata directory of the package under test.
+package fixtures
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// Read returns the content of the fixture file name
+func Read(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// Load decodes the JSON fixture file name into v
+func Load(t *testing.T, name string, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(Read(t, name), v); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+}
+
+// Server serves the fixture file of every request path, the fixture of the
+// empty path answering the paths without their own, until the end of the
+// test. It returns the server and a function listing the requested paths.
+func Server(t *testing.T, fixtures map[string]string) (*httptest.Server, func() []string) {
+	t.Helper()
+	bodies := make(map[string][]byte, len(fixtures))
+	for path, name := range fixtures {
+		bodies[path] = Read(t, name)
+	}
+
+	var mtx sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		paths = append(paths, r.URL.Path)
+		mtx.Unlock()
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			if body, ok = bodies[""]; !ok {
+				http.NotFound(w, r)
+				return
+			}
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
@@ -10,10 +10,16 @@ import (
 
 	"github.com/decred/slog"
 	"github.com/jrick/logrotate/rotator"
+	"github.com/raedahgroup/dcrextdata/chain"
 	"github.com/raedahgroup/dcrextdata/exchanges"
+	"github.com/raedahgroup/dcrextdata/exchanges/arbitrage"
+	"github.com/raedahgroup/dcrextdata/exchanges/transfers"
+	"github.com/raedahgroup/dcrextdata/fiat"
 	"github.com/raedahgroup/dcrextdata/postgres"
 	"github.com/raedahgroup/dcrextdata/pow"
+	"github.com/raedahgroup/dcrextdata/pow/share"
 	"github.com/raedahgroup/dcrextdata/vsp"
+	"github.com/raedahgroup/dcrextdata/web"
 )
 
 // logWriter implements an io.Writer that outputs to both standard output and
@@ -40,6 +46,12 @@ var (
 	pqLog      = backendLog.Logger("PSQL")
 	vspLog     = backendLog.Logger("VSPC")
 	powLog     = backendLog.Logger("POWL")
+	fiatLog    = backendLog.Logger("FIAT")
+	arbLog     = backendLog.Logger("ARBT")
+	xferLog    = backendLog.Logger("XFER")
+	shareLog   = backendLog.Logger("SHAR")
+	chainLog   = backendLog.Logger("CHAN")
+	webLog     = backendLog.Logger("WEBS")
 )
 
 // subsystemLoggers maps each subsystem identifier to its associated logger.
@@ -49,6 +61,12 @@ var subsystemLoggers = map[string]slog.Logger{
 	"PSQL": pqLog,
 	"VSPC": vspLog,
 	"POWL": powLog,
+	"FIAT": fiatLog,
+	"ARBT": arbLog,
+	"XFER": xferLog,
+	"SHAR": shareLog,
+	"CHAN": chainLog,
+	"WEBS": webLog,
 }
 
 func init() {
@@ -56,6 +74,12 @@ func init() {
 	exchanges.UseLogger(excLog)
 	postgres.UseLogger(pqLog)
 	vsp.UseLogger(vspLog)
+	fiat.UseLogger(fiatLog)
+	arbitrage.UseLogger(arbLog)
+	transfers.UseLogger(xferLog)
+	share.UseLogger(shareLog)
+	chain.UseLogger(chainLog)
+	web.UseLogger(webLog)
 }
 
 // initLogRotator initializes the logging rotater to write logs to logFile and
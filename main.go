@@ -12,9 +12,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/raedahgroup/dcrextdata/chain"
 	"github.com/raedahgroup/dcrextdata/exchanges"
+	"github.com/raedahgroup/dcrextdata/exchanges/arbitrage"
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+	"github.com/raedahgroup/dcrextdata/exchanges/transfers"
+	"github.com/raedahgroup/dcrextdata/fiat"
 	"github.com/raedahgroup/dcrextdata/postgres"
 	"github.com/raedahgroup/dcrextdata/pow"
+	"github.com/raedahgroup/dcrextdata/pow/share"
 	"github.com/raedahgroup/dcrextdata/version"
 	"github.com/raedahgroup/dcrextdata/vsp"
 	"github.com/raedahgroup/dcrextdata/web"
@@ -81,12 +87,216 @@ func _main(ctx context.Context) error {
 		log.Info("Tables dropped")
 	}
 
+	switch cfg.command {
+	case "import":
+		return importTicks(ctx, db, cfg.Import)
+	case "export":
+		return exportData(ctx, db, cfg.Export)
+	case "exchanges":
+		return manageExchanges(ctx, db, cfg.RESTExchanges, cfg.Exchanges)
+	case "backfill":
+		return manageBackfills(ctx, db, cfg.RESTExchanges, cfg.Backfill)
+	}
+
 	if cfg.HttpMode {
-		go web.StartHttpServer(cfg.HTTPHost, cfg.HTTPPort, db)
+		costs := pow.MiningCosts{Efficiency: cfg.ProfitEfficiency, PowerCost: cfg.ProfitPowerCost}
+		if err := costs.Validate(); err != nil {
+			log.Error(err)
+			return err
+		}
+		go web.StartHttpServer(cfg.HTTPHost, cfg.HTTPPort, cfg.AdminToken, costs, db)
+	}
+
+	if !cfg.DisableExchangeTicks {
+		if err := syncExchangeRegistry(ctx, db, cfg.RESTExchanges); err != nil {
+			return err
+		}
 	}
 
 	wg := new(sync.WaitGroup)
 
+	if !cfg.DisableExchangeTicks {
+		if exists := db.BackfillJobTableExits(); !exists {
+			if err := db.CreateBackfillJobTable(); err != nil {
+				log.Error("Error creating backfill job table: ", err)
+				return err
+			}
+		}
+
+		backfillRunner, err := exchanges.NewBackfillRunner(backfillPollInterval, db)
+		if err == nil {
+			wg.Add(1)
+			go backfillRunner.Run(ctx, wg)
+		} else {
+			log.Error(err)
+		}
+	}
+
+	if !cfg.DisableExchangeTicks && !cfg.DisableDiscovery {
+		discoverer, err := exchanges.NewMarketDiscoverer(cfg.DisabledExchanges,
+			time.Duration(cfg.DiscoveryInterval)*time.Second, cfg.AutoCollectMarkets, db)
+		if err == nil {
+			wg.Add(1)
+			go discoverer.Run(ctx, wg)
+		} else {
+			log.Error(err)
+		}
+	}
+
+	if !cfg.DisablePow {
+		if exists := db.PowDataTableExits(); !exists {
+			if err := db.CreatePowDataTable(); err != nil {
+				log.Error("Error creating PoW data table: ", err)
+				return err
+			}
+		} else {
+			if err := db.MigratePowDataDecimals(); err != nil {
+				log.Error("Error converting PoW prices to decimals: ", err)
+				return err
+			}
+			if err := db.MigratePowDataHashrateUnits(pow.LegacyHashrateUnits()); err != nil {
+				log.Error("Error converting PoW hashrates to H/s: ", err)
+				return err
+			}
+		}
+
+		if exists := db.PowCursorTableExits(); !exists {
+			if err := db.CreatePowCursorTable(); err != nil {
+				log.Error("Error creating PoW cursor table: ", err)
+				return err
+			}
+		}
+
+		if exists := db.PowBlockTableExits(); !exists {
+			if err := db.CreatePowBlockTable(); err != nil {
+				log.Error("Error creating PoW block table: ", err)
+				return err
+			}
+
+			if err := db.CreatePowBlockIndex(); err != nil {
+				log.Error("Error creating PoW block index: ", err)
+				return err
+			}
+		}
+
+		powCollector, err := pow.NewCollector(cfg.DisabledPows, cfg.PowInterval, cfg.PowIntervals, cfg.PowTimeout,
+			!cfg.DisablePowBlocks, db)
+		if err == nil {
+			wg.Add(1)
+			go powCollector.Run(ctx, wg)
+		} else {
+			log.Error(err)
+		}
+
+		if !cfg.DisablePoolShare {
+			if err := createPoolShareTables(db); err != nil {
+				return err
+			}
+
+			// Periods are computed once every source had time to report
+			var settle time.Duration
+			if powCollector != nil {
+				settle = powCollector.LongestDelay()
+			}
+			shareMonitor, err := share.NewMonitor(time.Duration(cfg.PoolShareInterval)*time.Second, settle,
+				cfg.PoolShareThreshold, cfg.TopPools, cfg.TopPoolsThreshold, db)
+			if err == nil {
+				wg.Add(1)
+				go shareMonitor.Run(ctx, wg)
+			} else {
+				log.Error(err)
+			}
+		}
+	}
+
+	if cfg.DcrdRPCServer != "" {
+		if exists := db.ChainBlockTableExits(); !exists {
+			if err := db.CreateChainBlockTable(); err != nil {
+				log.Error("Error creating chain block table: ", err)
+				return err
+			}
+		} else if err := db.MigrateChainBlockFreshStake(); err != nil {
+			log.Error("Error adding the fresh stake to chain blocks: ", err)
+			return err
+		}
+
+		node, err := chain.NewClient(chain.RPCConfig{
+			Host:  cfg.DcrdRPCServer,
+			User:  cfg.DcrdRPCUser,
+			Pass:  cfg.DcrdRPCPass,
+			Cert:  cfg.DcrdRPCCert,
+			NoTLS: cfg.DcrdNoTLS,
+		})
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+		defer node.Close()
+
+		chainCollector, err := chain.NewCollector(node, time.Duration(cfg.ChainInterval)*time.Second,
+			cfg.ChainHistory, db)
+		if err == nil {
+			wg.Add(1)
+			go chainCollector.Run(ctx, wg)
+		} else {
+			log.Error(err)
+		}
+
+		if !cfg.DisableProjection {
+			if exists := db.ChainProjectionTableExits(); !exists {
+				if err := db.CreateChainProjectionTable(); err != nil {
+					log.Error("Error creating chain projection table: ", err)
+					return err
+				}
+			}
+
+			projector, err := chain.NewProjector(time.Duration(cfg.ChainInterval)*time.Second, db)
+			if err == nil {
+				wg.Add(1)
+				go projector.Run(ctx, wg)
+			} else {
+				log.Error(err)
+			}
+		}
+	}
+
+	if cfg.PropagationNodes != "" {
+		if err := createPropagationTables(db); err != nil {
+			return err
+		}
+
+		nodes, err := chain.LoadNodes(cfg.PropagationNodes)
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+		propagationMonitor, err := chain.NewPropagationMonitor(nodes, cfg.PropagationVotes,
+			time.Duration(cfg.PropagationSettle)*time.Second, db)
+		if err == nil {
+			wg.Add(1)
+			go propagationMonitor.Run(ctx, wg)
+		} else {
+			log.Error(err)
+		}
+	}
+
+	if !cfg.DisableFiat {
+		if exists := db.FiatRateTableExits(); !exists {
+			if err := db.CreateFiatRateTable(); err != nil {
+				log.Error("Error creating fiat rate table: ", err)
+				return err
+			}
+		}
+
+		fiatCollector, err := fiat.NewCollector(cfg.DisabledFiatSources, cfg.FiatCurrencies, cfg.FiatInterval, db)
+		if err == nil {
+			wg.Add(1)
+			go fiatCollector.Run(ctx, wg)
+		} else {
+			log.Error(err)
+		}
+	}
+
 	collectData := func() error {
 		if !cfg.DisableVSP {
 			if exists := db.VSPInfoTableExits(); !exists {
@@ -117,50 +327,72 @@ func _main(ctx context.Context) error {
 			}
 		}
 		if !cfg.DisableExchangeTicks {
-			if exists := db.ExchangeTableExits(); !exists {
-				if err := db.CreateExchangeTable(); err != nil {
-					log.Error("Error creating exchange table: ", err)
-					return err
+			var health *exchanges.HealthMonitor
+			if !cfg.DisableHealth {
+				if exists := db.ExchangeHealthTableExits(); !exists {
+					if err := db.CreateExchangeHealthTable(); err != nil {
+						log.Error("Error creating exchange health table: ", err)
+						return err
+					}
 				}
-			}
 
-			if exists := db.ExchangeTickTableExits(); !exists {
-				if err := db.CreateExchangeTickTable(); err != nil {
-					log.Error("Error creating exchange tick table: ", err)
-					return err
+				if exists := db.ExchangeOutageTableExits(); !exists {
+					if err := db.CreateExchangeOutageTable(); err != nil {
+						log.Error("Error creating exchange outage table: ", err)
+						return err
+					}
 				}
 
-				if err := db.CreateExchangeTickIndex(); err != nil {
-					log.Error("Error creating exchange tick index: ", err)
-					return err
+				var err error
+				health, err = exchanges.NewHealthMonitor(time.Duration(cfg.StaleAfter)*time.Second,
+					time.Duration(cfg.DownAfter)*time.Second, db)
+				if err != nil {
+					log.Error(err)
 				}
 			}
 
-			ticksHub, err := exchanges.NewTickHub(ctx, cfg.DisabledExchanges, db)
+			ticksHub, err := exchanges.NewTickHub(ctx, cfg.DisabledExchanges, cfg.ExchangeConcurrency,
+				time.Duration(cfg.ExchangeTimeout)*time.Second, health, db)
 			if err == nil {
 				wg.Add(1)
 				ticksHub.Run(ctx, wg)
 			} else {
 				log.Error(err)
 			}
-		}
 
-		if !cfg.DisablePow {
-			if exists := db.PowDataTableExits(); !exists {
-				if err := db.CreatePowDataTable(); err != nil {
-					log.Error("Error creating PoW data table: ", err)
-					return err
+			if !cfg.DisableSpreads {
+				if exists := db.ExchangeSpreadTableExits(); !exists {
+					if err := db.CreateExchangeSpreadTable(); err != nil {
+						log.Error("Error creating exchange spread table: ", err)
+						return err
+					}
 				}
-			}
 
-			powCollector, err := pow.NewCollector(cfg.DisabledPows, cfg.PowInterval, db)
-			if err == nil {
-				wg.Add(1)
-				powCollector.Collect(ctx, wg)
-			} else {
-				log.Error(err)
+				spreadMonitor, err := arbitrage.NewMonitor(cfg.DisabledExchanges, cfg.SpreadThreshold, cfg.SpreadPeriods, db)
+				if err == nil {
+					wg.Add(1)
+					spreadMonitor.Run(ctx, wg)
+				} else {
+					log.Error(err)
+				}
 			}
 
+			if !cfg.DisableTransfers {
+				if exists := db.TransferStatusTableExits(); !exists {
+					if err := db.CreateTransferStatusTable(); err != nil {
+						log.Error("Error creating transfer status table: ", err)
+						return err
+					}
+				}
+
+				transferCollector, err := transfers.NewCollector(cfg.DisabledExchanges, db)
+				if err == nil {
+					wg.Add(1)
+					go transferCollector.Run(ctx, wg)
+				} else {
+					log.Error(err)
+				}
+			}
 		}
 
 		return nil
@@ -187,6 +419,115 @@ func _main(ctx context.Context) error {
 			return nil
 		}
 	}
-	
+}
+
+// syncExchangeRegistry loads the REST exchanges defined in the file at path,
+// if any, and adds them and the built in exchanges to the registry
+func syncExchangeRegistry(ctx context.Context, db *postgres.PgDb, path string) error {
+	if path != "" {
+		if err := exchanges.LoadRESTExchanges(path); err != nil {
+			log.Error("Error loading REST exchanges: ", err)
+			return err
+		}
+	}
+
+	if err := createExchangeTables(db); err != nil {
+		return err
+	}
+	if err := db.SyncExchangeRegistry(ctx, ticks.KnownExchanges()); err != nil {
+		log.Error("Error updating the exchange registry: ", err)
+		return err
+	}
+	return nil
+}
+
+func createExchangeTables(db *postgres.PgDb) error {
+	if exists := db.ExchangeTableExits(); !exists {
+		if err := db.CreateExchangeTable(); err != nil {
+			log.Error("Error creating exchange table: ", err)
+			return err
+		}
+	}
+
+	if exists := db.ExchangeTickTableExits(); !exists {
+		if err := db.CreateExchangeTickTable(); err != nil {
+			log.Error("Error creating exchange tick table: ", err)
+			return err
+		}
+
+		if err := db.CreateExchangeTickIndex(); err != nil {
+			log.Error("Error creating exchange tick index: ", err)
+			return err
+		}
+	} else {
+		if err := db.MigrateExchangeTickVolumes(ticks.ReportedVolumeSides()); err != nil {
+			log.Error("Error adding base and quote volumes to exchange ticks: ", err)
+			return err
+		}
+		if err := db.MigrateExchangeTickDecimals(); err != nil {
+			log.Error("Error converting exchange ticks to decimals: ", err)
+			return err
+		}
+	}
+
+	if exists := db.ExchangeInfoTableExits(); !exists {
+		if err := db.CreateExchangeInfoTable(); err != nil {
+			log.Error("Error creating exchange info table: ", err)
+			return err
+		}
+	}
+
+	if exists := db.ExchangeMarketTableExits(); !exists {
+		if err := db.CreateExchangeMarketTable(); err != nil {
+			log.Error("Error creating exchange market table: ", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func createPropagationTables(db *postgres.PgDb) error {
+	if exists := db.BlockPropagationTableExits(); !exists {
+		if err := db.CreateBlockPropagationTable(); err != nil {
+			log.Error("Error creating block propagation table: ", err)
+			return err
+		}
+
+		if err := db.CreateBlockPropagationIndex(); err != nil {
+			log.Error("Error creating block propagation index: ", err)
+			return err
+		}
+	}
+
+	if exists := db.VotePropagationTableExits(); !exists {
+		if err := db.CreateVotePropagationTable(); err != nil {
+			log.Error("Error creating vote propagation table: ", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func createPoolShareTables(db *postgres.PgDb) error {
+	if exists := db.PowCentralizationTableExits(); !exists {
+		if err := db.CreatePowCentralizationTable(); err != nil {
+			log.Error("Error creating PoW centralization table: ", err)
+			return err
+		}
+	}
+
+	if exists := db.PoolShareTableExits(); !exists {
+		if err := db.CreatePoolShareTable(); err != nil {
+			log.Error("Error creating pool share table: ", err)
+			return err
+		}
+	}
+
+	if exists := db.PoolShareAlertTableExits(); !exists {
+		if err := db.CreatePoolShareAlertTable(); err != nil {
+			log.Error("Error creating pool share alert table: ", err)
+			return err
+		}
+	}
 	return nil
 }
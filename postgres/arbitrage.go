@@ -0,0 +1,127 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/exchanges/arbitrage"
+)
+
+const (
+	selectTickCloses = `SELECT exchange.name, exchange_tick.interval, exchange_tick.currency_pair,
+		exchange_tick.time, exchange_tick.close
+		FROM exchange_tick INNER JOIN exchange ON exchange.id = exchange_tick.exchange_id
+		WHERE exchange_tick.time >= $1 ORDER BY exchange_tick.time`
+
+	selectLastSpreads = `SELECT DISTINCT ON (interval, currency_pair, exchange_a, exchange_b)
+		time, interval, currency_pair, exchange_a, exchange_b, price_a, price_b, spread, streak, sustained
+		FROM exchange_spread ORDER BY interval, currency_pair, exchange_a, exchange_b, time DESC`
+
+	insertSpread = `INSERT INTO exchange_spread (time, interval, currency_pair, exchange_a, exchange_b,
+		price_a, price_b, spread, streak, sustained) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT DO NOTHING`
+
+	selectSpreads = `SELECT time, interval, currency_pair, exchange_a, exchange_b, price_a, price_b,
+		spread, streak, sustained FROM exchange_spread WHERE sustained OR NOT $1
+		ORDER BY time DESC, exchange_a, exchange_b LIMIT $2 OFFSET $3`
+
+	selectSpreadsCount = `SELECT COUNT(*) FROM exchange_spread WHERE sustained OR NOT $1`
+)
+
+// TickCloses fetches the closing price of every exchange tick from the given time
+func (pg *PgDb) TickCloses(ctx context.Context, since time.Time) ([]arbitrage.TickClose, error) {
+	rows, err := pg.db.QueryContext(ctx, selectTickCloses, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var closes []arbitrage.TickClose
+	for rows.Next() {
+		var tc arbitrage.TickClose
+		if err = rows.Scan(&tc.Exchange, &tc.Interval, &tc.CurrencyPair, &tc.Time, &tc.Close); err != nil {
+			return nil, err
+		}
+		closes = append(closes, tc)
+	}
+	return closes, rows.Err()
+}
+
+// LastSpreads fetches the most recent spread of every exchange pair
+func (pg *PgDb) LastSpreads(ctx context.Context) ([]arbitrage.Spread, error) {
+	rows, err := pg.db.QueryContext(ctx, selectLastSpreads)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var spreads []arbitrage.Spread
+	for rows.Next() {
+		var s arbitrage.Spread
+		err = rows.Scan(&s.Time, &s.Interval, &s.CurrencyPair, &s.ExchangeA, &s.ExchangeB,
+			&s.PriceA, &s.PriceB, &s.Spread, &s.Streak, &s.Sustained)
+		if err != nil {
+			return nil, err
+		}
+		spreads = append(spreads, s)
+	}
+	return spreads, rows.Err()
+}
+
+// StoreSpreads
+func (pg *PgDb) StoreSpreads(ctx context.Context, spreads []arbitrage.Spread) error {
+	txr, err := pg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	sustained := 0
+	for _, s := range spreads {
+		_, err = txr.ExecContext(ctx, insertSpread, s.Time.UTC(), s.Interval, s.CurrencyPair, s.ExchangeA,
+			s.ExchangeB, s.PriceA, s.PriceB, s.Spread, s.Streak, s.Sustained)
+		if err != nil {
+			_ = txr.Rollback()
+			return err
+		}
+		if s.Sustained {
+			sustained++
+		}
+	}
+
+	if err = txr.Commit(); err != nil {
+		return err
+	}
+
+	log.Infof("Stored %6d exchange spreads, %d above threshold for the sustained period", len(spreads), sustained)
+	return nil
+}
+
+// FetchSpreads fetches a page of the stored spreads, most recent first
+func (pg *PgDb) FetchSpreads(ctx context.Context, sustainedOnly bool, offset int, limit int) ([]arbitrage.SpreadDto, error) {
+	rows, err := pg.db.QueryContext(ctx, selectSpreads, sustainedOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	spreadDtos := []arbitrage.SpreadDto{}
+	for rows.Next() {
+		var s arbitrage.SpreadDto
+		err = rows.Scan(&s.Time, &s.Interval, &s.CurrencyPair, &s.ExchangeA, &s.ExchangeB,
+			&s.PriceA, &s.PriceB, &s.Spread, &s.Streak, &s.Sustained)
+		if err != nil {
+			return nil, err
+		}
+		spreadDtos = append(spreadDtos, s)
+	}
+	return spreadDtos, rows.Err()
+}
+
+func (pg *PgDb) SpreadsCount(ctx context.Context, sustainedOnly bool) (count int64, err error) {
+	err = pg.db.QueryRowContext(ctx, selectSpreadsCount, sustainedOnly).Scan(&count)
+	return
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+)
+
+const (
+	insertBackfillJob = `INSERT INTO backfill_job (exchange, currency_pair, interval, start_time, end_time,
+		checkpoint, status) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	selectBackfillJobs = `SELECT id, exchange, currency_pair, interval, start_time, end_time, checkpoint,
+		status, error, created_at, updated_at, resumed_at, resumed_from FROM backfill_job`
+
+	// Interrupted jobs go before pending ones
+	selectNextBackfillJob = selectBackfillJobs + ` WHERE status IN ('running', 'pending')
+		ORDER BY status = 'running' DESC, id LIMIT 1`
+
+	updateBackfillStatus = `UPDATE backfill_job SET status = $2, error = $3, updated_at = NOW()
+		WHERE id = $1 AND status = ANY($4)`
+
+	// Running jobs record when they resumed and from which checkpoint
+	startBackfillJob = `UPDATE backfill_job SET status = $2, error = $3, updated_at = NOW(),
+		resumed_at = NOW(), resumed_from = checkpoint WHERE id = $1 AND status = ANY($4)`
+
+	updateBackfillCheckpoint = `UPDATE backfill_job SET checkpoint = $2, updated_at = NOW()
+		WHERE id = $1 RETURNING status`
+)
+
+// CreateBackfillJob stores a new backfill job and returns its id
+func (pg *PgDb) CreateBackfillJob(ctx context.Context, job ticks.BackfillJob) (int, error) {
+	var id int
+	err := pg.db.QueryRowContext(ctx, insertBackfillJob, job.Exchange, job.CurrencyPair, job.Interval,
+		job.Start.UTC(), job.End.UTC(), job.Checkpoint.UTC(), string(job.Status)).Scan(&id)
+	return id, err
+}
+
+// BackfillJobs returns all backfill jobs in the order they were created
+func (pg *PgDb) BackfillJobs(ctx context.Context) ([]ticks.BackfillJob, error) {
+	rows, err := pg.db.QueryContext(ctx, selectBackfillJobs+" ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []ticks.BackfillJob
+	for rows.Next() {
+		job, err := scanBackfillJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// NextBackfillJob returns the job to run next, the oldest interrupted job or
+// else the oldest pending job. It returns nil when there is none.
+func (pg *PgDb) NextBackfillJob(ctx context.Context) (*ticks.BackfillJob, error) {
+	job, err := scanBackfillJob(pg.db.QueryRowContext(ctx, selectNextBackfillJob))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// SetBackfillStatus changes the status and error of a job whose status is
+// one of from, and returns whether it did
+func (pg *PgDb) SetBackfillStatus(ctx context.Context, id int, status ticks.BackfillStatus, errMsg string, from ...ticks.BackfillStatus) (bool, error) {
+	query := updateBackfillStatus
+	if status == ticks.BackfillRunning {
+		query = startBackfillJob
+	}
+	statuses := make(pq.StringArray, len(from))
+	for i, s := range from {
+		statuses[i] = string(s)
+	}
+	result, err := pg.db.ExecContext(ctx, query, id, string(status), errMsg, statuses)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// UpdateBackfillCheckpoint stores the checkpoint of a job and returns the
+// job's status, which may have been changed while it ran
+func (pg *PgDb) UpdateBackfillCheckpoint(ctx context.Context, id int, checkpoint time.Time) (ticks.BackfillStatus, error) {
+	var status ticks.BackfillStatus
+	err := pg.db.QueryRowContext(ctx, updateBackfillCheckpoint, id, checkpoint.UTC()).Scan(&status)
+	return status, err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBackfillJob(row rowScanner) (ticks.BackfillJob, error) {
+	var job ticks.BackfillJob
+	var resumedAt, resumedFrom pq.NullTime
+	err := row.Scan(&job.ID, &job.Exchange, &job.CurrencyPair, &job.Interval, &job.Start, &job.End,
+		&job.Checkpoint, &job.Status, &job.Error, &job.CreatedAt, &job.UpdatedAt, &resumedAt, &resumedFrom)
+	if err != nil {
+		return job, err
+	}
+	if resumedAt.Valid {
+		job.ResumedAt = &resumedAt.Time
+	}
+	if resumedFrom.Valid {
+		job.ResumedFrom = &resumedFrom.Time
+	}
+	return job, nil
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/raedahgroup/dcrextdata/chain"
+	"github.com/volatiletech/sqlboiler/types"
+)
+
+const (
+	chainBlockColumns = `height, hash, time, difficulty, network_hashrate, ticket_price, pool_size, voters,
+		fresh_stake, revocations`
+
+	selectLastChainBlock = `SELECT ` + chainBlockColumns + ` FROM chain_block ORDER BY height DESC LIMIT 1`
+
+	selectChainBlockHash = `SELECT hash FROM chain_block WHERE height = $1`
+
+	selectChainBlocks = `SELECT ` + chainBlockColumns + ` FROM chain_block ORDER BY height DESC OFFSET $1 LIMIT $2`
+
+	selectChainBlockCount = `SELECT COUNT(*) FROM chain_block`
+
+	deleteChainBlocksFrom = `DELETE FROM chain_block WHERE height >= $1`
+
+	insertChainBlock = `INSERT INTO chain_block (` + chainBlockColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+)
+
+func scanChainBlock(row rowScanner) (chain.Block, error) {
+	var b chain.Block
+	var ticketPrice types.Decimal
+	var freshStake sql.NullInt64
+	err := row.Scan(&b.Height, &b.Hash, &b.Time, &b.Difficulty, &b.NetworkHashrate, &ticketPrice, &b.PoolSize,
+		&b.Voters, &freshStake, &b.Revocations)
+	b.TicketPrice = ticketPrice.Big
+	b.FreshStake = -1
+	if freshStake.Valid {
+		b.FreshStake = int(freshStake.Int64)
+	}
+	return b, err
+}
+
+// LastChainBlock fetches the stored block with the greatest height, nil when
+// no block is stored
+func (pg *PgDb) LastChainBlock(ctx context.Context) (*chain.Block, error) {
+	b, err := scanChainBlock(pg.db.QueryRowContext(ctx, selectLastChainBlock))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// ChainBlockHash fetches the hash of the stored block at height, an empty
+// string when there is none
+func (pg *PgDb) ChainBlockHash(ctx context.Context, height int64) (string, error) {
+	var hash string
+	err := pg.db.QueryRowContext(ctx, selectChainBlockHash, height).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return hash, err
+}
+
+// StoreChainBlocks replaces the stored blocks from the height of the first
+// block with blocks in a single transaction
+func (pg *PgDb) StoreChainBlocks(ctx context.Context, blocks []chain.Block) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	txr, err := pg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err = txr.ExecContext(ctx, deleteChainBlocksFrom, blocks[0].Height); err != nil {
+		_ = txr.Rollback()
+		return err
+	}
+	for _, b := range blocks {
+		if _, err = txr.ExecContext(ctx, insertChainBlock, b.Height, b.Hash, b.Time, b.Difficulty, b.NetworkHashrate,
+			types.NewDecimal(b.TicketPrice), b.PoolSize, b.Voters, b.FreshStake, b.Revocations); err != nil {
+			_ = txr.Rollback()
+			return err
+		}
+	}
+	return txr.Commit()
+}
+
+// FetchChainBlocks fetches a page of the stored blocks, highest first
+func (pg *PgDb) FetchChainBlocks(ctx context.Context, offset int, limit int) ([]chain.Block, error) {
+	rows, err := pg.db.QueryContext(ctx, selectChainBlocks, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := []chain.Block{}
+	for rows.Next() {
+		b, err := scanChainBlock(rows)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
+func (pg *PgDb) ChainBlockCount(ctx context.Context) (int64, error) {
+	var count int64
+	err := pg.db.QueryRowContext(ctx, selectChainBlockCount).Scan(&count)
+	return count, err
+}
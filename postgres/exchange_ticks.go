@@ -12,9 +12,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/raedahgroup/dcrextdata/postgres/models"
 	"github.com/volatiletech/sqlboiler/boil"
 	"github.com/volatiletech/sqlboiler/queries/qm"
+	"github.com/volatiletech/sqlboiler/types"
 
 	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
 )
@@ -23,6 +25,22 @@ const (
 	NegativeFiveMin = time.Duration(-5) * time.Minute
 	NegativeOneHour = time.Duration(-1) * time.Hour
 	NegativeOneDay  = time.Duration(-24) * time.Hour
+
+	addExchangeTickVolumes = `ALTER TABLE exchange_tick
+		ADD COLUMN base_volume NUMERIC NOT NULL DEFAULT 0,
+		ADD COLUMN quote_volume NUMERIC NOT NULL DEFAULT 0;`
+
+	// The volume not reported by an exchange is converted at the typical
+	// price of the candle, as done by ticks.DeriveVolumes
+	backfillFromBaseVolume = `UPDATE exchange_tick SET base_volume = volume,
+		quote_volume = volume * (high + low + close) / 3
+		FROM exchange WHERE exchange.id = exchange_tick.exchange_id AND exchange.name = ANY($1)
+		AND exchange_tick.base_volume = 0 AND exchange_tick.quote_volume = 0 AND exchange_tick.volume > 0`
+
+	backfillFromQuoteVolume = `UPDATE exchange_tick SET quote_volume = volume,
+		base_volume = COALESCE(volume * 3 / NULLIF(high + low + close, 0), 0)
+		FROM exchange WHERE exchange.id = exchange_tick.exchange_id AND exchange.name = ANY($1)
+		AND exchange_tick.base_volume = 0 AND exchange_tick.quote_volume = 0 AND exchange_tick.volume > 0`
 )
 
 var (
@@ -30,7 +48,7 @@ var (
 	zeroTime               time.Time
 )
 
-func (pg *PgDb) RegisterExchange(ctx context.Context, exchange ticks.ExchangeData) (time.Time, time.Time, time.Time, error) {
+func (pg *PgDb) RegisterExchange(ctx context.Context, exchange ticks.ExchangeData, pair string) (time.Time, time.Time, time.Time, error) {
 	xch, err := models.Exchanges(models.ExchangeWhere.Name.EQ(exchange.Name)).One(ctx, pg.db)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -47,15 +65,18 @@ func (pg *PgDb) RegisterExchange(ctx context.Context, exchange ticks.ExchangeDat
 		return int(t.Minutes())
 	}
 	timeDesc := qm.OrderBy("time desc")
-	lastShort, err := models.ExchangeTicks(qm.Expr(models.ExchangeTickWhere.ExchangeID.EQ(xch.ID), models.ExchangeTickWhere.Interval.EQ(toMin(exchange.ShortInterval)), timeDesc)).One(ctx, pg.db)
+	lastShort, err := models.ExchangeTicks(qm.Expr(models.ExchangeTickWhere.ExchangeID.EQ(xch.ID), models.ExchangeTickWhere.Interval.EQ(toMin(exchange.ShortInterval)),
+		models.ExchangeTickWhere.CurrencyPair.EQ(pair), timeDesc)).One(ctx, pg.db)
 	if err == nil {
 		shortTime = lastShort.Time
 	}
-	lastLong, err := models.ExchangeTicks(qm.Expr(models.ExchangeTickWhere.ExchangeID.EQ(xch.ID), models.ExchangeTickWhere.Interval.EQ(toMin(exchange.LongInterval)), timeDesc)).One(ctx, pg.db)
+	lastLong, err := models.ExchangeTicks(qm.Expr(models.ExchangeTickWhere.ExchangeID.EQ(xch.ID), models.ExchangeTickWhere.Interval.EQ(toMin(exchange.LongInterval)),
+		models.ExchangeTickWhere.CurrencyPair.EQ(pair), timeDesc)).One(ctx, pg.db)
 	if err == nil {
 		longTime = lastLong.Time
 	}
-	lastHistoric, err := models.ExchangeTicks(qm.Expr(models.ExchangeTickWhere.ExchangeID.EQ(xch.ID), models.ExchangeTickWhere.Interval.EQ(toMin(exchange.HistoricInterval)), timeDesc)).One(ctx, pg.db)
+	lastHistoric, err := models.ExchangeTicks(qm.Expr(models.ExchangeTickWhere.ExchangeID.EQ(xch.ID), models.ExchangeTickWhere.Interval.EQ(toMin(exchange.HistoricInterval)),
+		models.ExchangeTickWhere.CurrencyPair.EQ(pair), timeDesc)).One(ctx, pg.db)
 	if err == nil {
 		historicTime = lastHistoric.Time
 	}
@@ -67,15 +88,56 @@ func (pg *PgDb) RegisterExchange(ctx context.Context, exchange ticks.ExchangeDat
 	return shortTime, longTime, historicTime, err
 }
 
-// StoreExchangeTicks
-func (pg *PgDb) StoreExchangeTicks(ctx context.Context, name string, interval int, pair string, ticks []ticks.Tick) (time.Time, error) {
+// MigrateExchangeTickVolumes adds the base and quote volume columns to an
+// exchange_tick table created before they existed and fills them in for the
+// stored ticks from the volume side each exchange reports. It runs once, the
+// columns and their values are committed together.
+func (pg *PgDb) MigrateExchangeTickVolumes(sides map[string]ticks.VolumeSide) error {
+	_, err := pg.columnType("exchange_tick", "base_volume")
+	if err != sql.ErrNoRows {
+		return err
+	}
+	log.Info("Adding base and quote volumes to the exchange ticks, this may take a while")
+
+	var base, quote []string
+	for name, side := range sides {
+		if side == ticks.QuoteVolume {
+			quote = append(quote, name)
+		} else {
+			base = append(base, name)
+		}
+	}
+
+	txr, err := pg.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, step := range []struct {
+		query string
+		args  []interface{}
+	}{
+		{addExchangeTickVolumes, nil},
+		{backfillFromBaseVolume, []interface{}{pq.Array(base)}},
+		{backfillFromQuoteVolume, []interface{}{pq.Array(quote)}},
+	} {
+		if _, err = txr.Exec(step.query, step.args...); err != nil {
+			_ = txr.Rollback()
+			return err
+		}
+	}
+	return txr.Commit()
+}
+
+// StoreExchangeTicks stores the ticks, skipping those already stored, and
+// returns the time of the last tick and the number of ticks inserted
+func (pg *PgDb) StoreExchangeTicks(ctx context.Context, name string, interval int, pair string, ticks []ticks.Tick) (time.Time, int, error) {
 	if len(ticks) == 0 {
-		return zeroTime, fmt.Errorf("No ticks recieved for %s", name)
+		return zeroTime, 0, fmt.Errorf("No ticks recieved for %s", name)
 	}
 
 	exchange, err := models.Exchanges(models.ExchangeWhere.Name.EQ(name)).One(ctx, pg.db)
 	if err != nil {
-		return zeroTime, err
+		return zeroTime, 0, err
 	}
 
 	var lastTime time.Time
@@ -87,7 +149,7 @@ func (pg *PgDb) StoreExchangeTicks(ctx context.Context, name string, interval in
 	if err == sql.ErrNoRows {
 		lastTime = ticks[0].Time.Add(-time.Duration(interval))
 	} else if err != nil {
-		return lastTime, err
+		return lastTime, 0, err
 	} else {
 		lastTime = lastTick.Time
 	}
@@ -101,14 +163,17 @@ func (pg *PgDb) StoreExchangeTicks(ctx context.Context, name string, interval in
 		xcTick := tickToExchangeTick(exchange.ID, pair, interval, tick)
 		err = xcTick.Insert(ctx, pg.db, boil.Infer())
 		if err != nil && !strings.Contains(err.Error(), "unique constraint") {
-			return lastTime, err
+			return lastTime, added, err
 		}
 		lastTime = xcTick.Time
-		added++
+		// Duplicate ticks are skipped
+		if err == nil {
+			added++
+		}
 	}
 
 	if added == 0 {
-		log.Infof("No new ticks on %s(%dm) for", name, pair, interval)
+		log.Infof("No new ticks on %s %s(%dm)", name, pair, interval)
 	} else if added == 1 {
 		log.Infof("%-9s %7s, received %6dm ticks, storing      1 entries %s", name, pair,
 			interval, firstTime.Format(dateTemplate))
@@ -122,7 +187,7 @@ func (pg *PgDb) StoreExchangeTicks(ctx context.Context, name string, interval in
 		/*log.Infof("%10s %7s, received %6v ticks %14s %s to %s",
 		name, pair, added, fmt.Sprintf("(%dm each)", interval), firstTime.Format(dateTemplate), lastTime.Format(dateTemplate))*/
 	}
-	return lastTime, nil
+	return lastTime, added, nil
 }
 
 // AllExchange fetches a slice of all exchange from the db
@@ -151,12 +216,14 @@ func (pg *PgDb) FetchExchangeTicks(ctx context.Context, name string, offset int,
 			Interval:     tick.Interval,
 			CurrencyPair: tick.CurrencyPair,
 			Time:         tick.Time,
-			Close:        tick.Close,
+			Close:        tick.Close.Big,
 			ExchangeName: tick.R.Exchange.Name,
-			High:         tick.High,
-			Low:          tick.Low,
-			Open:         tick.Open,
-			Volume:       tick.Volume,
+			High:         tick.High.Big,
+			Low:          tick.Low.Big,
+			Open:         tick.Open.Big,
+			Volume:       tick.Volume.Big,
+			BaseVolume:   tick.BaseVolume.Big,
+			QuoteVolume:  tick.QuoteVolume.Big,
 		})
 	}
 
@@ -178,12 +245,14 @@ func (pg *PgDb) AllExchangeTicks(ctx context.Context, offset int, limit int) ([]
 			Interval:     tick.Interval,
 			CurrencyPair: tick.CurrencyPair,
 			Time:         tick.Time,
-			Close:        tick.Close,
+			Close:        tick.Close.Big,
 			ExchangeName: tick.R.Exchange.Name,
-			High:         tick.High,
-			Low:          tick.Low,
-			Open:         tick.Open,
-			Volume:       tick.Volume,
+			High:         tick.High.Big,
+			Low:          tick.Low.Big,
+			Open:         tick.Open.Big,
+			Volume:       tick.Volume.Big,
+			BaseVolume:   tick.BaseVolume.Big,
+			QuoteVolume:  tick.QuoteVolume.Big,
 		})
 	}
 
@@ -197,11 +266,13 @@ func (pg *PgDb) AllExchangeTicksCount(ctx context.Context) (int64, error) {
 func tickToExchangeTick(exchangeID int, pair string, interval int, tick ticks.Tick) *models.ExchangeTick {
 	return &models.ExchangeTick{
 		ExchangeID:   exchangeID,
-		High:         tick.High,
-		Low:          tick.Low,
-		Open:         tick.Open,
-		Close:        tick.Close,
-		Volume:       tick.Volume,
+		High:         types.NewDecimal(tick.High),
+		Low:          types.NewDecimal(tick.Low),
+		Open:         types.NewDecimal(tick.Open),
+		Close:        types.NewDecimal(tick.Close),
+		Volume:       types.NewDecimal(tick.Volume),
+		BaseVolume:   types.NewDecimal(tick.BaseVolume),
+		QuoteVolume:  types.NewDecimal(tick.QuoteVolume),
 		Time:         tick.Time.UTC(),
 		CurrencyPair: pair,
 		Interval:     interval,
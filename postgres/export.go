@@ -0,0 +1,153 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/raedahgroup/dcrextdata/export"
+	"github.com/volatiletech/sqlboiler/types"
+)
+
+const (
+	exportExchangeTicks = `SELECT exchange.name, exchange_tick.currency_pair, exchange_tick.interval,
+		exchange_tick.time, exchange_tick.open, exchange_tick.high, exchange_tick.low, exchange_tick.close,
+		exchange_tick.volume, exchange_tick.base_volume, exchange_tick.quote_volume FROM exchange_tick INNER JOIN exchange ON exchange.id = exchange_tick.exchange_id`
+
+	exportVSPTicks = `SELECT vsp.name, vsp_tick.time, vsp_tick.immature, vsp_tick.live, vsp_tick.voted,
+		vsp_tick.missed, vsp_tick.pool_fees, vsp_tick.proportion_live, vsp_tick.proportion_missed,
+		vsp_tick.user_count, vsp_tick.users_active FROM vsp_tick INNER JOIN vsp ON vsp.id = vsp_tick.vsp_id`
+
+	exportPowData = `SELECT source, time, COALESCE(NULLIF(network_hashrate, '')::FLOAT8, 0),
+		COALESCE(NULLIF(pool_hashrate, '')::FLOAT8, 0), COALESCE(workers, 0), COALESCE(network_difficulty, 0),
+		coin_price, btc_price FROM pow_data`
+)
+
+// whereBuilder collects the conditions and arguments of a filtered query
+type whereBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+func (w *whereBuilder) add(condition string, arg interface{}) {
+	w.args = append(w.args, arg)
+	w.conditions = append(w.conditions, fmt.Sprintf(condition, len(w.args)))
+}
+
+func (w *whereBuilder) query(base, orderBy string) string {
+	if len(w.conditions) == 0 {
+		return base + " ORDER BY " + orderBy
+	}
+	return base + " WHERE " + strings.Join(w.conditions, " AND ") + " ORDER BY " + orderBy
+}
+
+// ExportExchangeTicks streams the exchange ticks matching the filter to fn in time order
+func (pg *PgDb) ExportExchangeTicks(ctx context.Context, filter export.Filter, fn func(export.Record) error) error {
+	var where whereBuilder
+	if !filter.From.IsZero() {
+		where.add("exchange_tick.time >= $%d", filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		where.add("exchange_tick.time < $%d", filter.To.UTC())
+	}
+	if len(filter.Sources) > 0 {
+		where.add("exchange.name = ANY($%d)", pq.Array(filter.Sources))
+	}
+	if filter.Interval > 0 {
+		where.add("exchange_tick.interval = $%d", filter.Interval)
+	}
+
+	query := where.query(exportExchangeTicks, "exchange_tick.time, exchange.name, exchange_tick.currency_pair, exchange_tick.interval")
+	return pg.streamRows(ctx, query, where.args, func(rows *sql.Rows) error {
+		var t export.ExchangeTick
+		var open, high, low, close, volume, baseVolume, quoteVolume types.Decimal
+		err := rows.Scan(&t.Exchange, &t.CurrencyPair, &t.Interval, &t.Time, &open, &high, &low, &close, &volume,
+			&baseVolume, &quoteVolume)
+		if err != nil {
+			return err
+		}
+		t.Open, t.High, t.Low, t.Close = open.Big, high.Big, low.Big, close.Big
+		t.Volume, t.BaseVolume, t.QuoteVolume = volume.Big, baseVolume.Big, quoteVolume.Big
+		return fn(t)
+	})
+}
+
+// ExportVSPTicks streams the vsp ticks matching the filter to fn in time order
+func (pg *PgDb) ExportVSPTicks(ctx context.Context, filter export.Filter, fn func(export.Record) error) error {
+	var where whereBuilder
+	if !filter.From.IsZero() {
+		where.add("vsp_tick.time >= $%d", filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		where.add("vsp_tick.time < $%d", filter.To.UTC())
+	}
+	if len(filter.Sources) > 0 {
+		where.add("vsp.name = ANY($%d)", pq.Array(filter.Sources))
+	}
+
+	query := where.query(exportVSPTicks, "vsp_tick.time, vsp.name")
+	return pg.streamRows(ctx, query, where.args, func(rows *sql.Rows) error {
+		var t export.VSPTick
+		var name sql.NullString
+		err := rows.Scan(&name, &t.Time, &t.Immature, &t.Live, &t.Voted, &t.Missed, &t.PoolFees,
+			&t.ProportionLive, &t.ProportionMissed, &t.UserCount, &t.UsersActive)
+		if err != nil {
+			return err
+		}
+		t.VSP = name.String
+		return fn(t)
+	})
+}
+
+// ExportPowData streams the PoW data matching the filter to fn in time order
+func (pg *PgDb) ExportPowData(ctx context.Context, filter export.Filter, fn func(export.Record) error) error {
+	var where whereBuilder
+	if !filter.From.IsZero() {
+		where.add("time >= $%d", filter.From.Unix())
+	}
+	if !filter.To.IsZero() {
+		where.add("time < $%d", filter.To.Unix())
+	}
+	if len(filter.Sources) > 0 {
+		where.add("source = ANY($%d)", pq.Array(filter.Sources))
+	}
+
+	query := where.query(exportPowData, "time, source")
+	return pg.streamRows(ctx, query, where.args, func(rows *sql.Rows) error {
+		var p export.Pow
+		var unix int64
+		var coinPrice, btcPrice types.NullDecimal
+		err := rows.Scan(&p.Source, &unix, &p.NetworkHashrate, &p.PoolHashrate, &p.Workers,
+			&p.NetworkDifficulty, &coinPrice, &btcPrice)
+		if err != nil {
+			return err
+		}
+		p.CoinPrice, p.BtcPrice = coinPrice.Big, btcPrice.Big
+		p.Time = time.Unix(unix, 0).UTC()
+		return fn(p)
+	})
+}
+
+// streamRows runs the query and calls scan for every row as it is received
+// from the server, without loading the result set into memory.
+func (pg *PgDb) streamRows(ctx context.Context, query string, args []interface{}, scan func(*sql.Rows) error) error {
+	rows, err := pg.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/fiat"
+)
+
+const (
+	insertFiatRate = `INSERT INTO fiat_rate (time, asset, currency, rate, source)
+		VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING`
+
+	// Rates from all sources collected in the same cycle share a timestamp
+	// and are averaged.
+	selectFiatRateBefore = `SELECT AVG(rate) FROM fiat_rate WHERE asset = $1 AND currency = $2 AND time = (
+		SELECT time FROM fiat_rate WHERE asset = $1 AND currency = $2 AND time <= $3 ORDER BY time DESC LIMIT 1)`
+
+	selectFiatRateAfter = `SELECT AVG(rate) FROM fiat_rate WHERE asset = $1 AND currency = $2 AND time = (
+		SELECT time FROM fiat_rate WHERE asset = $1 AND currency = $2 AND time > $3 ORDER BY time LIMIT 1)`
+
+	// The rates collected closest to the range are included, as FiatRate
+	// uses them for the times at its ends
+	selectFiatRatesBetween = `SELECT time, asset, currency, AVG(rate) FROM fiat_rate
+		WHERE time >= COALESCE((SELECT MAX(time) FROM fiat_rate WHERE time <= $1), $1)
+		AND time <= COALESCE((SELECT MIN(time) FROM fiat_rate WHERE time > $2), $2)
+		GROUP BY time, asset, currency ORDER BY time`
+
+	selectLatestFiatRates = `SELECT DISTINCT ON (asset, currency) time, asset, currency,
+		AVG(rate) OVER (PARTITION BY time, asset, currency), COUNT(*) OVER (PARTITION BY time, asset, currency)
+		FROM fiat_rate ORDER BY asset, currency, time DESC`
+
+	selectFiatCurrencies = `SELECT DISTINCT currency FROM fiat_rate ORDER BY currency`
+)
+
+// StoreFiatRates
+func (pg *PgDb) StoreFiatRates(ctx context.Context, rates []fiat.Rate) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, rate := range rates {
+		_, err := pg.db.ExecContext(ctx, insertFiatRate, rate.Time.UTC(), rate.Asset,
+			rate.Currency, rate.Rate, rate.Source)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(rates) > 0 {
+		log.Infof("Stored %4d fiat rates from %12s %s", len(rates), rates[0].Source,
+			rates[0].Time.UTC().Format(dateTemplate))
+	}
+	return nil
+}
+
+// FiatRate returns the average rate of asset in currency collected closest to
+// but not after the given time. If no such rate exists, the earliest later rate
+// is used.
+func (pg *PgDb) FiatRate(ctx context.Context, asset, currency string, at time.Time) (float64, error) {
+	asset, currency = strings.ToUpper(asset), strings.ToUpper(currency)
+	for _, query := range []string{selectFiatRateBefore, selectFiatRateAfter} {
+		var rate sql.NullFloat64
+		err := pg.db.QueryRowContext(ctx, query, asset, currency, at.UTC()).Scan(&rate)
+		if err != nil {
+			return 0, err
+		}
+		if rate.Valid {
+			return rate.Float64, nil
+		}
+	}
+	return 0, fiat.ErrNoRate
+}
+
+// FiatRatesBetween fetches the average rates of every asset and currency
+// collected between from and to, and those collected closest to the range
+func (pg *PgDb) FiatRatesBetween(ctx context.Context, from, to time.Time) ([]fiat.Rate, error) {
+	rows, err := pg.db.QueryContext(ctx, selectFiatRatesBetween, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []fiat.Rate
+	for rows.Next() {
+		var rate fiat.Rate
+		if err = rows.Scan(&rate.Time, &rate.Asset, &rate.Currency, &rate.Rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+// LatestFiatRates fetches the most recent rate for every collected asset and currency
+func (pg *PgDb) LatestFiatRates(ctx context.Context) ([]fiat.RateDto, error) {
+	rows, err := pg.db.QueryContext(ctx, selectLatestFiatRates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []fiat.RateDto
+	for rows.Next() {
+		var rate fiat.RateDto
+		if err = rows.Scan(&rate.Time, &rate.Asset, &rate.Currency, &rate.Rate, &rate.Sources); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+// FiatCurrencies returns the fiat currencies rates have been collected in
+func (pg *PgDb) FiatCurrencies(ctx context.Context) ([]string, error) {
+	rows, err := pg.db.QueryContext(ctx, selectFiatCurrencies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var currencies []string
+	for rows.Next() {
+		var currency string
+		if err = rows.Scan(&currency); err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, currency)
+	}
+	return currencies, rows.Err()
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+)
+
+const (
+	upsertCollectionSuccess = `INSERT INTO exchange_health (exchange_id, currency_pair, last_success)
+		VALUES ($1, $2, $3) ON CONFLICT (exchange_id, currency_pair)
+		DO UPDATE SET last_success = EXCLUDED.last_success`
+
+	upsertCollectionFailure = `INSERT INTO exchange_health (exchange_id, currency_pair, last_failure, last_error)
+		VALUES ($1, $2, $3, $4) ON CONFLICT (exchange_id, currency_pair)
+		DO UPDATE SET last_failure = EXCLUDED.last_failure, last_error = EXCLUDED.last_error`
+
+	// The newest candle of any collected interval is the last new candle of
+	// a market, each interval's found through the exchange_tick_idx index
+	selectExchangeHealth = `SELECT exchange.name, exchange_health.currency_pair,
+		(SELECT MAX(newest.time) FROM UNNEST($1::INT[]) AS collected(minutes),
+			LATERAL (SELECT time FROM exchange_tick WHERE exchange_tick.exchange_id = exchange_health.exchange_id
+				AND exchange_tick.interval = collected.minutes
+				AND exchange_tick.currency_pair = exchange_health.currency_pair
+				ORDER BY time DESC LIMIT 1) newest),
+		exchange_health.last_success, exchange_health.last_failure, exchange_health.last_error,
+		exchange_health.health, exchange_health.health_since
+		FROM exchange_health JOIN exchange ON exchange.id = exchange_health.exchange_id
+		ORDER BY exchange.name, exchange_health.currency_pair`
+
+	updateMarketHealth = `UPDATE exchange_health SET health = $3, health_since = $4
+		WHERE exchange_id = $1 AND currency_pair = $2`
+
+	endExchangeOutage = `UPDATE exchange_outage SET ended_at = $3
+		WHERE exchange_id = $1 AND currency_pair = $2 AND ended_at IS NULL`
+
+	insertExchangeOutage = `INSERT INTO exchange_outage (exchange_id, currency_pair, health, started_at, reason)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	selectExchangeOutages = `SELECT exchange.name, exchange_outage.currency_pair, exchange_outage.health,
+		exchange_outage.started_at, exchange_outage.ended_at, exchange_outage.reason
+		FROM exchange_outage JOIN exchange ON exchange.id = exchange_outage.exchange_id
+		ORDER BY exchange_outage.started_at DESC, exchange_outage.id DESC OFFSET $1 LIMIT $2`
+
+	selectExchangeOutageCount = `SELECT COUNT(*) FROM exchange_outage`
+)
+
+// RecordCollection stores the time of the last successful or failed request
+// cycle of a market, along with the error of a failed one
+func (pg *PgDb) RecordCollection(ctx context.Context, exchange, pair string, at time.Time, collectErr error) error {
+	id, err := pg.exchangeID(ctx, exchange)
+	if err != nil {
+		return err
+	}
+	if collectErr == nil {
+		_, err = pg.db.ExecContext(ctx, upsertCollectionSuccess, id, pair, at.UTC())
+	} else {
+		_, err = pg.db.ExecContext(ctx, upsertCollectionFailure, id, pair, at.UTC(), collectErr.Error())
+	}
+	return err
+}
+
+// ExchangeHealth returns the collection state of every market with recorded
+// request cycles, ordered by exchange and pair
+func (pg *PgDb) ExchangeHealth(ctx context.Context) ([]ticks.MarketHealth, error) {
+	rows, err := pg.db.QueryContext(ctx, selectExchangeHealth, pq.Array(ticks.CollectedIntervals()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []ticks.MarketHealth
+	for rows.Next() {
+		var state ticks.MarketHealth
+		var lastTick, lastSuccess, lastFailure pq.NullTime
+		err = rows.Scan(&state.Exchange, &state.CurrencyPair, &lastTick, &lastSuccess, &lastFailure,
+			&state.LastError, &state.Health, &state.Since)
+		if err != nil {
+			return nil, err
+		}
+		if lastTick.Valid {
+			state.LastTick = &lastTick.Time
+		}
+		if lastSuccess.Valid {
+			state.LastSuccess = &lastSuccess.Time
+		}
+		if lastFailure.Valid {
+			state.LastFailure = &lastFailure.Time
+		}
+		states = append(states, state)
+	}
+	return states, rows.Err()
+}
+
+// SetMarketHealth changes the health of a market, ending its open outage and
+// starting a new one when the market is not healthy
+func (pg *PgDb) SetMarketHealth(ctx context.Context, exchange, pair string, health ticks.Health, at time.Time, reason string) error {
+	id, err := pg.exchangeID(ctx, exchange)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, updateMarketHealth, id, pair, string(health), at.UTC()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, endExchangeOutage, id, pair, at.UTC()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if health != ticks.HealthHealthy {
+		_, err = tx.ExecContext(ctx, insertExchangeOutage, id, pair, string(health), at.UTC(), reason)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// FetchOutages returns a page of market outages, newest first
+func (pg *PgDb) FetchOutages(ctx context.Context, offset int, limit int) ([]ticks.Outage, error) {
+	rows, err := pg.db.QueryContext(ctx, selectExchangeOutages, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var outages []ticks.Outage
+	for rows.Next() {
+		var outage ticks.Outage
+		var end pq.NullTime
+		err = rows.Scan(&outage.Exchange, &outage.CurrencyPair, &outage.Health, &outage.Start, &end, &outage.Reason)
+		if err != nil {
+			return nil, err
+		}
+		if end.Valid {
+			outage.End = &end.Time
+		}
+		outages = append(outages, outage)
+	}
+	return outages, rows.Err()
+}
+
+// OutagesCount returns the number of stored market outages
+func (pg *PgDb) OutagesCount(ctx context.Context) (int64, error) {
+	var count int64
+	err := pg.db.QueryRowContext(ctx, selectExchangeOutageCount).Scan(&count)
+	return count, err
+}
@@ -19,20 +19,23 @@ import (
 	"github.com/volatiletech/sqlboiler/queries/qm"
 	"github.com/volatiletech/sqlboiler/queries/qmhelper"
 	"github.com/volatiletech/sqlboiler/strmangle"
+	"github.com/volatiletech/sqlboiler/types"
 )
 
 // ExchangeTick is an object representing the database table.
 type ExchangeTick struct {
-	ID           int       `boil:"id" json:"id" toml:"id" yaml:"id"`
-	ExchangeID   int       `boil:"exchange_id" json:"exchange_id" toml:"exchange_id" yaml:"exchange_id"`
-	Interval     int       `boil:"interval" json:"interval" toml:"interval" yaml:"interval"`
-	High         float64   `boil:"high" json:"high" toml:"high" yaml:"high"`
-	Low          float64   `boil:"low" json:"low" toml:"low" yaml:"low"`
-	Open         float64   `boil:"open" json:"open" toml:"open" yaml:"open"`
-	Close        float64   `boil:"close" json:"close" toml:"close" yaml:"close"`
-	Volume       float64   `boil:"volume" json:"volume" toml:"volume" yaml:"volume"`
-	CurrencyPair string    `boil:"currency_pair" json:"currency_pair" toml:"currency_pair" yaml:"currency_pair"`
-	Time         time.Time `boil:"time" json:"time" toml:"time" yaml:"time"`
+	ID           int           `boil:"id" json:"id" toml:"id" yaml:"id"`
+	ExchangeID   int           `boil:"exchange_id" json:"exchange_id" toml:"exchange_id" yaml:"exchange_id"`
+	Interval     int           `boil:"interval" json:"interval" toml:"interval" yaml:"interval"`
+	High         types.Decimal `boil:"high" json:"high" toml:"high" yaml:"high"`
+	Low          types.Decimal `boil:"low" json:"low" toml:"low" yaml:"low"`
+	Open         types.Decimal `boil:"open" json:"open" toml:"open" yaml:"open"`
+	Close        types.Decimal `boil:"close" json:"close" toml:"close" yaml:"close"`
+	Volume       types.Decimal `boil:"volume" json:"volume" toml:"volume" yaml:"volume"`
+	CurrencyPair string        `boil:"currency_pair" json:"currency_pair" toml:"currency_pair" yaml:"currency_pair"`
+	Time         time.Time     `boil:"time" json:"time" toml:"time" yaml:"time"`
+	BaseVolume   types.Decimal `boil:"base_volume" json:"base_volume" toml:"base_volume" yaml:"base_volume"`
+	QuoteVolume  types.Decimal `boil:"quote_volume" json:"quote_volume" toml:"quote_volume" yaml:"quote_volume"`
 
 	R *exchangeTickR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L exchangeTickL  `boil:"-" json:"-" toml:"-" yaml:"-"`
@@ -49,6 +52,8 @@ var ExchangeTickColumns = struct {
 	Volume       string
 	CurrencyPair string
 	Time         string
+	BaseVolume   string
+	QuoteVolume  string
 }{
 	ID:           "id",
 	ExchangeID:   "exchange_id",
@@ -60,22 +65,30 @@ var ExchangeTickColumns = struct {
 	Volume:       "volume",
 	CurrencyPair: "currency_pair",
 	Time:         "time",
+	BaseVolume:   "base_volume",
+	QuoteVolume:  "quote_volume",
 }
 
 // Generated where
 
-type whereHelperfloat64 struct{ field string }
+type whereHelpertypes_Decimal struct{ field string }
 
-func (w whereHelperfloat64) EQ(x float64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.EQ, x) }
-func (w whereHelperfloat64) NEQ(x float64) qm.QueryMod {
+func (w whereHelpertypes_Decimal) EQ(x types.Decimal) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.EQ, x)
+}
+func (w whereHelpertypes_Decimal) NEQ(x types.Decimal) qm.QueryMod {
 	return qmhelper.Where(w.field, qmhelper.NEQ, x)
 }
-func (w whereHelperfloat64) LT(x float64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.LT, x) }
-func (w whereHelperfloat64) LTE(x float64) qm.QueryMod {
+func (w whereHelpertypes_Decimal) LT(x types.Decimal) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LT, x)
+}
+func (w whereHelpertypes_Decimal) LTE(x types.Decimal) qm.QueryMod {
 	return qmhelper.Where(w.field, qmhelper.LTE, x)
 }
-func (w whereHelperfloat64) GT(x float64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.GT, x) }
-func (w whereHelperfloat64) GTE(x float64) qm.QueryMod {
+func (w whereHelpertypes_Decimal) GT(x types.Decimal) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GT, x)
+}
+func (w whereHelpertypes_Decimal) GTE(x types.Decimal) qm.QueryMod {
 	return qmhelper.Where(w.field, qmhelper.GTE, x)
 }
 
@@ -104,24 +117,28 @@ var ExchangeTickWhere = struct {
 	ID           whereHelperint
 	ExchangeID   whereHelperint
 	Interval     whereHelperint
-	High         whereHelperfloat64
-	Low          whereHelperfloat64
-	Open         whereHelperfloat64
-	Close        whereHelperfloat64
-	Volume       whereHelperfloat64
+	High         whereHelpertypes_Decimal
+	Low          whereHelpertypes_Decimal
+	Open         whereHelpertypes_Decimal
+	Close        whereHelpertypes_Decimal
+	Volume       whereHelpertypes_Decimal
 	CurrencyPair whereHelperstring
 	Time         whereHelpertime_Time
+	BaseVolume   whereHelpertypes_Decimal
+	QuoteVolume  whereHelpertypes_Decimal
 }{
 	ID:           whereHelperint{field: "\"exchange_tick\".\"id\""},
 	ExchangeID:   whereHelperint{field: "\"exchange_tick\".\"exchange_id\""},
 	Interval:     whereHelperint{field: "\"exchange_tick\".\"interval\""},
-	High:         whereHelperfloat64{field: "\"exchange_tick\".\"high\""},
-	Low:          whereHelperfloat64{field: "\"exchange_tick\".\"low\""},
-	Open:         whereHelperfloat64{field: "\"exchange_tick\".\"open\""},
-	Close:        whereHelperfloat64{field: "\"exchange_tick\".\"close\""},
-	Volume:       whereHelperfloat64{field: "\"exchange_tick\".\"volume\""},
+	High:         whereHelpertypes_Decimal{field: "\"exchange_tick\".\"high\""},
+	Low:          whereHelpertypes_Decimal{field: "\"exchange_tick\".\"low\""},
+	Open:         whereHelpertypes_Decimal{field: "\"exchange_tick\".\"open\""},
+	Close:        whereHelpertypes_Decimal{field: "\"exchange_tick\".\"close\""},
+	Volume:       whereHelpertypes_Decimal{field: "\"exchange_tick\".\"volume\""},
 	CurrencyPair: whereHelperstring{field: "\"exchange_tick\".\"currency_pair\""},
 	Time:         whereHelpertime_Time{field: "\"exchange_tick\".\"time\""},
+	BaseVolume:   whereHelpertypes_Decimal{field: "\"exchange_tick\".\"base_volume\""},
+	QuoteVolume:  whereHelpertypes_Decimal{field: "\"exchange_tick\".\"quote_volume\""},
 }
 
 // ExchangeTickRels is where relationship names are stored.
@@ -145,9 +162,9 @@ func (*exchangeTickR) NewStruct() *exchangeTickR {
 type exchangeTickL struct{}
 
 var (
-	exchangeTickAllColumns            = []string{"id", "exchange_id", "interval", "high", "low", "open", "close", "volume", "currency_pair", "time"}
+	exchangeTickAllColumns            = []string{"id", "exchange_id", "interval", "high", "low", "open", "close", "volume", "currency_pair", "time", "base_volume", "quote_volume"}
 	exchangeTickColumnsWithoutDefault = []string{"exchange_id", "interval", "high", "low", "open", "close", "volume", "currency_pair", "time"}
-	exchangeTickColumnsWithDefault    = []string{"id"}
+	exchangeTickColumnsWithDefault    = []string{"id", "base_volume", "quote_volume"}
 	exchangeTickPrimaryKeyColumns     = []string{"id"}
 )
 
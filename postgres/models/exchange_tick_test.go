@@ -536,7 +536,7 @@ func testExchangeTicksSelect(t *testing.T) {
 }
 
 var (
-	exchangeTickDBTypes = map[string]string{`ID`: `integer`, `ExchangeID`: `integer`, `Interval`: `integer`, `High`: `double precision`, `Low`: `double precision`, `Open`: `double precision`, `Close`: `double precision`, `Volume`: `double precision`, `CurrencyPair`: `text`, `Time`: `timestamp with time zone`}
+	exchangeTickDBTypes = map[string]string{`ID`: `integer`, `ExchangeID`: `integer`, `Interval`: `integer`, `High`: `numeric`, `Low`: `numeric`, `Open`: `numeric`, `Close`: `numeric`, `Volume`: `numeric`, `CurrencyPair`: `text`, `Time`: `timestamp with time zone`, `BaseVolume`: `numeric`, `QuoteVolume`: `numeric`}
 	_                   = bytes.MinRead
 )
 
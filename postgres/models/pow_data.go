@@ -20,18 +20,19 @@ import (
 	"github.com/volatiletech/sqlboiler/queries/qm"
 	"github.com/volatiletech/sqlboiler/queries/qmhelper"
 	"github.com/volatiletech/sqlboiler/strmangle"
+	"github.com/volatiletech/sqlboiler/types"
 )
 
 // PowDatum is an object representing the database table.
 type PowDatum struct {
-	Time              int          `boil:"time" json:"time" toml:"time" yaml:"time"`
-	NetworkHashrate   null.String  `boil:"network_hashrate" json:"network_hashrate,omitempty" toml:"network_hashrate" yaml:"network_hashrate,omitempty"`
-	PoolHashrate      null.String  `boil:"pool_hashrate" json:"pool_hashrate,omitempty" toml:"pool_hashrate" yaml:"pool_hashrate,omitempty"`
-	Workers           null.Int     `boil:"workers" json:"workers,omitempty" toml:"workers" yaml:"workers,omitempty"`
-	NetworkDifficulty null.Float64 `boil:"network_difficulty" json:"network_difficulty,omitempty" toml:"network_difficulty" yaml:"network_difficulty,omitempty"`
-	CoinPrice         null.String  `boil:"coin_price" json:"coin_price,omitempty" toml:"coin_price" yaml:"coin_price,omitempty"`
-	BTCPrice          null.String  `boil:"btc_price" json:"btc_price,omitempty" toml:"btc_price" yaml:"btc_price,omitempty"`
-	Source            string       `boil:"source" json:"source" toml:"source" yaml:"source"`
+	Time              int               `boil:"time" json:"time" toml:"time" yaml:"time"`
+	NetworkHashrate   null.String       `boil:"network_hashrate" json:"network_hashrate,omitempty" toml:"network_hashrate" yaml:"network_hashrate,omitempty"`
+	PoolHashrate      null.String       `boil:"pool_hashrate" json:"pool_hashrate,omitempty" toml:"pool_hashrate" yaml:"pool_hashrate,omitempty"`
+	Workers           null.Int          `boil:"workers" json:"workers,omitempty" toml:"workers" yaml:"workers,omitempty"`
+	NetworkDifficulty null.Float64      `boil:"network_difficulty" json:"network_difficulty,omitempty" toml:"network_difficulty" yaml:"network_difficulty,omitempty"`
+	CoinPrice         types.NullDecimal `boil:"coin_price" json:"coin_price,omitempty" toml:"coin_price" yaml:"coin_price,omitempty"`
+	BTCPrice          types.NullDecimal `boil:"btc_price" json:"btc_price,omitempty" toml:"btc_price" yaml:"btc_price,omitempty"`
+	Source            string            `boil:"source" json:"source" toml:"source" yaml:"source"`
 
 	R *powDatumR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L powDatumL  `boil:"-" json:"-" toml:"-" yaml:"-"`
@@ -128,14 +129,39 @@ func (w whereHelpernull_Float64) GTE(x null.Float64) qm.QueryMod {
 	return qmhelper.Where(w.field, qmhelper.GTE, x)
 }
 
+type whereHelpertypes_NullDecimal struct{ field string }
+
+func (w whereHelpertypes_NullDecimal) EQ(x types.NullDecimal) qm.QueryMod {
+	return qmhelper.WhereNullEQ(w.field, false, x)
+}
+func (w whereHelpertypes_NullDecimal) NEQ(x types.NullDecimal) qm.QueryMod {
+	return qmhelper.WhereNullEQ(w.field, true, x)
+}
+func (w whereHelpertypes_NullDecimal) IsNull() qm.QueryMod { return qmhelper.WhereIsNull(w.field) }
+func (w whereHelpertypes_NullDecimal) IsNotNull() qm.QueryMod {
+	return qmhelper.WhereIsNotNull(w.field)
+}
+func (w whereHelpertypes_NullDecimal) LT(x types.NullDecimal) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LT, x)
+}
+func (w whereHelpertypes_NullDecimal) LTE(x types.NullDecimal) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LTE, x)
+}
+func (w whereHelpertypes_NullDecimal) GT(x types.NullDecimal) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GT, x)
+}
+func (w whereHelpertypes_NullDecimal) GTE(x types.NullDecimal) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GTE, x)
+}
+
 var PowDatumWhere = struct {
 	Time              whereHelperint
 	NetworkHashrate   whereHelpernull_String
 	PoolHashrate      whereHelpernull_String
 	Workers           whereHelpernull_Int
 	NetworkDifficulty whereHelpernull_Float64
-	CoinPrice         whereHelpernull_String
-	BTCPrice          whereHelpernull_String
+	CoinPrice         whereHelpertypes_NullDecimal
+	BTCPrice          whereHelpertypes_NullDecimal
 	Source            whereHelperstring
 }{
 	Time:              whereHelperint{field: "\"pow_data\".\"time\""},
@@ -143,8 +169,8 @@ var PowDatumWhere = struct {
 	PoolHashrate:      whereHelpernull_String{field: "\"pow_data\".\"pool_hashrate\""},
 	Workers:           whereHelpernull_Int{field: "\"pow_data\".\"workers\""},
 	NetworkDifficulty: whereHelpernull_Float64{field: "\"pow_data\".\"network_difficulty\""},
-	CoinPrice:         whereHelpernull_String{field: "\"pow_data\".\"coin_price\""},
-	BTCPrice:          whereHelpernull_String{field: "\"pow_data\".\"btc_price\""},
+	CoinPrice:         whereHelpertypes_NullDecimal{field: "\"pow_data\".\"coin_price\""},
+	BTCPrice:          whereHelpertypes_NullDecimal{field: "\"pow_data\".\"btc_price\""},
 	Source:            whereHelperstring{field: "\"pow_data\".\"source\""},
 }
 
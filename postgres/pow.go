@@ -2,22 +2,121 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/raedahgroup/dcrextdata/postgres/models"
 	"github.com/raedahgroup/dcrextdata/pow"
 	"github.com/volatiletech/null"
 	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+	"github.com/volatiletech/sqlboiler/types"
 )
 
+const (
+	selectPowCursor = `SELECT time FROM pow_cursor WHERE source = $1`
+
+	upsertPowCursor = `INSERT INTO pow_cursor (source, time) VALUES ($1, $2)
+		ON CONFLICT (source) DO UPDATE SET time = EXCLUDED.time, updated_at = NOW()`
+
+	insertPowBlock = `INSERT INTO pow_block (hash, height, time, reward, source) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (hash) DO NOTHING`
+
+	selectPowBlocks = `SELECT hash, height, time, reward, source FROM pow_block
+		ORDER BY height DESC, source OFFSET $1 LIMIT $2`
+
+	selectPowBlockCount = `SELECT COUNT(*) FROM pow_block`
+
+	selectPoolBlockCounts = `SELECT source, COUNT(*) FROM pow_block WHERE time >= $1 GROUP BY source`
+
+	selectAveragePoolShares = `SELECT source, AVG(share) FROM pool_share WHERE time >= $1 GROUP BY source`
+
+	// Sources that do not report a hashrate or difficulty store 0
+	selectDailyNetworkStats = `SELECT time / 86400 * 86400 AS day,
+		AVG(NULLIF(network_hashrate, '')::FLOAT8) FILTER (WHERE NULLIF(network_hashrate, '')::FLOAT8 > 0),
+		AVG(network_difficulty) FILTER (WHERE network_difficulty > 0)
+		FROM pow_data WHERE time >= $1 GROUP BY day ORDER BY day`
+
+	selectDailyClosePrices = `SELECT FLOOR(EXTRACT(EPOCH FROM time) / 86400)::INT8 * 86400 AS day, AVG(close)::FLOAT8
+		FROM exchange_tick WHERE currency_pair = $1 AND interval = $2 AND time >= $3 GROUP BY day`
+
+	// The table is chain_block or pow_block
+	selectDailyHeights = `SELECT time / 86400 * 86400 AS day, MIN(height) FROM %s WHERE time >= $1 GROUP BY day`
+
+	// The comment marks pow_data tables whose hashrates are all in H/s
+	powDataHashratesComment = `hashrates in H/s`
+
+	selectPowDataComment = `SELECT COALESCE(obj_description('pow_data'::REGCLASS, 'pg_class'), '')`
+
+	scalePowDataNetworkHashrates = `UPDATE pow_data
+		SET network_hashrate = ROUND(network_hashrate::FLOAT8 * $2)::INT8::TEXT
+		WHERE source = $1 AND NULLIF(network_hashrate, '') IS NOT NULL`
+
+	scalePowDataPoolHashrates = `UPDATE pow_data SET pool_hashrate = (pool_hashrate::FLOAT8 * $2)::TEXT
+		WHERE source = $1 AND NULLIF(pool_hashrate, '') IS NOT NULL`
+
+	commentPowDataHashrates = `COMMENT ON TABLE pow_data IS '` + powDataHashratesComment + `'`
+)
+
+// MigratePowDataHashrateUnits converts the hashrates stored before they were
+// normalized to H/s, given the units of the network and pool hashrates of
+// each source that did not store them in H/s. It runs once, the converted
+// table is marked with a comment.
+func (pg *PgDb) MigratePowDataHashrateUnits(network, pool map[string]pow.HashrateUnit) error {
+	var comment string
+	if err := pg.db.QueryRow(selectPowDataComment).Scan(&comment); err != nil || comment == powDataHashratesComment {
+		return err
+	}
+	log.Info("Converting the stored PoW hashrates to H/s")
+
+	txr, err := pg.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, scale := range []struct {
+		query string
+		units map[string]pow.HashrateUnit
+	}{{scalePowDataNetworkHashrates, network}, {scalePowDataPoolHashrates, pool}} {
+		for source, unit := range scale.units {
+			if _, err = txr.Exec(scale.query, source, float64(unit)); err != nil {
+				_ = txr.Rollback()
+				return err
+			}
+		}
+	}
+	if _, err = txr.Exec(commentPowDataHashrates); err != nil {
+		_ = txr.Rollback()
+		return err
+	}
+	return txr.Commit()
+}
+
 func (pg *PgDb) LastPowEntryTime(source string) (time int64) {
 	rows := pg.db.QueryRow(LastPowEntryTime, source)
 	_ = rows.Scan(&time)
 	return
 }
 
-//
+// PowCursor returns the time of the newest stored data of a PoW source, 0 when
+// the source has no cursor
+func (pg *PgDb) PowCursor(source string) (int64, error) {
+	var cursor int64
+	err := pg.db.QueryRow(selectPowCursor, source).Scan(&cursor)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return cursor, err
+}
+
+// SetPowCursor stores the time of the newest stored data of a PoW source
+func (pg *PgDb) SetPowCursor(ctx context.Context, source string, time int64) error {
+	_, err := pg.db.ExecContext(ctx, upsertPowCursor, source, time)
+	return err
+}
+
 func (pg *PgDb) AddPowData(ctx context.Context, data []pow.PowData) error {
 	if err := ctx.Err(); err != nil {
 		return err
@@ -51,8 +150,8 @@ func (pg *PgDb) AddPowData(ctx context.Context, data []pow.PowData) error {
 
 func responseToPowModel(data pow.PowData) (models.PowDatum, error) {
 	return models.PowDatum{
-		BTCPrice:          null.StringFrom(fmt.Sprint(data.BtcPrice)),
-		CoinPrice:         null.StringFrom(fmt.Sprint(data.CoinPrice)),
+		BTCPrice:          types.NewNullDecimal(data.BtcPrice),
+		CoinPrice:         types.NewNullDecimal(data.CoinPrice),
 		NetworkDifficulty: null.Float64From(data.NetworkDifficulty),
 		NetworkHashrate:   null.StringFrom(fmt.Sprint(data.NetworkHashrate)),
 		PoolHashrate:      null.StringFrom(fmt.Sprint(data.PoolHashrate)),
@@ -61,3 +160,219 @@ func responseToPowModel(data pow.PowData) (models.PowDatum, error) {
 		Workers:           null.IntFrom(int(data.Workers)),
 	}, nil
 }
+
+// FetchPowData returns a page of the stored PoW data, newest first
+func (pg *PgDb) FetchPowData(ctx context.Context, offset int, limit int) ([]pow.PowDataDto, error) {
+	powSlice, err := models.PowData(qm.OrderBy("time DESC"), qm.Limit(limit), qm.Offset(offset)).All(ctx, pg.db)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]pow.PowDataDto, 0, len(powSlice))
+	for _, d := range powSlice {
+		networkHashrate, err := parseHashrate(d.NetworkHashrate)
+		if err != nil {
+			return nil, err
+		}
+		poolHashrate, err := parseHashrate(d.PoolHashrate)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, pow.PowDataDto{
+			Time:              time.Unix(int64(d.Time), 0).UTC(),
+			NetworkHashrate:   networkHashrate,
+			PoolHashrate:      poolHashrate,
+			Workers:           int64(d.Workers.Int),
+			NetworkDifficulty: d.NetworkDifficulty.Float64,
+			CoinPrice:         d.CoinPrice.Big,
+			BtcPrice:          d.BTCPrice.Big,
+			Source:            d.Source,
+		})
+	}
+	return data, nil
+}
+
+func (pg *PgDb) PowDataCount(ctx context.Context) (int64, error) {
+	return models.PowData().Count(ctx, pg.db)
+}
+
+// parseHashrate parses a stored hashrate, 0 when it was not reported
+func parseHashrate(hashrate null.String) (float64, error) {
+	if !hashrate.Valid || hashrate.String == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(hashrate.String, 64)
+}
+
+// AddPowBlocks stores the blocks found by a pool. Blocks that were already
+// stored, by any pool, are left untouched.
+func (pg *PgDb) AddPowBlocks(ctx context.Context, blocks []pow.PoolBlock) error {
+	added := 0
+	for _, b := range blocks {
+		result, err := pg.db.ExecContext(ctx, insertPowBlock, b.Hash, b.Height, b.Time,
+			types.NewNullDecimal(b.Reward), b.Source)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			added += int(n)
+		}
+	}
+	if added > 0 {
+		last := blocks[len(blocks)-1]
+		log.Infof("Added %4d PoW blocks from %10s up to height %d", added, last.Source, last.Height)
+	}
+	return nil
+}
+
+// FetchPowBlocks returns a page of the stored pool blocks, highest first
+func (pg *PgDb) FetchPowBlocks(ctx context.Context, offset int, limit int) ([]pow.PoolBlock, error) {
+	rows, err := pg.db.QueryContext(ctx, selectPowBlocks, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := []pow.PoolBlock{}
+	for rows.Next() {
+		var b pow.PoolBlock
+		var reward types.NullDecimal
+		if err = rows.Scan(&b.Hash, &b.Height, &b.Time, &reward, &b.Source); err != nil {
+			return nil, err
+		}
+		b.Reward = reward.Big
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
+func (pg *PgDb) PowBlockCount(ctx context.Context) (int64, error) {
+	var count int64
+	err := pg.db.QueryRowContext(ctx, selectPowBlockCount).Scan(&count)
+	return count, err
+}
+
+// PoolBlockCounts returns the number of blocks found by each pool from since,
+// in unix seconds
+func (pg *PgDb) PoolBlockCounts(ctx context.Context, since int64) (map[string]int64, error) {
+	rows, err := pg.db.QueryContext(ctx, selectPoolBlockCounts, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var source string
+		var count int64
+		if err = rows.Scan(&source, &count); err != nil {
+			return nil, err
+		}
+		counts[source] = count
+	}
+	return counts, rows.Err()
+}
+
+// AveragePoolShares returns the average percentage of the network hashrate
+// held by each pool from since
+func (pg *PgDb) AveragePoolShares(ctx context.Context, since time.Time) (map[string]float64, error) {
+	rows, err := pg.db.QueryContext(ctx, selectAveragePoolShares, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := make(map[string]float64)
+	for rows.Next() {
+		var source string
+		var share float64
+		if err = rows.Scan(&source, &share); err != nil {
+			return nil, err
+		}
+		shares[source] = share
+	}
+	return shares, rows.Err()
+}
+
+// DailyNetworkStats returns the average network hashrate and difficulty
+// reported by the PoW sources on every UTC day from since
+func (pg *PgDb) DailyNetworkStats(ctx context.Context, since time.Time) ([]pow.NetworkDay, error) {
+	rows, err := pg.db.QueryContext(ctx, selectDailyNetworkStats, since.Truncate(24*time.Hour).Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []pow.NetworkDay
+	for rows.Next() {
+		var day int64
+		var hashrate, difficulty sql.NullFloat64
+		if err = rows.Scan(&day, &hashrate, &difficulty); err != nil {
+			return nil, err
+		}
+		days = append(days, pow.NetworkDay{
+			Time:            time.Unix(day, 0).UTC(),
+			NetworkHashrate: hashrate.Float64,
+			Difficulty:      difficulty.Float64,
+		})
+	}
+	return days, rows.Err()
+}
+
+// DailyHeights returns the lowest height of the blocks stored by the chain
+// collector or found by the PoW pools on every UTC day from since, keyed by
+// the unix time of the start of the day. Days without a stored block are left
+// out.
+func (pg *PgDb) DailyHeights(ctx context.Context, since time.Time) (map[int64]int64, error) {
+	heights := make(map[int64]int64)
+	for _, table := range []string{"chain_block", "pow_block"} {
+		if exists, err := pg.tableExists(table); err != nil || !exists {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		rows, err := pg.db.QueryContext(ctx, fmt.Sprintf(selectDailyHeights, table), since.Truncate(24*time.Hour).Unix())
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var day, height int64
+			if err = rows.Scan(&day, &height); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if known, ok := heights[day]; !ok || height < known {
+				heights[day] = height
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return heights, nil
+}
+
+// DailyClosePrices returns the average closing price of the ticks of pair on
+// every UTC day from since, keyed by the unix time of the start of the day.
+// Only the ticks of interval, in minutes, are averaged.
+func (pg *PgDb) DailyClosePrices(ctx context.Context, pair string, interval int, since time.Time) (map[int64]float64, error) {
+	rows, err := pg.db.QueryContext(ctx, selectDailyClosePrices, pair, interval, since.Truncate(24*time.Hour).UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := make(map[int64]float64)
+	for rows.Next() {
+		var day int64
+		var price float64
+		if err = rows.Scan(&day, &price); err != nil {
+			return nil, err
+		}
+		prices[day] = price
+	}
+	return prices, rows.Err()
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/raedahgroup/dcrextdata/chain"
+)
+
+const (
+	selectChainBlocksFrom = `SELECT ` + chainBlockColumns + ` FROM chain_block WHERE height >= $1 ORDER BY height`
+
+	chainProjectionColumns = `retarget_height, height, time, difficulty, projected_difficulty, ticket_price,
+		projected_ticket_price, actual_difficulty, actual_ticket_price`
+
+	insertChainProjection = `INSERT INTO chain_projection (retarget_height, height, time, difficulty,
+		projected_difficulty, ticket_price, projected_ticket_price) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (retarget_height, height) DO NOTHING`
+
+	selectLastChainProjection = `SELECT ` + chainProjectionColumns + ` FROM chain_projection
+		ORDER BY height DESC LIMIT 1`
+
+	// The actual values are those of the retarget block
+	updateChainProjectionActuals = `UPDATE chain_projection p SET actual_difficulty = b.difficulty,
+		actual_ticket_price = b.ticket_price::FLOAT8
+		FROM chain_block b WHERE b.height = p.retarget_height AND p.actual_difficulty IS NULL`
+
+	selectChainProjections = `SELECT ` + chainProjectionColumns + ` FROM chain_projection
+		ORDER BY height DESC OFFSET $1 LIMIT $2`
+
+	selectChainProjectionCount = `SELECT COUNT(*) FROM chain_projection`
+
+	selectResolvedChainProjections = `SELECT ` + chainProjectionColumns + ` FROM chain_projection
+		WHERE actual_difficulty IS NOT NULL AND retarget_height >= $1 ORDER BY height`
+)
+
+func scanChainProjection(row rowScanner) (chain.Projection, error) {
+	var p chain.Projection
+	var projectedTicketPrice, actualDifficulty, actualTicketPrice sql.NullFloat64
+	err := row.Scan(&p.RetargetHeight, &p.Height, &p.Time, &p.Difficulty, &p.ProjectedDifficulty, &p.TicketPrice,
+		&projectedTicketPrice, &actualDifficulty, &actualTicketPrice)
+	p.ProjectedTicketPrice = projectedTicketPrice.Float64
+	p.ActualDifficulty = actualDifficulty.Float64
+	p.ActualTicketPrice = actualTicketPrice.Float64
+	return p, err
+}
+
+func (pg *PgDb) queryChainProjections(ctx context.Context, query string, args ...interface{}) ([]chain.Projection, error) {
+	rows, err := pg.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projections := []chain.Projection{}
+	for rows.Next() {
+		p, err := scanChainProjection(rows)
+		if err != nil {
+			return nil, err
+		}
+		projections = append(projections, p)
+	}
+	return projections, rows.Err()
+}
+
+// ChainBlocks fetches the stored blocks from height from, lowest first
+func (pg *PgDb) ChainBlocks(ctx context.Context, from int64) ([]chain.Block, error) {
+	rows, err := pg.db.QueryContext(ctx, selectChainBlocksFrom, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := []chain.Block{}
+	for rows.Next() {
+		b, err := scanChainBlock(rows)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
+// StoreProjection stores a projection, the ticket price projection as NULL
+// when it is unknown
+func (pg *PgDb) StoreProjection(ctx context.Context, projection chain.Projection) error {
+	var projectedTicketPrice sql.NullFloat64
+	if projection.ProjectedTicketPrice > 0 {
+		projectedTicketPrice = sql.NullFloat64{Float64: projection.ProjectedTicketPrice, Valid: true}
+	}
+	_, err := pg.db.ExecContext(ctx, insertChainProjection, projection.RetargetHeight, projection.Height,
+		projection.Time, projection.Difficulty, projection.ProjectedDifficulty, projection.TicketPrice,
+		projectedTicketPrice)
+	return err
+}
+
+// LastProjection fetches the projection made from the highest block, nil when
+// there is none
+func (pg *PgDb) LastProjection(ctx context.Context) (*chain.Projection, error) {
+	p, err := scanChainProjection(pg.db.QueryRowContext(ctx, selectLastChainProjection))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ResolveProjections sets the actual values of the projections whose retarget
+// block is stored
+func (pg *PgDb) ResolveProjections(ctx context.Context) error {
+	_, err := pg.db.ExecContext(ctx, updateChainProjectionActuals)
+	return err
+}
+
+// FetchChainProjections fetches a page of the projections, the most recent
+// first
+func (pg *PgDb) FetchChainProjections(ctx context.Context, offset int, limit int) ([]chain.Projection, error) {
+	return pg.queryChainProjections(ctx, selectChainProjections, offset, limit)
+}
+
+func (pg *PgDb) ChainProjectionCount(ctx context.Context) (int64, error) {
+	var count int64
+	err := pg.db.QueryRowContext(ctx, selectChainProjectionCount).Scan(&count)
+	return count, err
+}
+
+// FetchResolvedProjections fetches the projections of the retargets from
+// height from that happened
+func (pg *PgDb) FetchResolvedProjections(ctx context.Context, from int64) ([]chain.Projection, error) {
+	return pg.queryChainProjections(ctx, selectResolvedChainProjections, from)
+}
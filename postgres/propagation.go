@@ -0,0 +1,160 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/raedahgroup/dcrextdata/chain"
+)
+
+const (
+	insertBlockPropagation = `INSERT INTO block_propagation (hash, height, node, seen_at, delay_ms)
+		VALUES ($1, $2, $3, $4, $5) ON CONFLICT (hash, node) DO NOTHING`
+
+	insertVotePropagation = `INSERT INTO vote_propagation (hash, block_hash, block_height, node, seen_at, delay_ms)
+		VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (hash, node) DO NOTHING`
+
+	// Pages are of blocks, with the sightings of every node
+	selectBlockPropagation = `SELECT hash, height, node, seen_at, delay_ms FROM block_propagation
+		WHERE hash IN (SELECT hash FROM block_propagation GROUP BY hash, height ORDER BY height DESC, hash
+			OFFSET $1 LIMIT $2)
+		ORDER BY height DESC, hash, seen_at`
+
+	selectPropagatedBlockCount = `SELECT COUNT(DISTINCT hash) FROM block_propagation`
+
+	selectNodeBlockDelays = `SELECT node, COUNT(*), AVG(delay_ms)::FLOAT8, MAX(delay_ms) FROM block_propagation
+		WHERE seen_at >= $1 GROUP BY node`
+
+	selectNodeVoteDelays = `SELECT node, COUNT(*), AVG(delay_ms)::FLOAT8 FROM vote_propagation
+		WHERE seen_at >= $1 GROUP BY node`
+)
+
+// StoreBlockPropagation stores the times the nodes first saw blocks in a
+// single transaction
+func (pg *PgDb) StoreBlockPropagation(ctx context.Context, propagation []chain.BlockPropagation) error {
+	txr, err := pg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, p := range propagation {
+		if _, err = txr.ExecContext(ctx, insertBlockPropagation, p.Hash, p.Height, p.Node, p.SeenAt.UTC(),
+			p.Delay.Nanoseconds()/int64(time.Millisecond)); err != nil {
+			_ = txr.Rollback()
+			return err
+		}
+	}
+	return txr.Commit()
+}
+
+// StoreVotePropagation stores the times the nodes first saw votes in a single
+// transaction
+func (pg *PgDb) StoreVotePropagation(ctx context.Context, propagation []chain.VotePropagation) error {
+	txr, err := pg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, p := range propagation {
+		if _, err = txr.ExecContext(ctx, insertVotePropagation, p.Hash, p.BlockHash, p.BlockHeight, p.Node,
+			p.SeenAt.UTC(), p.Delay.Nanoseconds()/int64(time.Millisecond)); err != nil {
+			_ = txr.Rollback()
+			return err
+		}
+	}
+	return txr.Commit()
+}
+
+// FetchBlockPropagation fetches the sightings of a page of blocks, highest
+// first, each block's sightings in the order the nodes saw it
+func (pg *PgDb) FetchBlockPropagation(ctx context.Context, offset int, limit int) ([]chain.BlockPropagation, error) {
+	rows, err := pg.db.QueryContext(ctx, selectBlockPropagation, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	propagation := []chain.BlockPropagation{}
+	for rows.Next() {
+		var p chain.BlockPropagation
+		var delay int64
+		if err = rows.Scan(&p.Hash, &p.Height, &p.Node, &p.SeenAt, &delay); err != nil {
+			return nil, err
+		}
+		p.SeenAt = p.SeenAt.UTC()
+		p.Delay = time.Duration(delay) * time.Millisecond
+		propagation = append(propagation, p)
+	}
+	return propagation, rows.Err()
+}
+
+func (pg *PgDb) PropagatedBlockCount(ctx context.Context) (int64, error) {
+	var count int64
+	err := pg.db.QueryRowContext(ctx, selectPropagatedBlockCount).Scan(&count)
+	return count, err
+}
+
+// NodeDelays sums up the block and vote delays of every node from since,
+// sorted by node
+func (pg *PgDb) NodeDelays(ctx context.Context, since time.Time) ([]chain.NodeDelays, error) {
+	nodes := make(map[string]*chain.NodeDelays)
+	node := func(name string) *chain.NodeDelays {
+		if nodes[name] == nil {
+			nodes[name] = &chain.NodeDelays{Node: name}
+		}
+		return nodes[name]
+	}
+
+	rows, err := pg.db.QueryContext(ctx, selectNodeBlockDelays, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var count, max int64
+		var average float64
+		if err = rows.Scan(&name, &count, &average, &max); err != nil {
+			return nil, err
+		}
+		n := node(name)
+		n.Blocks = count
+		n.AverageDelay = time.Duration(average * float64(time.Millisecond))
+		n.MaxDelay = time.Duration(max) * time.Millisecond
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	voteRows, err := pg.db.QueryContext(ctx, selectNodeVoteDelays, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer voteRows.Close()
+	for voteRows.Next() {
+		var name string
+		var count int64
+		var average float64
+		if err = voteRows.Scan(&name, &count, &average); err != nil {
+			return nil, err
+		}
+		n := node(name)
+		n.Votes = count
+		n.AverageVoteDelay = time.Duration(average * float64(time.Millisecond))
+	}
+	if err = voteRows.Err(); err != nil {
+		return nil, err
+	}
+
+	delays := make([]chain.NodeDelays, 0, len(nodes))
+	for _, n := range nodes {
+		delays = append(delays, *n)
+	}
+	sort.Slice(delays, func(i, j int) bool {
+		return delays[i].Node < delays[j].Node
+	})
+	return delays, nil
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+)
+
+const (
+	selectExchangeID = `SELECT id FROM exchange WHERE name = $1`
+
+	insertExchange = `INSERT INTO exchange (name, url) VALUES ($1, $2) RETURNING id`
+
+	// Intervals follow the code, the status and notes are kept as edited
+	upsertExchangeInfo = `INSERT INTO exchange_info (exchange_id, status, intervals) VALUES ($1, $2, $3)
+		ON CONFLICT (exchange_id) DO UPDATE SET intervals = EXCLUDED.intervals`
+
+	upsertExchangeMarket = `INSERT INTO exchange_market (exchange_id, currency_pair, base, quote, symbol)
+		VALUES ($1, $2, $3, $4, $5) ON CONFLICT (exchange_id, currency_pair)
+		DO UPDATE SET base = EXCLUDED.base, quote = EXCLUDED.quote, symbol = EXCLUDED.symbol,
+		discovered_at = NULL`
+
+	insertExchangeMarket = `INSERT INTO exchange_market (exchange_id, currency_pair, base, quote)
+		VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`
+
+	insertDiscoveredMarket = `INSERT INTO exchange_market (exchange_id, currency_pair, base, quote, symbol,
+		discovered_at, collect) VALUES ($1, $2, $3, $4, $5, NOW(), $6) ON CONFLICT DO NOTHING`
+
+	insertDefaultExchangeInfo = `INSERT INTO exchange_info (exchange_id) VALUES ($1) ON CONFLICT DO NOTHING`
+
+	// Exchanges without a registry entry, such as imported ones, are active
+	selectExchangeRegistry = `SELECT exchange.id, exchange.name, exchange.url,
+		COALESCE(exchange_info.status, 'active'), COALESCE(exchange_info.intervals, '{}'),
+		COALESCE(exchange_info.notes, ''), exchange_info.updated_at
+		FROM exchange LEFT JOIN exchange_info ON exchange_info.exchange_id = exchange.id
+		ORDER BY exchange.name`
+
+	selectExchangeMarkets = `SELECT exchange_id, currency_pair, base, quote, symbol, listed_at, delisted_at,
+		discovered_at, collect, notes FROM exchange_market ORDER BY exchange_id, currency_pair`
+)
+
+// SyncExchangeRegistry adds the given exchanges and their markets to the
+// registry. The intervals and market symbols of registered exchanges are
+// updated while their status, dates and notes are left as they are. Markets
+// found by discovery that are now configured lose their discovery date.
+func (pg *PgDb) SyncExchangeRegistry(ctx context.Context, exchanges []ticks.ExchangeInfo) error {
+	tx, err := pg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, exchange := range exchanges {
+		var id int
+		err = tx.QueryRowContext(ctx, selectExchangeID, exchange.Name).Scan(&id)
+		if err == sql.ErrNoRows {
+			err = tx.QueryRowContext(ctx, insertExchange, exchange.Name, exchange.WebsiteURL).Scan(&id)
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		intervals := make(pq.Int64Array, len(exchange.Intervals))
+		for i, interval := range exchange.Intervals {
+			intervals[i] = int64(interval)
+		}
+		_, err = tx.ExecContext(ctx, upsertExchangeInfo, id, string(exchange.Status), intervals)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		for _, market := range exchange.Markets {
+			_, err = tx.ExecContext(ctx, upsertExchangeMarket, id, market.Pair, market.Base, market.Quote, market.Symbol)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ExchangeRegistry returns the registry entries of all stored exchanges,
+// ordered by name
+func (pg *PgDb) ExchangeRegistry(ctx context.Context) ([]ticks.ExchangeInfo, error) {
+	rows, err := pg.db.QueryContext(ctx, selectExchangeRegistry)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var infos []ticks.ExchangeInfo
+	index := make(map[int]int)
+	for rows.Next() {
+		var id int
+		var info ticks.ExchangeInfo
+		var intervals pq.Int64Array
+		var updatedAt pq.NullTime
+		err = rows.Scan(&id, &info.Name, &info.WebsiteURL, &info.Status, &intervals, &info.Notes, &updatedAt)
+		if err != nil {
+			return nil, err
+		}
+		for _, interval := range intervals {
+			info.Intervals = append(info.Intervals, int(interval))
+		}
+		info.UpdatedAt = updatedAt.Time
+		index[id] = len(infos)
+		infos = append(infos, info)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	marketRows, err := pg.db.QueryContext(ctx, selectExchangeMarkets)
+	if err != nil {
+		return nil, err
+	}
+	defer marketRows.Close()
+
+	for marketRows.Next() {
+		var id int
+		var market ticks.MarketInfo
+		var listedAt, delistedAt, discoveredAt pq.NullTime
+		err = marketRows.Scan(&id, &market.Pair, &market.Base, &market.Quote, &market.Symbol,
+			&listedAt, &delistedAt, &discoveredAt, &market.Collect, &market.Notes)
+		if err != nil {
+			return nil, err
+		}
+		if listedAt.Valid {
+			market.ListedAt = &listedAt.Time
+		}
+		if delistedAt.Valid {
+			market.DelistedAt = &delistedAt.Time
+		}
+		if discoveredAt.Valid {
+			market.DiscoveredAt = &discoveredAt.Time
+		}
+		if i, ok := index[id]; ok {
+			infos[i].Markets = append(infos[i].Markets, market)
+		}
+	}
+	return infos, marketRows.Err()
+}
+
+// UpdateExchange changes the status and notes of a stored exchange
+func (pg *PgDb) UpdateExchange(ctx context.Context, name string, update ticks.ExchangeUpdate) error {
+	var sets []string
+	var args []interface{}
+	if update.Status != nil {
+		status, err := ticks.ParseExchangeStatus(string(*update.Status))
+		if err != nil {
+			return err
+		}
+		args = append(args, string(status))
+		sets = append(sets, fmt.Sprintf("status = $%d", len(args)+1))
+	}
+	if update.Notes != nil {
+		args = append(args, *update.Notes)
+		sets = append(sets, fmt.Sprintf("notes = $%d", len(args)+1))
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+
+	id, err := pg.exchangeID(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, err = pg.db.ExecContext(ctx, insertDefaultExchangeInfo, id); err != nil {
+		return err
+	}
+	query := fmt.Sprintf("UPDATE exchange_info SET %s, updated_at = NOW() WHERE exchange_id = $1",
+		strings.Join(sets, ", "))
+	_, err = pg.db.ExecContext(ctx, query, append([]interface{}{id}, args...)...)
+	return err
+}
+
+// UpdateExchangeMarket changes the listing dates and notes of a market of a
+// stored exchange, adding the market to the registry if it is not there yet
+func (pg *PgDb) UpdateExchangeMarket(ctx context.Context, name, pair string, update ticks.MarketUpdate) error {
+	market, err := ticks.ParsePair(pair)
+	if err != nil {
+		return err
+	}
+
+	var sets []string
+	var args []interface{}
+	for column, value := range map[string]*time.Time{"listed_at": update.ListedAt, "delisted_at": update.DelistedAt} {
+		if value == nil {
+			continue
+		}
+		if value.IsZero() {
+			args = append(args, nil)
+		} else {
+			args = append(args, value.UTC())
+		}
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)+2))
+	}
+	if update.Collect != nil {
+		args = append(args, *update.Collect)
+		sets = append(sets, fmt.Sprintf("collect = $%d", len(args)+2))
+	}
+	if update.Notes != nil {
+		args = append(args, *update.Notes)
+		sets = append(sets, fmt.Sprintf("notes = $%d", len(args)+2))
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+
+	id, err := pg.exchangeID(ctx, name)
+	if err != nil {
+		return err
+	}
+	_, err = pg.db.ExecContext(ctx, insertExchangeMarket, id, market.Pair(), market.Base, market.Quote)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("UPDATE exchange_market SET %s WHERE exchange_id = $1 AND currency_pair = $2",
+		strings.Join(sets, ", "))
+	_, err = pg.db.ExecContext(ctx, query, append([]interface{}{id, market.Pair()}, args...)...)
+	return err
+}
+
+// RecordDiscoveredMarkets adds the markets found on an exchange to the
+// registry, to be collected if collect is set, and returns the markets that
+// were not registered before
+func (pg *PgDb) RecordDiscoveredMarkets(ctx context.Context, name string, markets []ticks.Market, collect bool) ([]ticks.Market, error) {
+	id, err := pg.exchangeID(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var added []ticks.Market
+	for _, market := range markets {
+		result, err := pg.db.ExecContext(ctx, insertDiscoveredMarket, id, market.Pair(), market.Base,
+			market.Quote, market.Symbol, collect)
+		if err != nil {
+			return added, err
+		}
+		if n, err := result.RowsAffected(); err == nil && n > 0 {
+			added = append(added, market)
+		}
+	}
+	return added, nil
+}
+
+func (pg *PgDb) exchangeID(ctx context.Context, name string) (int, error) {
+	var id int
+	err := pg.db.QueryRowContext(ctx, selectExchangeID, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("Unknown exchange %s", name)
+	}
+	return id, err
+}
@@ -1,6 +1,9 @@
 package postgres
 
-import "fmt"
+import (
+	"database/sql"
+	"fmt"
+)
 
 const (
 	createExchangeTable = `CREATE TABLE IF NOT EXISTS exchange (
@@ -12,13 +15,15 @@ const (
 		id SERIAL PRIMARY KEY,
 		exchange_id INT REFERENCES exchange(id) NOT NULL, 
 		interval INT NOT NULL,
-		high FLOAT NOT NULL,
-		low FLOAT NOT NULL,
-		open FLOAT NOT NULL,
-		close FLOAT NOT NULL,
-		volume FLOAT NOT NULL,
+		high NUMERIC NOT NULL,
+		low NUMERIC NOT NULL,
+		open NUMERIC NOT NULL,
+		close NUMERIC NOT NULL,
+		volume NUMERIC NOT NULL,
 		currency_pair TEXT NOT NULL,
-		time TIMESTAMPTZ NOT NULL
+		time TIMESTAMPTZ NOT NULL,
+		base_volume NUMERIC NOT NULL DEFAULT 0,
+		quote_volume NUMERIC NOT NULL DEFAULT 0
 	);`
 
 	createExchangeTickIndex = `CREATE UNIQUE INDEX IF NOT EXISTS exchange_tick_idx ON exchange_tick (exchange_id, interval, currency_pair, time);`
@@ -57,13 +62,213 @@ const (
 		pool_hashrate VARCHAR(25),
 		workers INT,
 		network_difficulty FLOAT8,
-		coin_price VARCHAR(25),
-		btc_price VARCHAR(25),
+		coin_price NUMERIC,
+		btc_price NUMERIC,
 		source VARCHAR(25),
 		PRIMARY KEY (time, source)
-	);`
+	);
+	COMMENT ON TABLE pow_data IS 'hashrates in H/s';`
+
+	// Conversion of tables created with FLOAT prices and volumes
+	alterExchangeTickDecimals = `ALTER TABLE exchange_tick
+		ALTER COLUMN high TYPE NUMERIC,
+		ALTER COLUMN low TYPE NUMERIC,
+		ALTER COLUMN open TYPE NUMERIC,
+		ALTER COLUMN close TYPE NUMERIC,
+		ALTER COLUMN volume TYPE NUMERIC,
+		ALTER COLUMN base_volume TYPE NUMERIC,
+		ALTER COLUMN quote_volume TYPE NUMERIC;`
+
+	alterPowDataDecimals = `ALTER TABLE pow_data
+		ALTER COLUMN coin_price TYPE NUMERIC USING NULLIF(coin_price, '')::NUMERIC,
+		ALTER COLUMN btc_price TYPE NUMERIC USING NULLIF(btc_price, '')::NUMERIC;`
+
+	alterChainBlockFreshStake = `ALTER TABLE chain_block ADD COLUMN IF NOT EXISTS fresh_stake INT4;`
+
+	columnType = `SELECT data_type FROM information_schema.columns WHERE table_name = $1 AND column_name = $2`
 
 	LastPowEntryTime = `SELECT time FROM pow_data WHERE source=$1 ORDER BY time DESC LIMIT 1`
+
+	// Fiat rate table
+	createFiatRateTable = `CREATE TABLE IF NOT EXISTS fiat_rate (
+		time TIMESTAMPTZ NOT NULL,
+		asset TEXT NOT NULL,
+		currency TEXT NOT NULL,
+		rate FLOAT8 NOT NULL,
+		source TEXT NOT NULL,
+		PRIMARY KEY (time, asset, currency, source)
+	);`
+
+	// Exchange spread table
+	createExchangeSpreadTable = `CREATE TABLE IF NOT EXISTS exchange_spread (
+		time TIMESTAMPTZ NOT NULL,
+		interval INT NOT NULL,
+		currency_pair TEXT NOT NULL,
+		exchange_a TEXT NOT NULL,
+		exchange_b TEXT NOT NULL,
+		price_a FLOAT8 NOT NULL,
+		price_b FLOAT8 NOT NULL,
+		spread FLOAT8 NOT NULL,
+		streak INT NOT NULL,
+		sustained BOOLEAN NOT NULL,
+		PRIMARY KEY (interval, currency_pair, exchange_a, exchange_b, time)
+	);`
+
+	createExchangeInfoTable = `CREATE TABLE IF NOT EXISTS exchange_info (
+		exchange_id INT PRIMARY KEY REFERENCES exchange(id),
+		status TEXT NOT NULL DEFAULT 'active',
+		intervals INT[] NOT NULL DEFAULT '{}',
+		notes TEXT NOT NULL DEFAULT '',
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);`
+
+	createExchangeMarketTable = `CREATE TABLE IF NOT EXISTS exchange_market (
+		exchange_id INT REFERENCES exchange(id) NOT NULL,
+		currency_pair TEXT NOT NULL,
+		base TEXT NOT NULL,
+		quote TEXT NOT NULL,
+		symbol TEXT NOT NULL DEFAULT '',
+		listed_at TIMESTAMPTZ,
+		delisted_at TIMESTAMPTZ,
+		notes TEXT NOT NULL DEFAULT '',
+		discovered_at TIMESTAMPTZ,
+		collect BOOLEAN NOT NULL DEFAULT TRUE,
+		PRIMARY KEY (exchange_id, currency_pair)
+	);`
+
+	createTransferStatusTable = `CREATE TABLE IF NOT EXISTS transfer_status (
+		exchange VARCHAR(25) NOT NULL,
+		time TIMESTAMPTZ NOT NULL,
+		deposit BOOLEAN NOT NULL,
+		withdrawal BOOLEAN NOT NULL,
+		PRIMARY KEY (exchange, time)
+	);`
+
+	createExchangeHealthTable = `CREATE TABLE IF NOT EXISTS exchange_health (
+		exchange_id INT REFERENCES exchange(id) NOT NULL,
+		currency_pair TEXT NOT NULL,
+		last_success TIMESTAMPTZ,
+		last_failure TIMESTAMPTZ,
+		last_error TEXT NOT NULL DEFAULT '',
+		health TEXT NOT NULL DEFAULT 'healthy',
+		health_since TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (exchange_id, currency_pair)
+	);`
+
+	createExchangeOutageTable = `CREATE TABLE IF NOT EXISTS exchange_outage (
+		id SERIAL PRIMARY KEY,
+		exchange_id INT REFERENCES exchange(id) NOT NULL,
+		currency_pair TEXT NOT NULL,
+		health TEXT NOT NULL,
+		started_at TIMESTAMPTZ NOT NULL,
+		ended_at TIMESTAMPTZ,
+		reason TEXT NOT NULL DEFAULT ''
+	);`
+
+	createBackfillJobTable = `CREATE TABLE IF NOT EXISTS backfill_job (
+		id SERIAL PRIMARY KEY,
+		exchange TEXT NOT NULL,
+		currency_pair TEXT NOT NULL,
+		interval INT NOT NULL,
+		start_time TIMESTAMPTZ NOT NULL,
+		end_time TIMESTAMPTZ NOT NULL,
+		checkpoint TIMESTAMPTZ NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		error TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		resumed_at TIMESTAMPTZ,
+		resumed_from TIMESTAMPTZ
+	);`
+
+	createPowCursorTable = `CREATE TABLE IF NOT EXISTS pow_cursor (
+		source VARCHAR(25) PRIMARY KEY,
+		time INT8 NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);`
+
+	createPowCentralizationTable = `CREATE TABLE IF NOT EXISTS pow_centralization (
+		time TIMESTAMPTZ PRIMARY KEY,
+		network_hashrate FLOAT8 NOT NULL,
+		nakamoto_coefficient INT,
+		known_share FLOAT8 NOT NULL,
+		top_share FLOAT8 NOT NULL
+	);`
+
+	createPoolShareTable = `CREATE TABLE IF NOT EXISTS pool_share (
+		time TIMESTAMPTZ NOT NULL,
+		source VARCHAR(25) NOT NULL,
+		hashrate FLOAT8 NOT NULL,
+		share FLOAT8 NOT NULL,
+		PRIMARY KEY (time, source)
+	);`
+
+	createPoolShareAlertTable = `CREATE TABLE IF NOT EXISTS pool_share_alert (
+		id SERIAL PRIMARY KEY,
+		kind VARCHAR(16) NOT NULL,
+		pools TEXT[] NOT NULL,
+		share FLOAT8 NOT NULL,
+		threshold FLOAT8 NOT NULL,
+		started_at TIMESTAMPTZ NOT NULL,
+		ended_at TIMESTAMPTZ
+	);`
+
+	createPowBlockTable = `CREATE TABLE IF NOT EXISTS pow_block (
+		hash VARCHAR(64) PRIMARY KEY,
+		height INT8 NOT NULL,
+		time INT8 NOT NULL,
+		reward NUMERIC,
+		source VARCHAR(25) NOT NULL
+	);`
+
+	createPowBlockIndex = `CREATE INDEX IF NOT EXISTS pow_block_idx ON pow_block (source, time);`
+
+	createChainBlockTable = `CREATE TABLE IF NOT EXISTS chain_block (
+		height INT8 PRIMARY KEY,
+		hash VARCHAR(64) NOT NULL,
+		time INT8 NOT NULL,
+		difficulty FLOAT8 NOT NULL,
+		network_hashrate FLOAT8 NOT NULL,
+		ticket_price NUMERIC NOT NULL,
+		pool_size INT8 NOT NULL,
+		voters INT4 NOT NULL,
+		fresh_stake INT4,
+		revocations INT4 NOT NULL
+	);`
+
+	createBlockPropagationTable = `CREATE TABLE IF NOT EXISTS block_propagation (
+		hash VARCHAR(64) NOT NULL,
+		height INT8 NOT NULL,
+		node VARCHAR(64) NOT NULL,
+		seen_at TIMESTAMPTZ NOT NULL,
+		delay_ms INT8 NOT NULL,
+		PRIMARY KEY (hash, node)
+	);`
+
+	createBlockPropagationIndex = `CREATE INDEX IF NOT EXISTS block_propagation_idx ON block_propagation (seen_at);`
+
+	createVotePropagationTable = `CREATE TABLE IF NOT EXISTS vote_propagation (
+		hash VARCHAR(64) NOT NULL,
+		block_hash VARCHAR(64) NOT NULL,
+		block_height INT8 NOT NULL,
+		node VARCHAR(64) NOT NULL,
+		seen_at TIMESTAMPTZ NOT NULL,
+		delay_ms INT8 NOT NULL,
+		PRIMARY KEY (hash, node)
+	);`
+
+	createChainProjectionTable = `CREATE TABLE IF NOT EXISTS chain_projection (
+		retarget_height INT8 NOT NULL,
+		height INT8 NOT NULL,
+		time INT8 NOT NULL,
+		difficulty FLOAT8 NOT NULL,
+		projected_difficulty FLOAT8 NOT NULL,
+		ticket_price FLOAT8 NOT NULL,
+		projected_ticket_price FLOAT8,
+		actual_difficulty FLOAT8,
+		actual_ticket_price FLOAT8,
+		PRIMARY KEY (retarget_height, height)
+	);`
 )
 
 func (pg *PgDb) CreateExchangeTable() error {
@@ -129,6 +334,186 @@ func (pg *PgDb) PowDataTableExits() bool {
 	return exists
 }
 
+func (pg *PgDb) CreateFiatRateTable() error {
+	_, err := pg.db.Exec(createFiatRateTable)
+	return err
+}
+
+func (pg *PgDb) FiatRateTableExits() bool {
+	exists, _ := pg.tableExists("fiat_rate")
+	return exists
+}
+
+func (pg *PgDb) CreateExchangeSpreadTable() error {
+	_, err := pg.db.Exec(createExchangeSpreadTable)
+	return err
+}
+
+func (pg *PgDb) ExchangeSpreadTableExits() bool {
+	exists, _ := pg.tableExists("exchange_spread")
+	return exists
+}
+
+func (pg *PgDb) CreateExchangeInfoTable() error {
+	_, err := pg.db.Exec(createExchangeInfoTable)
+	return err
+}
+
+func (pg *PgDb) ExchangeInfoTableExits() bool {
+	exists, _ := pg.tableExists("exchange_info")
+	return exists
+}
+
+func (pg *PgDb) CreateExchangeMarketTable() error {
+	_, err := pg.db.Exec(createExchangeMarketTable)
+	return err
+}
+
+func (pg *PgDb) ExchangeMarketTableExits() bool {
+	exists, _ := pg.tableExists("exchange_market")
+	return exists
+}
+
+func (pg *PgDb) CreateTransferStatusTable() error {
+	_, err := pg.db.Exec(createTransferStatusTable)
+	return err
+}
+
+func (pg *PgDb) TransferStatusTableExits() bool {
+	exists, _ := pg.tableExists("transfer_status")
+	return exists
+}
+
+func (pg *PgDb) CreateExchangeHealthTable() error {
+	_, err := pg.db.Exec(createExchangeHealthTable)
+	return err
+}
+
+func (pg *PgDb) ExchangeHealthTableExits() bool {
+	exists, _ := pg.tableExists("exchange_health")
+	return exists
+}
+
+func (pg *PgDb) CreateExchangeOutageTable() error {
+	_, err := pg.db.Exec(createExchangeOutageTable)
+	return err
+}
+
+func (pg *PgDb) ExchangeOutageTableExits() bool {
+	exists, _ := pg.tableExists("exchange_outage")
+	return exists
+}
+
+func (pg *PgDb) CreateBackfillJobTable() error {
+	_, err := pg.db.Exec(createBackfillJobTable)
+	return err
+}
+
+func (pg *PgDb) BackfillJobTableExits() bool {
+	exists, _ := pg.tableExists("backfill_job")
+	return exists
+}
+
+func (pg *PgDb) CreatePowCursorTable() error {
+	_, err := pg.db.Exec(createPowCursorTable)
+	return err
+}
+
+func (pg *PgDb) PowCursorTableExits() bool {
+	exists, _ := pg.tableExists("pow_cursor")
+	return exists
+}
+
+func (pg *PgDb) CreatePowCentralizationTable() error {
+	_, err := pg.db.Exec(createPowCentralizationTable)
+	return err
+}
+
+func (pg *PgDb) PowCentralizationTableExits() bool {
+	exists, _ := pg.tableExists("pow_centralization")
+	return exists
+}
+
+func (pg *PgDb) CreatePoolShareTable() error {
+	_, err := pg.db.Exec(createPoolShareTable)
+	return err
+}
+
+func (pg *PgDb) PoolShareTableExits() bool {
+	exists, _ := pg.tableExists("pool_share")
+	return exists
+}
+
+func (pg *PgDb) CreatePoolShareAlertTable() error {
+	_, err := pg.db.Exec(createPoolShareAlertTable)
+	return err
+}
+
+func (pg *PgDb) PoolShareAlertTableExits() bool {
+	exists, _ := pg.tableExists("pool_share_alert")
+	return exists
+}
+
+func (pg *PgDb) CreatePowBlockTable() error {
+	_, err := pg.db.Exec(createPowBlockTable)
+	return err
+}
+
+func (pg *PgDb) CreatePowBlockIndex() error {
+	_, err := pg.db.Exec(createPowBlockIndex)
+	return err
+}
+
+func (pg *PgDb) PowBlockTableExits() bool {
+	exists, _ := pg.tableExists("pow_block")
+	return exists
+}
+
+func (pg *PgDb) CreateChainBlockTable() error {
+	_, err := pg.db.Exec(createChainBlockTable)
+	return err
+}
+
+func (pg *PgDb) ChainBlockTableExits() bool {
+	exists, _ := pg.tableExists("chain_block")
+	return exists
+}
+
+func (pg *PgDb) CreateBlockPropagationTable() error {
+	_, err := pg.db.Exec(createBlockPropagationTable)
+	return err
+}
+
+func (pg *PgDb) CreateBlockPropagationIndex() error {
+	_, err := pg.db.Exec(createBlockPropagationIndex)
+	return err
+}
+
+func (pg *PgDb) BlockPropagationTableExits() bool {
+	exists, _ := pg.tableExists("block_propagation")
+	return exists
+}
+
+func (pg *PgDb) CreateVotePropagationTable() error {
+	_, err := pg.db.Exec(createVotePropagationTable)
+	return err
+}
+
+func (pg *PgDb) VotePropagationTableExits() bool {
+	exists, _ := pg.tableExists("vote_propagation")
+	return exists
+}
+
+func (pg *PgDb) CreateChainProjectionTable() error {
+	_, err := pg.db.Exec(createChainProjectionTable)
+	return err
+}
+
+func (pg *PgDb) ChainProjectionTableExits() bool {
+	exists, _ := pg.tableExists("chain_projection")
+	return exists
+}
+
 func (pg *PgDb) tableExists(name string) (bool, error) {
 	rows, err := pg.db.Query(`SELECT relname FROM pg_class WHERE relname = $1`, name)
 	if err == nil {
@@ -142,6 +527,49 @@ func (pg *PgDb) tableExists(name string) (bool, error) {
 	return false, err
 }
 
+func (pg *PgDb) columnType(table, column string) (string, error) {
+	var dataType string
+	err := pg.db.QueryRow(columnType, table, column).Scan(&dataType)
+	return dataType, err
+}
+
+// MigrateExchangeTickDecimals converts the prices and volumes of an
+// exchange_tick table created with FLOAT columns to NUMERIC
+func (pg *PgDb) MigrateExchangeTickDecimals() error {
+	dataType, err := pg.columnType("exchange_tick", "close")
+	if err != nil || dataType == "numeric" {
+		return err
+	}
+	log.Info("Converting exchange tick prices and volumes to NUMERIC, this may take a while")
+	_, err = pg.db.Exec(alterExchangeTickDecimals)
+	return err
+}
+
+// MigratePowDataDecimals converts the prices of a pow_data table created with
+// VARCHAR price columns to NUMERIC
+func (pg *PgDb) MigratePowDataDecimals() error {
+	dataType, err := pg.columnType("pow_data", "coin_price")
+	if err != nil || dataType == "numeric" {
+		return err
+	}
+	log.Info("Converting PoW prices to NUMERIC")
+	_, err = pg.db.Exec(alterPowDataDecimals)
+	return err
+}
+
+// MigrateChainBlockFreshStake adds the fresh stake column to a chain_block
+// table created without it. The fresh stake of the blocks already recorded
+// stays unknown.
+func (pg *PgDb) MigrateChainBlockFreshStake() error {
+	_, err := pg.columnType("chain_block", "fresh_stake")
+	if err != sql.ErrNoRows {
+		return err
+	}
+	log.Info("Adding the fresh stake to the chain blocks")
+	_, err = pg.db.Exec(alterChainBlockFreshStake)
+	return err
+}
+
 func (pg *PgDb) DropAllTables() error {
 	// vsp_tick
 	if err := pg.dropIndex("vsp_tick_idx"); err != nil {
@@ -166,11 +594,104 @@ func (pg *PgDb) DropAllTables() error {
 		return err
 	}
 
+	// exchange_info
+	if err := pg.dropTable("exchange_info"); err != nil {
+		return err
+	}
+
+	// exchange_market
+	if err := pg.dropTable("exchange_market"); err != nil {
+		return err
+	}
+
+	// exchange_health
+	if err := pg.dropTable("exchange_health"); err != nil {
+		return err
+	}
+
+	// exchange_outage
+	if err := pg.dropTable("exchange_outage"); err != nil {
+		return err
+	}
+
 	// exchange
 	if err := pg.dropTable("exchange"); err != nil {
 		return err
 	}
 
+	// fiat_rate
+	if err := pg.dropTable("fiat_rate"); err != nil {
+		return err
+	}
+
+	// exchange_spread
+	if err := pg.dropTable("exchange_spread"); err != nil {
+		return err
+	}
+
+	// transfer_status
+	if err := pg.dropTable("transfer_status"); err != nil {
+		return err
+	}
+
+	// backfill_job
+	if err := pg.dropTable("backfill_job"); err != nil {
+		return err
+	}
+
+	// pow_cursor
+	if err := pg.dropTable("pow_cursor"); err != nil {
+		return err
+	}
+
+	// pow_centralization
+	if err := pg.dropTable("pow_centralization"); err != nil {
+		return err
+	}
+
+	// pool_share
+	if err := pg.dropTable("pool_share"); err != nil {
+		return err
+	}
+
+	// pool_share_alert
+	if err := pg.dropTable("pool_share_alert"); err != nil {
+		return err
+	}
+
+	// pow_block
+	if err := pg.dropIndex("pow_block_idx"); err != nil {
+		return err
+	}
+
+	if err := pg.dropTable("pow_block"); err != nil {
+		return err
+	}
+
+	// chain_block
+	if err := pg.dropTable("chain_block"); err != nil {
+		return err
+	}
+
+	// block_propagation
+	if err := pg.dropIndex("block_propagation_idx"); err != nil {
+		return err
+	}
+
+	if err := pg.dropTable("block_propagation"); err != nil {
+		return err
+	}
+
+	// vote_propagation
+	if err := pg.dropTable("vote_propagation"); err != nil {
+		return err
+	}
+
+	// chain_projection
+	if err := pg.dropTable("chain_projection"); err != nil {
+		return err
+	}
+
 	// pow_data
 	return pg.dropTable("pow_data")
 }
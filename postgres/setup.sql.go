@@ -12,13 +12,15 @@ const (
 			id SERIAL PRIMARY KEY,
 			exchange_id INT REFERENCES exchange(id) NOT NULL, 
 			interval INT NOT NULL,
-			high FLOAT NOT NULL,
-			low FLOAT NOT NULL,
-			open FLOAT NOT NULL,
-			close FLOAT NOT NULL,
-			volume FLOAT NOT NULL,
+			high NUMERIC NOT NULL,
+			low NUMERIC NOT NULL,
+			open NUMERIC NOT NULL,
+			close NUMERIC NOT NULL,
+			volume NUMERIC NOT NULL,
 			currency_pair TEXT NOT NULL,
-			time TIMESTAMPTZ NOT NULL
+			time TIMESTAMPTZ NOT NULL,
+			base_volume NUMERIC NOT NULL DEFAULT 0,
+			quote_volume NUMERIC NOT NULL DEFAULT 0
 		);
 		
 		CREATE UNIQUE INDEX IF NOT EXISTS exchange_tick_idx ON exchange_tick (exchange_id, interval, currency_pair, time);
@@ -56,15 +58,195 @@ const (
 			pool_hashrate VARCHAR(25),
 			workers INT,
 			network_difficulty FLOAT8,
-			coin_price VARCHAR(25),
-			btc_price VARCHAR(25),
+			coin_price NUMERIC,
+			btc_price NUMERIC,
 			source VARCHAR(25),
 			PRIMARY KEY (time, source)
 		);
+		COMMENT ON TABLE pow_data IS 'hashrates in H/s';
+
+		CREATE TABLE IF NOT EXISTS fiat_rate (
+			time TIMESTAMPTZ NOT NULL,
+			asset TEXT NOT NULL,
+			currency TEXT NOT NULL,
+			rate FLOAT8 NOT NULL,
+			source TEXT NOT NULL,
+			PRIMARY KEY (time, asset, currency, source)
+		);
+
+		CREATE TABLE IF NOT EXISTS exchange_spread (
+			time TIMESTAMPTZ NOT NULL,
+			interval INT NOT NULL,
+			currency_pair TEXT NOT NULL,
+			exchange_a TEXT NOT NULL,
+			exchange_b TEXT NOT NULL,
+			price_a FLOAT8 NOT NULL,
+			price_b FLOAT8 NOT NULL,
+			spread FLOAT8 NOT NULL,
+			streak INT NOT NULL,
+			sustained BOOLEAN NOT NULL,
+			PRIMARY KEY (interval, currency_pair, exchange_a, exchange_b, time)
+		);
+
+		CREATE TABLE IF NOT EXISTS exchange_info (
+			exchange_id INT PRIMARY KEY REFERENCES exchange(id),
+			status TEXT NOT NULL DEFAULT 'active',
+			intervals INT[] NOT NULL DEFAULT '{}',
+			notes TEXT NOT NULL DEFAULT '',
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS exchange_market (
+			exchange_id INT REFERENCES exchange(id) NOT NULL,
+			currency_pair TEXT NOT NULL,
+			base TEXT NOT NULL,
+			quote TEXT NOT NULL,
+			symbol TEXT NOT NULL DEFAULT '',
+			listed_at TIMESTAMPTZ,
+			delisted_at TIMESTAMPTZ,
+			notes TEXT NOT NULL DEFAULT '',
+			discovered_at TIMESTAMPTZ,
+			collect BOOLEAN NOT NULL DEFAULT TRUE,
+			PRIMARY KEY (exchange_id, currency_pair)
+		);
+
+		CREATE TABLE IF NOT EXISTS transfer_status (
+			exchange VARCHAR(25) NOT NULL,
+			time TIMESTAMPTZ NOT NULL,
+			deposit BOOLEAN NOT NULL,
+			withdrawal BOOLEAN NOT NULL,
+			PRIMARY KEY (exchange, time)
+		);
+
+		CREATE TABLE IF NOT EXISTS exchange_health (
+			exchange_id INT REFERENCES exchange(id) NOT NULL,
+			currency_pair TEXT NOT NULL,
+			last_success TIMESTAMPTZ,
+			last_failure TIMESTAMPTZ,
+			last_error TEXT NOT NULL DEFAULT '',
+			health TEXT NOT NULL DEFAULT 'healthy',
+			health_since TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (exchange_id, currency_pair)
+		);
+
+		CREATE TABLE IF NOT EXISTS exchange_outage (
+			id SERIAL PRIMARY KEY,
+			exchange_id INT REFERENCES exchange(id) NOT NULL,
+			currency_pair TEXT NOT NULL,
+			health TEXT NOT NULL,
+			started_at TIMESTAMPTZ NOT NULL,
+			ended_at TIMESTAMPTZ,
+			reason TEXT NOT NULL DEFAULT ''
+		);
+
+		CREATE TABLE IF NOT EXISTS backfill_job (
+			id SERIAL PRIMARY KEY,
+			exchange TEXT NOT NULL,
+			currency_pair TEXT NOT NULL,
+			interval INT NOT NULL,
+			start_time TIMESTAMPTZ NOT NULL,
+			end_time TIMESTAMPTZ NOT NULL,
+			checkpoint TIMESTAMPTZ NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			resumed_at TIMESTAMPTZ,
+			resumed_from TIMESTAMPTZ
+		);
+
+		CREATE TABLE IF NOT EXISTS pow_cursor (
+			source VARCHAR(25) PRIMARY KEY,
+			time INT8 NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS pow_centralization (
+			time TIMESTAMPTZ PRIMARY KEY,
+			network_hashrate FLOAT8 NOT NULL,
+			nakamoto_coefficient INT,
+			known_share FLOAT8 NOT NULL,
+			top_share FLOAT8 NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS pool_share (
+			time TIMESTAMPTZ NOT NULL,
+			source VARCHAR(25) NOT NULL,
+			hashrate FLOAT8 NOT NULL,
+			share FLOAT8 NOT NULL,
+			PRIMARY KEY (time, source)
+		);
+
+		CREATE TABLE IF NOT EXISTS pool_share_alert (
+			id SERIAL PRIMARY KEY,
+			kind VARCHAR(16) NOT NULL,
+			pools TEXT[] NOT NULL,
+			share FLOAT8 NOT NULL,
+			threshold FLOAT8 NOT NULL,
+			started_at TIMESTAMPTZ NOT NULL,
+			ended_at TIMESTAMPTZ
+		);
+
+		CREATE TABLE IF NOT EXISTS pow_block (
+			hash VARCHAR(64) PRIMARY KEY,
+			height INT8 NOT NULL,
+			time INT8 NOT NULL,
+			reward NUMERIC,
+			source VARCHAR(25) NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS pow_block_idx ON pow_block (source, time);
+
+		CREATE TABLE IF NOT EXISTS chain_block (
+			height INT8 PRIMARY KEY,
+			hash VARCHAR(64) NOT NULL,
+			time INT8 NOT NULL,
+			difficulty FLOAT8 NOT NULL,
+			network_hashrate FLOAT8 NOT NULL,
+			ticket_price NUMERIC NOT NULL,
+			pool_size INT8 NOT NULL,
+			voters INT4 NOT NULL,
+			fresh_stake INT4,
+			revocations INT4 NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS block_propagation (
+			hash VARCHAR(64) NOT NULL,
+			height INT8 NOT NULL,
+			node VARCHAR(64) NOT NULL,
+			seen_at TIMESTAMPTZ NOT NULL,
+			delay_ms INT8 NOT NULL,
+			PRIMARY KEY (hash, node)
+		);
+
+		CREATE INDEX IF NOT EXISTS block_propagation_idx ON block_propagation (seen_at);
+
+		CREATE TABLE IF NOT EXISTS vote_propagation (
+			hash VARCHAR(64) NOT NULL,
+			block_hash VARCHAR(64) NOT NULL,
+			block_height INT8 NOT NULL,
+			node VARCHAR(64) NOT NULL,
+			seen_at TIMESTAMPTZ NOT NULL,
+			delay_ms INT8 NOT NULL,
+			PRIMARY KEY (hash, node)
+		);
+
+		CREATE TABLE IF NOT EXISTS chain_projection (
+			retarget_height INT8 NOT NULL,
+			height INT8 NOT NULL,
+			time INT8 NOT NULL,
+			difficulty FLOAT8 NOT NULL,
+			projected_difficulty FLOAT8 NOT NULL,
+			ticket_price FLOAT8 NOT NULL,
+			projected_ticket_price FLOAT8,
+			actual_difficulty FLOAT8,
+			actual_ticket_price FLOAT8,
+			PRIMARY KEY (retarget_height, height)
+		);
 	`
 
 	dropTablesAndIndices = `
-		DROP INDEX IF EXISTS exchange_tick_idx, vsp_tick_idx;
-		DROP TABLE IF EXISTS vsp_tick, vsp, exchange_tick, exchange, pow_data;
+		DROP INDEX IF EXISTS exchange_tick_idx, vsp_tick_idx, pow_block_idx, block_propagation_idx;
+		DROP TABLE IF EXISTS vsp_tick, vsp, exchange_tick, exchange, pow_data, fiat_rate, exchange_spread, exchange_info, exchange_market, transfer_status, exchange_health, exchange_outage, backfill_job, pow_cursor, pow_centralization, pool_share, pool_share_alert, pow_block, chain_block, block_propagation, vote_propagation, chain_projection;
 	`
 )
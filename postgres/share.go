@@ -0,0 +1,203 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/raedahgroup/dcrextdata/pow/share"
+	"github.com/volatiletech/null"
+)
+
+const (
+	selectPoolHashrates = `SELECT source, time, network_hashrate, pool_hashrate FROM pow_data
+		WHERE time >= $1 ORDER BY time`
+
+	selectCentralization = `SELECT pow_centralization.time, network_hashrate, nakamoto_coefficient,
+		known_share, top_share, ARRAY_AGG(pool_share.source ORDER BY pool_share.share DESC, pool_share.source),
+		ARRAY_AGG(pool_share.hashrate ORDER BY pool_share.share DESC, pool_share.source),
+		ARRAY_AGG(pool_share.share ORDER BY pool_share.share DESC, pool_share.source)
+		FROM pow_centralization JOIN pool_share ON pool_share.time = pow_centralization.time
+		GROUP BY pow_centralization.time ORDER BY pow_centralization.time DESC OFFSET $1 LIMIT $2`
+
+	selectCentralizationCount = `SELECT COUNT(*) FROM pow_centralization`
+
+	insertCentralization = `INSERT INTO pow_centralization (time, network_hashrate, nakamoto_coefficient,
+		known_share, top_share) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (time) DO NOTHING`
+
+	insertPoolShare = `INSERT INTO pool_share (time, source, hashrate, share) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (time, source) DO NOTHING`
+
+	selectOpenShareAlerts = `SELECT id, kind, pools, share, threshold, started_at, ended_at
+		FROM pool_share_alert WHERE ended_at IS NULL ORDER BY started_at`
+
+	selectShareAlerts = `SELECT id, kind, pools, share, threshold, started_at, ended_at
+		FROM pool_share_alert ORDER BY started_at DESC, id DESC LIMIT $1`
+
+	insertShareAlert = `INSERT INTO pool_share_alert (kind, pools, share, threshold, started_at, ended_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	endShareAlert = `UPDATE pool_share_alert SET ended_at = $2 WHERE id = $1`
+)
+
+// PoolHashrates fetches the hashrates reported by the PoW sources from since,
+// oldest first
+func (pg *PgDb) PoolHashrates(ctx context.Context, since int64) ([]share.PoolHashrate, error) {
+	rows, err := pg.db.QueryContext(ctx, selectPoolHashrates, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hashrates []share.PoolHashrate
+	for rows.Next() {
+		var h share.PoolHashrate
+		var network, pool null.String
+		if err = rows.Scan(&h.Source, &h.Time, &network, &pool); err != nil {
+			return nil, err
+		}
+		if h.NetworkHashrate, err = parseHashrate(network); err != nil {
+			return nil, err
+		}
+		if h.PoolHashrate, err = parseHashrate(pool); err != nil {
+			return nil, err
+		}
+		hashrates = append(hashrates, h)
+	}
+	return hashrates, rows.Err()
+}
+
+// LastCentralization fetches the most recent computed pool shares, nil when
+// none were computed
+func (pg *PgDb) LastCentralization(ctx context.Context) (*share.Centralization, error) {
+	periods, err := pg.FetchCentralization(ctx, 0, 1)
+	if err != nil || len(periods) == 0 {
+		return nil, err
+	}
+	return &periods[0], nil
+}
+
+// FetchCentralization fetches a page of the computed pool shares, most recent
+// first
+func (pg *PgDb) FetchCentralization(ctx context.Context, offset int, limit int) ([]share.Centralization, error) {
+	rows, err := pg.db.QueryContext(ctx, selectCentralization, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := []share.Centralization{}
+	for rows.Next() {
+		var c share.Centralization
+		var coefficient sql.NullInt64
+		var sources pq.StringArray
+		var hashrates, shares pq.Float64Array
+		if err = rows.Scan(&c.Time, &c.NetworkHashrate, &coefficient, &c.KnownShare, &c.TopShare,
+			&sources, &hashrates, &shares); err != nil {
+			return nil, err
+		}
+		c.Time = c.Time.UTC()
+		c.NakamotoCoefficient = int(coefficient.Int64)
+		for i, source := range sources {
+			c.Shares = append(c.Shares, share.PoolShare{Source: source, Hashrate: hashrates[i], Share: shares[i]})
+		}
+		periods = append(periods, c)
+	}
+	return periods, rows.Err()
+}
+
+func (pg *PgDb) CentralizationCount(ctx context.Context) (int64, error) {
+	var count int64
+	err := pg.db.QueryRowContext(ctx, selectCentralizationCount).Scan(&count)
+	return count, err
+}
+
+// OpenShareAlerts fetches the pool share alerts that have not ended
+func (pg *PgDb) OpenShareAlerts(ctx context.Context) ([]share.Alert, error) {
+	return pg.queryShareAlerts(ctx, selectOpenShareAlerts)
+}
+
+// FetchShareAlerts fetches the limit most recent pool share alerts
+func (pg *PgDb) FetchShareAlerts(ctx context.Context, limit int) ([]share.Alert, error) {
+	return pg.queryShareAlerts(ctx, selectShareAlerts, limit)
+}
+
+func (pg *PgDb) queryShareAlerts(ctx context.Context, query string, args ...interface{}) ([]share.Alert, error) {
+	rows, err := pg.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []share.Alert{}
+	for rows.Next() {
+		var a share.Alert
+		var pools pq.StringArray
+		var end pq.NullTime
+		if err = rows.Scan(&a.ID, &a.Kind, &pools, &a.Share, &a.Threshold, &a.Start, &end); err != nil {
+			return nil, err
+		}
+		a.Pools = pools
+		a.Start = a.Start.UTC()
+		if end.Valid {
+			t := end.Time.UTC()
+			a.End = &t
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
+
+// StoreCentralization stores the computed pool shares and alerts in a single
+// transaction. Alerts without an ID are added, the others are ended.
+func (pg *PgDb) StoreCentralization(ctx context.Context, periods []share.Centralization, alerts []share.Alert) error {
+	txr, err := pg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range periods {
+		coefficient := sql.NullInt64{Int64: int64(c.NakamotoCoefficient), Valid: c.NakamotoCoefficient > 0}
+		if _, err = txr.ExecContext(ctx, insertCentralization, c.Time.UTC(), c.NetworkHashrate, coefficient,
+			c.KnownShare, c.TopShare); err != nil {
+			_ = txr.Rollback()
+			return err
+		}
+		for _, s := range c.Shares {
+			if _, err = txr.ExecContext(ctx, insertPoolShare, c.Time.UTC(), s.Source, s.Hashrate, s.Share); err != nil {
+				_ = txr.Rollback()
+				return err
+			}
+		}
+	}
+
+	for _, a := range alerts {
+		var end pq.NullTime
+		if a.End != nil {
+			end = pq.NullTime{Time: a.End.UTC(), Valid: true}
+		}
+		if a.ID == 0 {
+			_, err = txr.ExecContext(ctx, insertShareAlert, a.Kind, pq.StringArray(a.Pools), a.Share, a.Threshold,
+				a.Start.UTC(), end)
+		} else {
+			_, err = txr.ExecContext(ctx, endShareAlert, a.ID, end)
+		}
+		if err != nil {
+			_ = txr.Rollback()
+			return err
+		}
+	}
+
+	if err = txr.Commit(); err != nil {
+		return err
+	}
+	if len(periods) > 0 {
+		log.Infof("Stored pool shares of %d periods up to %s", len(periods),
+			periods[len(periods)-1].Time.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/raedahgroup/dcrextdata/exchanges/transfers"
+)
+
+const (
+	// Only changes are stored, a status is in effect from its time until
+	// the next status of the exchange
+	insertTransferStatus = `INSERT INTO transfer_status (exchange, time, deposit, withdrawal)
+		SELECT $1, $2, $3, $4 WHERE NOT EXISTS (
+			SELECT 1 FROM (SELECT deposit, withdrawal FROM transfer_status WHERE exchange = $1
+				ORDER BY time DESC LIMIT 1) latest
+			WHERE latest.deposit = $3 AND latest.withdrawal = $4)
+		ON CONFLICT DO NOTHING`
+
+	selectLatestTransferStatuses = `SELECT DISTINCT ON (exchange) exchange, time, deposit, withdrawal
+		FROM transfer_status ORDER BY exchange, time DESC`
+
+	selectTransferStatusHistory = `SELECT exchange, time, deposit, withdrawal FROM transfer_status
+		ORDER BY time DESC, exchange OFFSET $1 LIMIT $2`
+
+	selectTransferStatusCount = `SELECT COUNT(*) FROM transfer_status`
+)
+
+// StoreTransferStatus stores the transfer status of an exchange if it
+// differs from the last stored one and returns whether it was stored
+func (pg *PgDb) StoreTransferStatus(ctx context.Context, status transfers.Status) (bool, error) {
+	result, err := pg.db.ExecContext(ctx, insertTransferStatus, status.Exchange, status.Time.UTC(),
+		status.Deposit, status.Withdrawal)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// LatestTransferStatuses returns the current transfer status of every
+// monitored exchange
+func (pg *PgDb) LatestTransferStatuses(ctx context.Context) ([]transfers.StatusDto, error) {
+	return pg.queryTransferStatuses(ctx, selectLatestTransferStatuses)
+}
+
+// TransferStatusHistory returns a page of transfer status changes, newest
+// first
+func (pg *PgDb) TransferStatusHistory(ctx context.Context, offset int, limit int) ([]transfers.StatusDto, error) {
+	return pg.queryTransferStatuses(ctx, selectTransferStatusHistory, offset, limit)
+}
+
+// TransferStatusCount returns the number of stored transfer status changes
+func (pg *PgDb) TransferStatusCount(ctx context.Context) (int64, error) {
+	var count int64
+	err := pg.db.QueryRowContext(ctx, selectTransferStatusCount).Scan(&count)
+	return count, err
+}
+
+func (pg *PgDb) queryTransferStatuses(ctx context.Context, query string, args ...interface{}) ([]transfers.StatusDto, error) {
+	rows, err := pg.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statuses []transfers.StatusDto
+	for rows.Next() {
+		var status transfers.StatusDto
+		if err = rows.Scan(&status.Exchange, &status.Since, &status.Deposit, &status.Withdrawal); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, rows.Err()
+}
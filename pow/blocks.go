@@ -0,0 +1,332 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pow
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ericlagergren/decimal"
+	"github.com/raedahgroup/dcrextdata/helpers"
+)
+
+const (
+	LuxorBlocksUrl  = "http://mining.luxor.tech/API/DCR/blocks"
+	F2poolBlocksUrl = "https://api.f2pool.com/decred/blocks"
+	BtcBlocksUrl    = "https://pool.api.btc.com/v1/pool/blocks?coin_type=dcr"
+	PoolinBlocksUrl = "https://api-prod.poolin.com/api/public/v2/pool/blocks?coin_type=dcr"
+	ViabtcBlocksUrl = "https://www.viabtc.com/res/pool/DCR/block?page=1&limit=100"
+
+	// targetBlockTime is the time between Decred blocks the difficulty is
+	// adjusted towards
+	targetBlockTime = 5 * time.Minute
+
+	atomsPerCoin = 1e8
+)
+
+// BlockSource is a PoW source that also lists the recent blocks found by its
+// pool
+type BlockSource interface {
+	Pow
+	// CollectBlocks returns the recent blocks found by the pool, oldest
+	// first
+	CollectBlocks(ctx context.Context) ([]PoolBlock, error)
+}
+
+// PoolBlock is a block found by the pool Source. Time is in unix seconds and
+// Reward in DCR, nil when the pool does not report it.
+type PoolBlock struct {
+	Hash   string       `json:"hash"`
+	Height int64        `json:"height"`
+	Time   int64        `json:"time"`
+	Reward *decimal.Big `json:"reward"`
+	Source string       `json:"source"`
+}
+
+// PoolLuck compares the blocks found by a pool over a window with the blocks
+// expected from its average share of the network hashrate, in percent. Luck
+// is the found blocks as a percentage of the expected blocks, 0 when no block
+// was expected.
+type PoolLuck struct {
+	Source         string  `json:"source"`
+	Blocks         int64   `json:"blocks"`
+	Share          float64 `json:"share"`
+	ExpectedBlocks float64 `json:"expected_blocks"`
+	Luck           float64 `json:"luck"`
+}
+
+// Luck computes the luck of every pool that found blocks or held a share of
+// the hashrate over window, given the blocks found and the average share of
+// each pool. Pools are sorted by share, largest first.
+func Luck(blocks map[string]int64, shares map[string]float64, window time.Duration) []PoolLuck {
+	networkBlocks := float64(window) / float64(targetBlockTime)
+	sources := make(map[string]struct{}, len(shares))
+	for source := range blocks {
+		sources[source] = struct{}{}
+	}
+	for source := range shares {
+		sources[source] = struct{}{}
+	}
+
+	lucks := make([]PoolLuck, 0, len(sources))
+	for source := range sources {
+		luck := PoolLuck{
+			Source:         source,
+			Blocks:         blocks[source],
+			Share:          shares[source],
+			ExpectedBlocks: shares[source] / 100 * networkBlocks,
+		}
+		if luck.ExpectedBlocks > 0 {
+			luck.Luck = float64(luck.Blocks) / luck.ExpectedBlocks * 100
+		}
+		lucks = append(lucks, luck)
+	}
+	sort.Slice(lucks, func(i, j int) bool {
+		if lucks[i].Share == lucks[j].Share {
+			return lucks[i].Source < lucks[j].Source
+		}
+		return lucks[i].Share > lucks[j].Share
+	})
+	return lucks
+}
+
+// blocksResponse is a decoded blocks-found response of a source
+type blocksResponse interface {
+	// toBlocks parses the blocks of the response, in any order, and
+	// returns the blocks that could not be parsed
+	toBlocks() ([]PoolBlock, []skippedRecord)
+}
+
+// fetchBlocks requests the blocks-found list of the source into resp and
+// returns the valid blocks, oldest first
+func (in *CommonInfo) fetchBlocks(ctx context.Context, resp blocksResponse) ([]PoolBlock, error) {
+	if err := helpers.GetResponse(ctx, in.client, in.blocksUrl, resp); err != nil {
+		return nil, err
+	}
+
+	parsed, skipped := resp.toBlocks()
+	blocks := make([]PoolBlock, 0, len(parsed))
+	for _, block := range parsed {
+		if reason := invalidBlock(block); reason != "" {
+			skipped = append(skipped, skippedRecord{fmt.Sprint(block.Height), reason})
+			continue
+		}
+		block.Source = in.source
+		blocks = append(blocks, block)
+	}
+	if err := in.reportSkipped(len(blocks), skipped); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].Height < blocks[j].Height
+	})
+	return blocks, nil
+}
+
+// invalidBlock returns why a parsed block cannot be stored, or an empty
+// string when it can
+func invalidBlock(block PoolBlock) string {
+	if len(block.Hash) != 64 {
+		return fmt.Sprintf("invalid hash %q", block.Hash)
+	}
+	if _, err := hex.DecodeString(block.Hash); err != nil {
+		return fmt.Sprintf("invalid hash %q", block.Hash)
+	}
+	if block.Height <= 0 {
+		return "invalid height"
+	}
+	if block.Time <= 0 {
+		return "invalid time"
+	}
+	if block.Reward != nil && block.Reward.Sign() < 0 {
+		return "negative reward"
+	}
+	return ""
+}
+
+// parseAtoms parses an amount in atoms to DCR
+func parseAtoms(s string) (*decimal.Big, error) {
+	atoms, err := parseOptionalDecimal(s)
+	if err != nil || atoms == nil {
+		return atoms, err
+	}
+	return atoms.Quo(atoms, decimal.New(atomsPerCoin, 0)), nil
+}
+
+func (in *LuxorPow) CollectBlocks(ctx context.Context) ([]PoolBlock, error) {
+	return in.fetchBlocks(ctx, new(luxorBlocksResponse))
+}
+
+func (in *F2poolPow) CollectBlocks(ctx context.Context) ([]PoolBlock, error) {
+	return in.fetchBlocks(ctx, new(f2poolBlocksResponse))
+}
+
+func (in *BtcPow) CollectBlocks(ctx context.Context) ([]PoolBlock, error) {
+	return in.fetchBlocks(ctx, new(btcBlocksResponse))
+}
+
+func (in *PoolinPow) CollectBlocks(ctx context.Context) ([]PoolBlock, error) {
+	return in.fetchBlocks(ctx, new(poolinBlocksResponse))
+}
+
+func (in *ViabtcPow) CollectBlocks(ctx context.Context) ([]PoolBlock, error) {
+	return in.fetchBlocks(ctx, new(viabtcBlocksResponse))
+}
+
+type luxorBlock struct {
+	Height int64  `json:"height"`
+	Hash   string `json:"hash"`
+	Time   string `json:"time"`
+	Reward string `json:"reward"`
+}
+
+type luxorBlocksResponse struct {
+	Blocks []luxorBlock `json:"blocks"`
+}
+
+func (resp luxorBlocksResponse) toBlocks() ([]PoolBlock, []skippedRecord) {
+	blocks := make([]PoolBlock, 0, len(resp.Blocks))
+	var skipped []skippedRecord
+	for _, b := range resp.Blocks {
+		t, err := time.Parse(time.RFC3339, b.Time)
+		if err != nil {
+			skipped = append(skipped, skippedRecord{fmt.Sprint(b.Height), "invalid time"})
+			continue
+		}
+		reward, err := parseOptionalDecimal(b.Reward)
+		if err != nil {
+			skipped = append(skipped, skippedRecord{fmt.Sprint(b.Height), "reward: " + err.Error()})
+			continue
+		}
+		blocks = append(blocks, PoolBlock{Hash: b.Hash, Height: b.Height, Time: t.Unix(), Reward: reward})
+	}
+	return blocks, skipped
+}
+
+type f2poolBlock struct {
+	Height    int64       `json:"height"`
+	Hash      string      `json:"hash"`
+	Timestamp int64       `json:"timestamp"`
+	Reward    json.Number `json:"reward"`
+}
+
+type f2poolBlocksResponse struct {
+	Blocks []f2poolBlock `json:"blocks"`
+}
+
+func (resp f2poolBlocksResponse) toBlocks() ([]PoolBlock, []skippedRecord) {
+	blocks := make([]PoolBlock, 0, len(resp.Blocks))
+	var skipped []skippedRecord
+	for _, b := range resp.Blocks {
+		reward, err := parseOptionalDecimal(b.Reward.String())
+		if err != nil {
+			skipped = append(skipped, skippedRecord{fmt.Sprint(b.Height), "reward: " + err.Error()})
+			continue
+		}
+		blocks = append(blocks, PoolBlock{Hash: b.Hash, Height: b.Height, Time: b.Timestamp, Reward: reward})
+	}
+	return blocks, skipped
+}
+
+// btcBlock reports the reward in atoms
+type btcBlock struct {
+	Height    int64  `json:"height"`
+	Hash      string `json:"hash"`
+	CreatedAt int64  `json:"created_at"`
+	Rewards   string `json:"rewards"`
+}
+
+type btcBlocksResponse struct {
+	ErrNo int `json:"err_no"`
+	Data  struct {
+		List []btcBlock `json:"list"`
+	} `json:"data"`
+}
+
+func (resp btcBlocksResponse) toBlocks() ([]PoolBlock, []skippedRecord) {
+	if resp.ErrNo != 0 {
+		return nil, []skippedRecord{{"response", fmt.Sprintf("error %d", resp.ErrNo)}}
+	}
+	blocks := make([]PoolBlock, 0, len(resp.Data.List))
+	var skipped []skippedRecord
+	for _, b := range resp.Data.List {
+		reward, err := parseAtoms(b.Rewards)
+		if err != nil {
+			skipped = append(skipped, skippedRecord{fmt.Sprint(b.Height), "reward: " + err.Error()})
+			continue
+		}
+		blocks = append(blocks, PoolBlock{Hash: b.Hash, Height: b.Height, Time: b.CreatedAt, Reward: reward})
+	}
+	return blocks, skipped
+}
+
+type poolinBlock struct {
+	Height    int64  `json:"height"`
+	BlockHash string `json:"block_hash"`
+	Time      int64  `json:"time"`
+	Reward    string `json:"reward"`
+}
+
+type poolinBlocksResponse struct {
+	ErrNo  int    `json:"err_no"`
+	ErrMsg string `json:"err_msg"`
+	Data   struct {
+		List []poolinBlock `json:"list"`
+	} `json:"data"`
+}
+
+func (resp poolinBlocksResponse) toBlocks() ([]PoolBlock, []skippedRecord) {
+	if resp.ErrNo != 0 {
+		return nil, []skippedRecord{{"response", fmt.Sprintf("error %d: %s", resp.ErrNo, resp.ErrMsg)}}
+	}
+	blocks := make([]PoolBlock, 0, len(resp.Data.List))
+	var skipped []skippedRecord
+	for _, b := range resp.Data.List {
+		reward, err := parseOptionalDecimal(b.Reward)
+		if err != nil {
+			skipped = append(skipped, skippedRecord{fmt.Sprint(b.Height), "reward: " + err.Error()})
+			continue
+		}
+		blocks = append(blocks, PoolBlock{Hash: b.BlockHash, Height: b.Height, Time: b.Time, Reward: reward})
+	}
+	return blocks, skipped
+}
+
+type viabtcBlock struct {
+	Height    int64  `json:"height"`
+	BlockHash string `json:"block_hash"`
+	Time      int64  `json:"time"`
+	Reward    string `json:"reward"`
+}
+
+type viabtcBlocksResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Data []viabtcBlock `json:"data"`
+	} `json:"data"`
+}
+
+func (resp viabtcBlocksResponse) toBlocks() ([]PoolBlock, []skippedRecord) {
+	if resp.Code != 0 {
+		return nil, []skippedRecord{{"response", fmt.Sprintf("error %d: %s", resp.Code, resp.Message)}}
+	}
+	blocks := make([]PoolBlock, 0, len(resp.Data.Data))
+	var skipped []skippedRecord
+	for _, b := range resp.Data.Data {
+		reward, err := parseOptionalDecimal(b.Reward)
+		if err != nil {
+			skipped = append(skipped, skippedRecord{fmt.Sprint(b.Height), "reward: " + err.Error()})
+			continue
+		}
+		blocks = append(blocks, PoolBlock{Hash: b.BlockHash, Height: b.Height, Time: b.Time, Reward: reward})
+	}
+	return blocks, skipped
+}
@@ -6,7 +6,9 @@ package pow
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"runtime/debug"
 	"sync"
 	"time"
 )
@@ -17,22 +19,58 @@ var (
 		Luxor,
 		F2pool,
 		Btc,
+		Poolin,
+		Antpool,
+		Viabtc,
+		Uupool,
 	}
 )
 
 type PowDataStore interface {
 	AddPowData(context.Context, []PowData) error
 	LastPowEntryTime(source string) (time int64)
+	// PowCursor returns the time of the newest stored data of a source, 0
+	// when no cursor was stored
+	PowCursor(source string) (int64, error)
+	SetPowCursor(ctx context.Context, source string, time int64) error
+	// AddPowBlocks stores the blocks found by a pool, skipping the blocks
+	// that were already stored
+	AddPowBlocks(ctx context.Context, blocks []PoolBlock) error
 }
 
+// Collector collects the data of each PoW source on its own interval until
+// it is stopped
 type Collector struct {
-	pows   []Pow
-	period int64
-	store  PowDataStore
+	pows    []Pow
+	periods map[string]time.Duration
+	timeout time.Duration
+	blocks  bool
+	store   PowDataStore
 }
 
-func NewCollector(disabledPows []string, period int64, store PowDataStore) (*Collector, error) {
-	pows := make([]Pow, 0, len(availablePows)-len(disabledPows))
+// NewCollector creates a collector for the sources that are not disabled.
+// Sources are collected every period seconds, or at their interval in
+// sourcePeriods, and each collection is cancelled after timeout seconds. With
+// blocks set, the blocks found by the pools of the sources that list them are
+// collected along with the data.
+func NewCollector(disabledPows []string, period int64, sourcePeriods map[string]int64, timeout int64, blocks bool, store PowDataStore) (*Collector, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("PoW collection interval must be positive, got %d", period)
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("PoW collection timeout must be positive, got %d", timeout)
+	}
+	for source, sourcePeriod := range sourcePeriods {
+		if _, ok := PowConstructors[source]; !ok {
+			return nil, fmt.Errorf("Unknown PoW source %s", source)
+		}
+		if sourcePeriod <= 0 {
+			return nil, fmt.Errorf("PoW collection interval of %s must be positive, got %d", source, sourcePeriod)
+		}
+	}
+
+	pows := make([]Pow, 0, len(availablePows))
+	periods := make(map[string]time.Duration, len(availablePows))
 	disabledMap := make(map[string]struct{})
 	for _, pow := range disabledPows {
 		disabledMap[pow] = struct{}{}
@@ -44,64 +82,160 @@ func NewCollector(disabledPows []string, period int64, store PowDataStore) (*Col
 		}
 
 		if contructor, ok := PowConstructors[pow]; ok {
-			lastEntryTime := store.LastPowEntryTime(pow)
+			lastEntryTime, err := store.PowCursor(pow)
+			if err != nil {
+				return nil, err
+			}
+			if lastEntryTime == 0 {
+				// Sources collected before cursors were stored resume
+				// from their newest data
+				lastEntryTime = store.LastPowEntryTime(pow)
+			}
 			in, err := contructor(&http.Client{Timeout: 300 * time.Second}, lastEntryTime) // Consider if sharing a single client is better
 			if err != nil {
 				return nil, err
 			}
 			pows = append(pows, in)
+			periods[pow] = time.Duration(period) * time.Second
+			if sourcePeriod, ok := sourcePeriods[pow]; ok {
+				periods[pow] = time.Duration(sourcePeriod) * time.Second
+			}
 		}
 	}
 
+	if len(pows) == 0 {
+		return nil, fmt.Errorf("No PoW sources")
+	}
+
 	return &Collector{
-		pows:   pows,
-		period: period,
-		store:  store,
+		pows:    pows,
+		periods: periods,
+		timeout: time.Duration(timeout) * time.Second,
+		blocks:  blocks,
+		store:   store,
 	}, nil
 }
 
-func (pc *Collector) Collect(ctx context.Context, wg *sync.WaitGroup) {
+// LongestDelay returns the longest time a data point can take to be stored,
+// the longest source interval and the collection timeout
+func (pc *Collector) LongestDelay() time.Duration {
+	var longest time.Duration
+	for _, period := range pc.periods {
+		if period > longest {
+			longest = period
+		}
+	}
+	return longest + pc.timeout
+}
+
+// Run collects every source right away and then at its interval, with the
+// sources collected concurrently, until ctx is cancelled. The sources keep
+// the time of their last data between collections.
+func (pc *Collector) Run(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 	if ctx.Err() != nil {
 		return
 	}
 
-	runPowCollectors := func() {
-		log.Info("Triggering PoW collectors")
-		for _, in := range pc.pows {
-			func(powInfo Pow) {
-				/*lastEntryTime := pc.store.LastPowEntryTime(powInfo.Name())
-				lastStr := helpers.UnixTimeToString(in.LastUpdateTime())
-				if lastEntryTime == 0 {
-					lastStr = "never"
-				}
-				log.Infof("Starting PoW collector for %s, last collect time: %s", powInfo.Name(), lastStr)*/
+	sourcesWg := new(sync.WaitGroup)
+	for _, in := range pc.pows {
+		sourcesWg.Add(1)
+		go func(powInfo Pow) {
+			defer sourcesWg.Done()
+
+			period := pc.periods[powInfo.Name()]
+			log.Infof("Collecting PoW data from %s every %v", powInfo.Name(), period)
+			blockSource, hasBlocks := powInfo.(BlockSource)
+			hasBlocks = hasBlocks && pc.blocks
+			pc.collect(ctx, powInfo)
+			if hasBlocks {
+				pc.collectBlocks(ctx, blockSource)
+			}
 
-				data, err := powInfo.Collect(ctx)
-				if err != nil {
-					log.Error(err)
-				}
-				err = pc.store.AddPowData(ctx, data)
-				if err != nil {
-					log.Error(err)
+			ticker := time.NewTicker(period)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					pc.collect(ctx, powInfo)
+					if hasBlocks {
+						pc.collectBlocks(ctx, blockSource)
+					}
+				case <-ctx.Done():
+					return
 				}
-			}(in)
-		}
+			}
+		}(in)
 	}
+	sourcesWg.Wait()
+	log.Infof("Stopped PoW collectors")
+}
 
-	runPowCollectors()
+// collect stores the new data of a source and advances its cursor, giving up
+// after pc.timeout. A panicking source is recovered from so that it cannot
+// stop the others.
+func (pc *Collector) collect(ctx context.Context, powInfo Pow) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("Recovered from panic in %s PoW collector: %v\n%s", powInfo.Name(), r, debug.Stack())
+		}
+	}()
+
+	collectCtx, cancel := context.WithTimeout(ctx, pc.timeout)
+	defer cancel()
+
+	data, err := powInfo.Collect(collectCtx)
+	if err != nil {
+		// A timed out request fails with the deadline wrapped in its own error
+		if collectCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			log.Errorf("%s PoW collection did not complete within %v", powInfo.Name(), pc.timeout)
+		} else if ctx.Err() == nil {
+			log.Errorf("%s: %v", powInfo.Name(), err)
+		}
+		return
+	}
+	if len(data) == 0 {
+		log.Debugf("No new PoW data from %s", powInfo.Name())
+		return
+	}
+	if err = pc.store.AddPowData(collectCtx, data); err != nil {
+		log.Error(err)
+		return
+	}
 
-	/*ticker := time.NewTicker(time.Duration(pc.period) * time.Second)
-	defer ticker.Stop()
+	last := data[len(data)-1].Time
+	powInfo.SetLastUpdateTime(last)
+	if err = pc.store.SetPowCursor(collectCtx, powInfo.Name(), last); err != nil {
+		log.Errorf("Unable to store the PoW cursor of %s: %v", powInfo.Name(), err)
+	}
+}
 
-	for {
-		select {
-		case <-ticker.C:
-			runPowCollectors()
-		case <-ctx.Done():
-			log.Infof("Stopping collectors")
-			return
+// collectBlocks stores the blocks found by the pool of a source, giving up
+// after pc.timeout
+func (pc *Collector) collectBlocks(ctx context.Context, source BlockSource) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("Recovered from panic in %s PoW block collector: %v\n%s", source.Name(), r, debug.Stack())
 		}
+	}()
 
-	}*/
+	collectCtx, cancel := context.WithTimeout(ctx, pc.timeout)
+	defer cancel()
+
+	blocks, err := source.CollectBlocks(collectCtx)
+	if err != nil {
+		if collectCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			log.Errorf("%s PoW block collection did not complete within %v", source.Name(), pc.timeout)
+		} else if ctx.Err() == nil {
+			log.Errorf("%s blocks: %v", source.Name(), err)
+		}
+		return
+	}
+	if len(blocks) == 0 {
+		log.Debugf("No blocks found by %s", source.Name())
+		return
+	}
+	if err = pc.store.AddPowBlocks(collectCtx, blocks); err != nil {
+		log.Error(err)
+	}
 }
@@ -0,0 +1,289 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pow
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryStore struct {
+	mtx     sync.Mutex
+	data    []PowData
+	cursors map[string]int64
+	blocks  []PoolBlock
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{cursors: make(map[string]int64)}
+}
+
+func (s *memoryStore) AddPowData(ctx context.Context, data []PowData) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.data = append(s.data, data...)
+	return nil
+}
+
+func (s *memoryStore) LastPowEntryTime(source string) int64 {
+	return 0
+}
+
+func (s *memoryStore) PowCursor(source string) (int64, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.cursors[source], nil
+}
+
+func (s *memoryStore) SetPowCursor(ctx context.Context, source string, time int64) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.cursors[source] = time
+	return nil
+}
+
+func (s *memoryStore) AddPowBlocks(ctx context.Context, blocks []PoolBlock) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.blocks = append(s.blocks, blocks...)
+	return nil
+}
+
+// testPow is a source whose collections call collect, returning one data
+// point newer than its cursor unless collect fails
+type testPow struct {
+	name    string
+	mtx     sync.Mutex
+	calls   int
+	last    int64
+	collect func(ctx context.Context) error
+}
+
+func (p *testPow) Collect(ctx context.Context) ([]PowData, error) {
+	p.mtx.Lock()
+	p.calls++
+	last := p.last
+	p.mtx.Unlock()
+	if p.collect != nil {
+		if err := p.collect(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return []PowData{{Time: last + 60, Source: p.name}}, nil
+}
+
+func (p *testPow) LastUpdateTime() int64 {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.last
+}
+
+func (p *testPow) SetLastUpdateTime(last int64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.last = last
+}
+
+func (p *testPow) Name() string { return p.name }
+
+func (p *testPow) HashrateUnits() (network, pool HashrateUnit) {
+	return HashesPerSecond, HashesPerSecond
+}
+
+func (p *testPow) collections() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.calls
+}
+
+// testBlockPow is a source that also lists the blocks of its pool
+type testBlockPow struct {
+	testPow
+	blockCalls int
+}
+
+func (p *testBlockPow) CollectBlocks(ctx context.Context) ([]PoolBlock, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.blockCalls++
+	return []PoolBlock{{Source: p.name, Height: int64(p.blockCalls)}}, nil
+}
+
+// runCollector runs pc until the duration passed and returns once Run
+// returned
+func runCollector(t *testing.T, pc *Collector, duration time.Duration) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	go pc.Run(ctx, wg)
+	time.Sleep(duration)
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after the cancellation")
+	}
+}
+
+func TestCollectorIntervals(t *testing.T) {
+	fast := &testPow{name: "fast"}
+	slow := &testPow{name: "slow"}
+	store := newMemoryStore()
+	pc := &Collector{
+		pows: []Pow{fast, slow},
+		periods: map[string]time.Duration{
+			"fast": 20 * time.Millisecond,
+			"slow": time.Hour,
+		},
+		timeout: time.Second,
+		store:   store,
+	}
+	runCollector(t, pc, 110*time.Millisecond)
+
+	// Both are collected right away, then fast every 20ms
+	if calls := slow.collections(); calls != 1 {
+		t.Errorf("slow source collected %d times, expected 1", calls)
+	}
+	if calls := fast.collections(); calls < 4 || calls > 7 {
+		t.Errorf("fast source collected %d times, expected about 6", calls)
+	}
+	if store.cursors["fast"] != int64(fast.collections())*60 || store.cursors["slow"] != 60 {
+		t.Errorf("cursors %v do not match the stored data", store.cursors)
+	}
+	if len(store.data) != fast.collections()+slow.collections() {
+		t.Errorf("stored %d data points, expected %d", len(store.data), fast.collections()+slow.collections())
+	}
+}
+
+func TestCollectorFailures(t *testing.T) {
+	hanging := &testPow{name: "hanging", collect: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	panicking := &testPow{name: "panicking", collect: func(context.Context) error {
+		var m map[string]int
+		m["panic"]++
+		return nil
+	}}
+	healthy := &testBlockPow{testPow: testPow{name: "healthy"}}
+	store := newMemoryStore()
+	pc := &Collector{
+		pows: []Pow{hanging, panicking, healthy},
+		periods: map[string]time.Duration{
+			"hanging":   20 * time.Millisecond,
+			"panicking": 20 * time.Millisecond,
+			"healthy":   20 * time.Millisecond,
+		},
+		timeout: 10 * time.Millisecond,
+		blocks:  true,
+		store:   store,
+	}
+	runCollector(t, pc, 110*time.Millisecond)
+
+	// The hanging source times out every time and the panicking one is
+	// recovered from, neither stops the collections
+	if hanging.collections() < 3 || panicking.collections() < 3 {
+		t.Errorf("hanging collected %d times and panicking %d times, expected both to go on",
+			hanging.collections(), panicking.collections())
+	}
+	if _, ok := store.cursors["hanging"]; ok {
+		t.Error("the cursor of the hanging source was stored")
+	}
+	if _, ok := store.cursors["panicking"]; ok {
+		t.Error("the cursor of the panicking source was stored")
+	}
+	if healthy.collections() < 3 || len(store.blocks) != healthy.blockCalls {
+		t.Errorf("healthy collected %d times with %d of %d block lists stored", healthy.collections(),
+			len(store.blocks), healthy.blockCalls)
+	}
+}
+
+func TestCollectorBlocksDisabled(t *testing.T) {
+	source := &testBlockPow{testPow: testPow{name: "pool"}}
+	store := newMemoryStore()
+	pc := &Collector{
+		pows:    []Pow{source},
+		periods: map[string]time.Duration{"pool": time.Hour},
+		timeout: time.Second,
+		store:   store,
+	}
+	runCollector(t, pc, 20*time.Millisecond)
+	if source.blockCalls != 0 || len(store.blocks) != 0 {
+		t.Errorf("blocks were collected %d times without being enabled", source.blockCalls)
+	}
+}
+
+func TestCollectorStopsBeforeStart(t *testing.T) {
+	source := &testPow{name: "source"}
+	pc := &Collector{
+		pows:    []Pow{source},
+		periods: map[string]time.Duration{"source": time.Hour},
+		timeout: time.Second,
+		store:   newMemoryStore(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	pc.Run(ctx, wg)
+	wg.Wait()
+	if source.collections() != 0 {
+		t.Errorf("collected %d times after the cancellation", source.collections())
+	}
+}
+
+func TestNewCollector(t *testing.T) {
+	store := newMemoryStore()
+	store.cursors[Luxor] = 1560000000
+	pc, err := NewCollector([]string{Coinmine, F2pool, Btc, Poolin, Antpool, Viabtc}, 300,
+		map[string]int64{Uupool: 1800}, 60, false, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pc.pows) != 2 {
+		t.Fatalf("%d sources, expected luxor and uupool", len(pc.pows))
+	}
+	if pc.pows[0].LastUpdateTime() != 1560000000 {
+		t.Errorf("luxor resumes from %d, expected its cursor", pc.pows[0].LastUpdateTime())
+	}
+	if pc.periods[Luxor] != 300*time.Second || pc.periods[Uupool] != 1800*time.Second {
+		t.Errorf("unexpected periods %v", pc.periods)
+	}
+	if delay := pc.LongestDelay(); delay != 1860*time.Second {
+		t.Errorf("longest delay %v, expected the uupool interval and the timeout", delay)
+	}
+
+	// Unknown and repeated disabled sources are ignored
+	disabled := append(append([]string{}, availablePows...), "unknown", Luxor)
+	if _, err = NewCollector(disabled, 300, nil, 60, false, store); err == nil {
+		t.Error("expected an error without sources")
+	}
+
+	invalid := []struct {
+		name          string
+		period        int64
+		sourcePeriods map[string]int64
+		timeout       int64
+	}{
+		{"no period", 0, nil, 60},
+		{"no timeout", 300, nil, 0},
+		{"unknown source", 300, map[string]int64{"unknown": 60}, 60},
+		{"source without period", 300, map[string]int64{Luxor: 0}, 60},
+	}
+	for _, test := range invalid {
+		if _, err := NewCollector(nil, test.period, test.sourcePeriods, test.timeout, false, store); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ericlagergren/decimal"
+	"github.com/raedahgroup/dcrextdata/helpers"
+)
+
+// skippedRecord is a record of a source response that could not be parsed,
+// identified by key, usually its time
+type skippedRecord struct {
+	key    string
+	reason string
+}
+
+func (r skippedRecord) String() string {
+	return fmt.Sprintf("%s (%s)", r.key, r.reason)
+}
+
+// sourceData is a parsed source record, with its hashrates in the units the
+// source reports them in instead of the H/s of the PowData hashrates. Sources
+// that send the unit with the hashrate set it in units.
+type sourceData struct {
+	PowData
+	networkHashrate float64
+	poolHashrate    float64
+	units           hashrateUnits
+}
+
+// powResponse is a decoded source response
+type powResponse interface {
+	// toPowData parses the records of the response, in any order, and
+	// returns the records that could not be parsed. Sources that only
+	// report their current state time it at now.
+	toPowData(now int64) ([]sourceData, []skippedRecord)
+}
+
+// fetch requests the source into resp and returns the parsed records newer
+// than the source's cursor, oldest first, with their hashrates in H/s.
+// Skipped records are logged, and fail the fetch when no record could be
+// parsed. The cursor is left for the collector to advance once the records
+// are stored.
+func (in *CommonInfo) fetch(ctx context.Context, resp powResponse) ([]PowData, error) {
+	if err := helpers.GetResponse(ctx, in.client, in.baseUrl, resp); err != nil {
+		return nil, err
+	}
+
+	data, skipped := resp.toPowData(time.Now().Unix())
+	if err := in.reportSkipped(len(data), skipped); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(data, func(i, j int) bool {
+		return data[i].Time < data[j].Time
+	})
+	newData := make([]PowData, 0, len(data))
+	for _, record := range data {
+		if record.Time > in.lastUpdate {
+			d := in.units.normalize(record)
+			d.Source = in.source
+			newData = append(newData, d)
+		}
+	}
+	return newData, nil
+}
+
+// reportSkipped logs the records of a response that were skipped, and
+// returns an error when none of the records could be parsed
+func (in *CommonInfo) reportSkipped(parsed int, skipped []skippedRecord) error {
+	if len(skipped) == 0 {
+		return nil
+	}
+	reasons := make([]string, len(skipped))
+	for i, record := range skipped {
+		reasons[i] = record.String()
+	}
+	if parsed == 0 {
+		return fmt.Errorf("%s: none of %d records could be parsed: %s", in.source, len(skipped),
+			strings.Join(reasons, ", "))
+	}
+	log.Warnf("%s: skipped %d of %d records: %s", in.source, len(skipped), parsed+len(skipped),
+		strings.Join(reasons, ", "))
+	return nil
+}
+
+// parseOptionalDecimal parses a price that sources may leave empty, which is
+// returned as nil
+func parseOptionalDecimal(s string) (*decimal.Big, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	return helpers.ParseDecimal(s)
+}
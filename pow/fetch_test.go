@@ -0,0 +1,244 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pow
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ericlagergren/decimal"
+	"github.com/raedahgroup/dcrextdata/internal/fixtures"
+)
+
+// urlOverrider is implemented by the sources built on CommonInfo
+type urlOverrider interface {
+	overrideUrls(url string)
+}
+
+// overrideUrls points the data and block requests of the source at url
+func (in *CommonInfo) overrideUrls(url string) {
+	in.baseUrl = url
+	in.blocksUrl = url
+}
+
+// fixtureSource returns a source of the given constructor that is served
+// the fixture file
+func fixtureSource(t *testing.T, constructor func(*http.Client, int64) (Pow, error), fixture string, lastUpdate int64) Pow {
+	server, _ := fixtures.Server(t, map[string]string{"": fixture})
+	source, err := constructor(server.Client(), lastUpdate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	overrider, ok := source.(urlOverrider)
+	if !ok {
+		t.Fatalf("%s does not embed CommonInfo", source.Name())
+	}
+	overrider.overrideUrls(server.URL)
+	return source
+}
+
+func TestFetchOrdersAndSkips(t *testing.T) {
+	tests := []struct {
+		name        string
+		constructor func(*http.Client, int64) (Pow, error)
+		fixture     string
+		lastUpdate  int64
+		times       []int64
+	}{
+		// The unparseable time and price are skipped, empty prices are nil
+		{"luxor", NewLuxor, "luxor.json", 0, []int64{1559347200, 1559347800}},
+		{"luxor after cursor", NewLuxor, "luxor.json", 1559347200, []int64{1559347800}},
+		{"f2pool", NewF2pool, "f2pool.json", 0, []int64{1559347200, 1559350800, 1559354400}},
+		{"f2pool after cursor", NewF2pool, "f2pool.json", 1559350800, []int64{1559354400}},
+		{"f2pool up to date", NewF2pool, "f2pool.json", 1559354400, nil},
+	}
+	for _, test := range tests {
+		source := fixtureSource(t, test.constructor, test.fixture, test.lastUpdate)
+		data, err := source.Collect(context.Background())
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if len(data) != len(test.times) {
+			t.Errorf("%s: got %d records, expected %d", test.name, len(data), len(test.times))
+			continue
+		}
+		for i, d := range data {
+			if d.Time != test.times[i] {
+				t.Errorf("%s: record %d at %d, expected %d", test.name, i, d.Time, test.times[i])
+			}
+			if d.Source != source.Name() {
+				t.Errorf("%s: record %d from %q", test.name, i, d.Source)
+			}
+		}
+		if source.LastUpdateTime() != test.lastUpdate {
+			t.Errorf("%s: cursor moved to %d before the data was stored", test.name, source.LastUpdateTime())
+		}
+	}
+}
+
+func TestFetchLuxorPrices(t *testing.T) {
+	source := fixtureSource(t, NewLuxor, "luxor.json", 0)
+	data, err := source.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0].CoinPrice != nil || data[0].BtcPrice != nil {
+		t.Errorf("expected no prices, got %v and %v", data[0].CoinPrice, data[0].BtcPrice)
+	}
+	if data[1].CoinPrice.String() != "25.1" {
+		t.Errorf("coin price: got %v, expected 25.1", data[1].CoinPrice)
+	}
+}
+
+func TestFetchBtc(t *testing.T) {
+	source := fixtureSource(t, NewBtc, "btc.json", 0)
+	data, err := source.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 || data[0].NetworkHashrate != 2500000000000000 || data[0].PoolHashrate != 250000000000000 {
+		t.Errorf("unexpected data %+v", data)
+	}
+
+	source = fixtureSource(t, NewBtc, "btc_error.json", 0)
+	if _, err = source.Collect(context.Background()); err == nil {
+		t.Error("expected an error for an unparseable response")
+	}
+}
+
+func TestFetchPools(t *testing.T) {
+	tests := []struct {
+		name            string
+		constructor     func(*http.Client, int64) (Pow, error)
+		fixture         string
+		time            int64
+		networkHashrate int64
+		poolHashrate    float64
+		workers         int64
+	}{
+		// Time 0 is a source that times its state at the request
+		{"poolin", NewPoolin, "poolin.json", 0, 512500000000000000, 120250000000000, 1530},
+		{"antpool", NewAntpool, "antpool.json", 0, 512500000000000000, 98000500000000000, 2210},
+		{"viabtc", NewViabtc, "viabtc.json", 0, 512500000000000000, 45000000000000000, 870},
+		{"uupool", NewUupool, "uupool.json", 1559347200, 512500000000000000, 15000000000000000, 320},
+	}
+	for _, test := range tests {
+		source := fixtureSource(t, test.constructor, test.fixture, 0)
+		data, err := source.Collect(context.Background())
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if len(data) != 1 {
+			t.Errorf("%s: got %d records, expected 1", test.name, len(data))
+			continue
+		}
+		d := data[0]
+		if test.time != 0 && d.Time != test.time || d.Time <= 0 {
+			t.Errorf("%s: record at %d, expected %d", test.name, d.Time, test.time)
+		}
+		if d.NetworkHashrate != test.networkHashrate || d.PoolHashrate != test.poolHashrate {
+			t.Errorf("%s: hashrates %d and %v, expected %d and %v", test.name,
+				d.NetworkHashrate, d.PoolHashrate, test.networkHashrate, test.poolHashrate)
+		}
+		if d.Workers != test.workers || d.NetworkDifficulty != 4214012873.21 {
+			t.Errorf("%s: unexpected data %+v", test.name, d)
+		}
+		if d.Source != test.name {
+			t.Errorf("%s: record from %q", test.name, d.Source)
+		}
+	}
+
+	source := fixtureSource(t, NewPoolin, "poolin_error.json", 0)
+	if _, err := source.Collect(context.Background()); err == nil {
+		t.Error("expected an error for a poolin error response")
+	}
+
+	source = fixtureSource(t, NewUupool, "uupool.json", 1559347200)
+	data, err := source.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 0 {
+		t.Errorf("expected no data at the cursor, got %+v", data)
+	}
+}
+
+func TestFetchBlocks(t *testing.T) {
+	tests := []struct {
+		name        string
+		constructor func(*http.Client, int64) (Pow, error)
+		fixture     string
+		heights     []int64
+		reward      string
+	}{
+		// The block without a valid hash is skipped
+		{"luxor", NewLuxor, "luxor_blocks.json", []int64{350001, 350002}, ""},
+		{"f2pool", NewF2pool, "f2pool_blocks.json", []int64{350001, 350002}, "15.21"},
+		// btc reports rewards in atoms
+		{"btc", NewBtc, "btc_blocks.json", []int64{350001, 350002}, "15.21"},
+		{"poolin", NewPoolin, "poolin_blocks.json", []int64{350001, 350002}, "15.21"},
+		// The block without a time is skipped
+		{"viabtc", NewViabtc, "viabtc_blocks.json", []int64{350002}, "15.21"},
+	}
+	for _, test := range tests {
+		source := fixtureSource(t, test.constructor, test.fixture, 0)
+		blockSource, ok := source.(BlockSource)
+		if !ok {
+			t.Errorf("%s does not list its blocks", test.name)
+			continue
+		}
+		blocks, err := blockSource.CollectBlocks(context.Background())
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if len(blocks) != len(test.heights) {
+			t.Errorf("%s: got %d blocks, expected %d", test.name, len(blocks), len(test.heights))
+			continue
+		}
+		for i, block := range blocks {
+			if block.Height != test.heights[i] || block.Source != test.name || block.Time <= 0 {
+				t.Errorf("%s: unexpected block %d %+v", test.name, i, block)
+			}
+		}
+		// The first block of the luxor fixture has no reward
+		if reward := blocks[0].Reward; test.reward == "" && reward != nil ||
+			test.reward != "" && (reward == nil || reward.Cmp(mustDecimal(t, test.reward)) != 0) {
+			t.Errorf("%s: reward %v, expected %q", test.name, reward, test.reward)
+		}
+	}
+}
+
+func TestLuck(t *testing.T) {
+	// A day has 288 blocks, of which a 25% pool is expected to find 72
+	lucks := Luck(map[string]int64{"a": 90, "c": 3}, map[string]float64{"a": 25, "b": 10}, 24*time.Hour)
+	expected := []PoolLuck{
+		{Source: "a", Blocks: 90, Share: 25, ExpectedBlocks: 72, Luck: 125},
+		{Source: "b", Blocks: 0, Share: 10, ExpectedBlocks: 28.8, Luck: 0},
+		{Source: "c", Blocks: 3},
+	}
+	if len(lucks) != len(expected) {
+		t.Fatalf("got %+v", lucks)
+	}
+	for i, luck := range lucks {
+		if luck.Source != expected[i].Source || luck.Blocks != expected[i].Blocks ||
+			math.Abs(luck.ExpectedBlocks-expected[i].ExpectedBlocks) > 1e-9 || math.Abs(luck.Luck-expected[i].Luck) > 1e-9 {
+			t.Errorf("luck %d is %+v, expected %+v", i, luck, expected[i])
+		}
+	}
+}
+
+func mustDecimal(t *testing.T, s string) *decimal.Big {
+	d, ok := new(decimal.Big).SetString(s)
+	if !ok {
+		t.Fatalf("invalid decimal %q", s)
+	}
+	return d
+}
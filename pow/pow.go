@@ -7,10 +7,6 @@ package pow
 import (
 	"context"
 	"net/http"
-	"strconv"
-	"time"
-
-	"github.com/raedahgroup/dcrextdata/helpers"
 )
 
 const (
@@ -25,6 +21,31 @@ const (
 
 	Btc    = "btc"
 	BtcUrl = "https://pool.api.btc.com/v1/pool/status"
+
+	Poolin    = "poolin"
+	PoolinUrl = "https://api-prod.poolin.com/api/public/v2/pool/stats?coin_type=dcr"
+
+	Antpool    = "antpool"
+	AntpoolUrl = "https://www.antpool.com/api/poolStats.htm?coinType=DCR"
+
+	Viabtc    = "viabtc"
+	ViabtcUrl = "https://www.viabtc.com/res/pool/state"
+
+	Uupool    = "uupool"
+	UupoolUrl = "https://uupool.cn/api/getPoolInfo.php?coin=dcr"
+)
+
+var (
+	luxorUnits  = hashrateUnits{network: HashesPerSecond, pool: HashesPerSecond}
+	f2poolUnits = hashrateUnits{network: HashesPerSecond, pool: HashesPerSecond}
+	// Coinmine runs MPOS, which keeps pool hashrates in KH/s
+	coinmineUnits = hashrateUnits{network: HashesPerSecond, pool: KiloHashesPerSecond}
+	btcUnits      = hashrateUnits{network: PetaHashesPerSecond, pool: PetaHashesPerSecond}
+	// Poolin sends the unit with every hashrate
+	poolinUnits  = hashrateUnits{network: HashesPerSecond, pool: HashesPerSecond}
+	antpoolUnits = hashrateUnits{network: TeraHashesPerSecond, pool: TeraHashesPerSecond}
+	viabtcUnits  = hashrateUnits{network: HashesPerSecond, pool: HashesPerSecond}
+	uupoolUnits  = hashrateUnits{network: HashesPerSecond, pool: GigaHashesPerSecond}
 )
 
 var PowConstructors = map[string]func(*http.Client, int64) (Pow, error){
@@ -32,253 +53,194 @@ var PowConstructors = map[string]func(*http.Client, int64) (Pow, error){
 	F2pool:   NewF2pool,
 	Coinmine: NewCoinmine,
 	Btc:      NewBtc,
+	Poolin:   NewPoolin,
+	Antpool:  NewAntpool,
+	Viabtc:   NewViabtc,
+	Uupool:   NewUupool,
 }
 
 type Pow interface {
+	// Collect returns the data of the source newer than LastUpdateTime,
+	// oldest first
 	Collect(ctx context.Context) ([]PowData, error)
 	LastUpdateTime() int64
+	// SetLastUpdateTime advances the cursor of the source once its data is
+	// stored
+	SetLastUpdateTime(int64)
 	Name() string
+	// HashrateUnits returns the units the source reports the network and
+	// the pool hashrate in, which Collect converts to H/s
+	HashrateUnits() (network, pool HashrateUnit)
 }
 
 type CommonInfo struct {
 	client     *http.Client
 	lastUpdate int64
 	baseUrl    string
+	source     string
+	units      hashrateUnits
+	// blocksUrl lists the blocks found by the pool of a BlockSource
+	blocksUrl string
+}
+
+func newCommonInfo(client *http.Client, lastUpdate int64, baseUrl, source string, units hashrateUnits) (CommonInfo, error) {
+	if client == nil {
+		return CommonInfo{}, nilClientError
+	}
+	return CommonInfo{
+		client:     client,
+		lastUpdate: lastUpdate,
+		baseUrl:    baseUrl,
+		source:     source,
+		units:      units,
+	}, nil
 }
 
 func (in *CommonInfo) LastUpdateTime() int64 {
 	return in.lastUpdate
 }
 
-type LuxorPow struct {
-	CommonInfo
+func (in *CommonInfo) SetLastUpdateTime(lastUpdate int64) {
+	in.lastUpdate = lastUpdate
 }
 
-func NewLuxor(client *http.Client, lastUpdate int64) (Pow, error) {
-	if client == nil {
-		return nil, nilClientError
-	}
-	return &LuxorPow{
-		CommonInfo: CommonInfo{
-			client:     client,
-			lastUpdate: lastUpdate,
-			baseUrl:    LuxorUrl,
-		},
-	}, nil
+func (in *CommonInfo) Name() string {
+	return in.source
 }
 
-func (in *LuxorPow) Collect(ctx context.Context) ([]PowData, error) {
-	res := new(luxorAPIResponse)
-	err := helpers.GetResponse(ctx, in.client, in.baseUrl, res)
+func (in *CommonInfo) HashrateUnits() (network, pool HashrateUnit) {
+	return in.units.network, in.units.pool
+}
+
+type LuxorPow struct {
+	CommonInfo
+}
 
+func NewLuxor(client *http.Client, lastUpdate int64) (Pow, error) {
+	common, err := newCommonInfo(client, lastUpdate, LuxorUrl, Luxor, luxorUnits)
 	if err != nil {
 		return nil, err
 	}
-
-	result := in.fetch(res, in.lastUpdate)
-	in.lastUpdate = result[len(result)-1].Time
-
-	return result, nil
-}
-
-func (LuxorPow) fetch(res *luxorAPIResponse, start int64) []PowData {
-	data := make([]PowData, 0, len(res.GlobalStats))
-	for _, j := range res.GlobalStats {
-		t, _ := time.Parse(time.RFC3339, j.Time)
-
-		if t.Unix() < start {
-			continue
-		}
-
-		coinPrice, err := strconv.ParseFloat(j.CoinPrice, 64)
-		if err != nil {
-			continue
-		}
-		btcPrice, err := strconv.ParseFloat(j.BtcPrice, 64)
-		if err != nil {
-			continue
-		}
-
-		data = append(data, PowData{
-			Time:              t.Unix(),
-			NetworkHashrate:   j.NetworkHashrate,
-			PoolHashrate:      j.PoolHashrate,
-			Workers:           j.Workers,
-			NetworkDifficulty: j.NetworkDifficulty,
-			CoinPrice:         coinPrice,
-			BtcPrice:          btcPrice,
-			Source:            "luxor",
-		})
-	}
-	return data
+	common.blocksUrl = LuxorBlocksUrl
+	return &LuxorPow{common}, nil
 }
 
-func (*LuxorPow) Name() string { return Luxor }
+func (in *LuxorPow) Collect(ctx context.Context) ([]PowData, error) {
+	return in.fetch(ctx, new(luxorAPIResponse))
+}
 
 type F2poolPow struct {
 	CommonInfo
 }
 
 func NewF2pool(client *http.Client, lastUpdate int64) (Pow, error) {
-	if client == nil {
-		return nil, nilClientError
-	}
-	return &F2poolPow{
-		CommonInfo: CommonInfo{
-			client:     client,
-			lastUpdate: lastUpdate,
-			baseUrl:    F2poolUrl,
-		},
-	}, nil
-}
-
-func (in *F2poolPow) Collect(ctx context.Context) ([]PowData, error) {
-	res := new(f2poolAPIResponse)
-	err := helpers.GetResponse(ctx, in.client, in.baseUrl, res)
-
+	common, err := newCommonInfo(client, lastUpdate, F2poolUrl, F2pool, f2poolUnits)
 	if err != nil {
 		return nil, err
 	}
-
-	result := in.fetch(res, in.lastUpdate)
-	in.lastUpdate = result[len(result)-1].Time
-
-	return result, nil
+	common.blocksUrl = F2poolBlocksUrl
+	return &F2poolPow{common}, nil
 }
 
-func (F2poolPow) fetch(res *f2poolAPIResponse, start int64) []PowData {
-	data := make([]PowData, 0, len(res.Hashrate))
-	for k, v := range res.Hashrate {
-		t, _ := time.Parse(time.RFC3339, k)
-
-		if t.Unix() < start {
-			continue
-		}
-
-		data = append(data, PowData{
-			Time:              t.Unix(),
-			NetworkHashrate:   0,
-			PoolHashrate:      v,
-			Workers:           0,
-			NetworkDifficulty: 0,
-			CoinPrice:         0,
-			BtcPrice:          0,
-			Source:            "f2pool",
-		})
-	}
-	return data
+func (in *F2poolPow) Collect(ctx context.Context) ([]PowData, error) {
+	return in.fetch(ctx, new(f2poolAPIResponse))
 }
 
-func (*F2poolPow) Name() string { return F2pool }
-
 type CoinminePow struct {
 	CommonInfo
 }
 
 func NewCoinmine(client *http.Client, lastUpdate int64) (Pow, error) {
-	if client == nil {
-		return nil, nilClientError
+	common, err := newCommonInfo(client, lastUpdate, CoinmineUrl, Coinmine, coinmineUnits)
+	if err != nil {
+		return nil, err
 	}
-	return &CoinminePow{
-		CommonInfo: CommonInfo{
-			client:     client,
-			lastUpdate: lastUpdate,
-			baseUrl:    CoinmineUrl,
-		},
-	}, nil
+	return &CoinminePow{common}, nil
 }
 
 func (in *CoinminePow) Collect(ctx context.Context) ([]PowData, error) {
-	res := new(coinmineAPIResponse)
-	err := helpers.GetResponse(ctx, in.client, in.baseUrl, res)
+	return in.fetch(ctx, new(coinmineAPIResponse))
+}
 
+type BtcPow struct {
+	CommonInfo
+}
+
+func NewBtc(client *http.Client, lastUpdate int64) (Pow, error) {
+	common, err := newCommonInfo(client, lastUpdate, BtcUrl, Btc, btcUnits)
 	if err != nil {
 		return nil, err
 	}
+	common.blocksUrl = BtcBlocksUrl
+	return &BtcPow{common}, nil
+}
 
-	result := in.fetch(res, in.lastUpdate)
-	in.lastUpdate = result[len(result)-1].Time
-
-	return result, nil
+func (in *BtcPow) Collect(ctx context.Context) ([]PowData, error) {
+	return in.fetch(ctx, new(btcAPIResponse))
 }
 
-func (CoinminePow) fetch(res *coinmineAPIResponse, start int64) []PowData {
-	data := make([]PowData, 0, 1)
-	t := time.Now().Unix()
+type PoolinPow struct {
+	CommonInfo
+}
 
-	data = append(data, PowData{
-		Time:              t,
-		NetworkHashrate:   res.NetworkHashrate,
-		PoolHashrate:      res.PoolHashrate,
-		Workers:           res.Workers,
-		NetworkDifficulty: 0,
-		CoinPrice:         0,
-		BtcPrice:          0,
-		Source:            "coinmine",
-	})
-	return data
+func NewPoolin(client *http.Client, lastUpdate int64) (Pow, error) {
+	common, err := newCommonInfo(client, lastUpdate, PoolinUrl, Poolin, poolinUnits)
+	if err != nil {
+		return nil, err
+	}
+	common.blocksUrl = PoolinBlocksUrl
+	return &PoolinPow{common}, nil
 }
 
-func (*CoinminePow) Name() string { return Coinmine }
+func (in *PoolinPow) Collect(ctx context.Context) ([]PowData, error) {
+	return in.fetch(ctx, new(poolinAPIResponse))
+}
 
-type BtcPow struct {
+type AntpoolPow struct {
 	CommonInfo
 }
 
-func NewBtc(client *http.Client, lastUpdate int64) (Pow, error) {
-	if client == nil {
-		return nil, nilClientError
+func NewAntpool(client *http.Client, lastUpdate int64) (Pow, error) {
+	common, err := newCommonInfo(client, lastUpdate, AntpoolUrl, Antpool, antpoolUnits)
+	if err != nil {
+		return nil, err
 	}
-	return &BtcPow{
-		CommonInfo: CommonInfo{
-			client:     client,
-			lastUpdate: lastUpdate,
-			baseUrl:    BtcUrl,
-		},
-	}, nil
+	return &AntpoolPow{common}, nil
 }
 
-func (in *BtcPow) Collect(ctx context.Context) ([]PowData, error) {
-	res := new(btcAPIResponse)
-	err := helpers.GetResponse(ctx, in.client, in.baseUrl, res)
+func (in *AntpoolPow) Collect(ctx context.Context) ([]PowData, error) {
+	return in.fetch(ctx, new(antpoolAPIResponse))
+}
+
+type ViabtcPow struct {
+	CommonInfo
+}
 
+func NewViabtc(client *http.Client, lastUpdate int64) (Pow, error) {
+	common, err := newCommonInfo(client, lastUpdate, ViabtcUrl, Viabtc, viabtcUnits)
 	if err != nil {
 		return nil, err
 	}
+	common.blocksUrl = ViabtcBlocksUrl
+	return &ViabtcPow{common}, nil
+}
 
-	result := in.fetch(res, in.lastUpdate)
-	in.lastUpdate = result[len(result)-1].Time
-
-	return result, nil
+func (in *ViabtcPow) Collect(ctx context.Context) ([]PowData, error) {
+	return in.fetch(ctx, new(viabtcAPIResponse))
 }
 
-func (BtcPow) fetch(res *btcAPIResponse, start int64) []PowData {
-	data := make([]PowData, 0, 1)
-	t := time.Now().Unix()
+type UupoolPow struct {
+	CommonInfo
+}
 
-	n, err := strconv.ParseFloat(res.BtcData.NetworkHashrate, 64)
-	if err != nil {
-		return nil
-	}
-	p, err := strconv.ParseFloat(res.BtcData.PoolHashrate, 64)
+func NewUupool(client *http.Client, lastUpdate int64) (Pow, error) {
+	common, err := newCommonInfo(client, lastUpdate, UupoolUrl, Uupool, uupoolUnits)
 	if err != nil {
-		return nil
+		return nil, err
 	}
-
-	networkHashrate := int64(1000000000000000 * n)
-	poolHashrate := 1000000000000000 * p
-
-	data = append(data, PowData{
-		Time:              t,
-		NetworkHashrate:   networkHashrate,
-		PoolHashrate:      poolHashrate,
-		Workers:           0,
-		NetworkDifficulty: 0,
-		CoinPrice:         0,
-		BtcPrice:          res.BtcData.Rates.CoinPrice,
-		Source:            "btc",
-	})
-	return data
+	return &UupoolPow{common}, nil
 }
 
-func (*BtcPow) Name() string { return Btc }
+func (in *UupoolPow) Collect(ctx context.Context) ([]PowData, error) {
+	return in.fetch(ctx, new(uupoolAPIResponse))
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pow
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// genesisTime is the time of the Decred mainnet genesis block
+	genesisTime = 1454954400
+
+	// baseSubsidy is the block subsidy, in atoms, before any reduction.
+	// Every subsidyReductionInterval blocks it is reduced by
+	// subsidyReductionMultiplier / subsidyReductionDivisor.
+	baseSubsidy                = 3119582664
+	subsidyReductionInterval   = 6144
+	subsidyReductionMultiplier = 100
+	subsidyReductionDivisor    = 101
+
+	// hashesPerDifficulty is the expected number of hashes to find a block
+	// at difficulty 1
+	hashesPerDifficulty = 1 << 32
+
+	blocksPerDay = float64(24*time.Hour) / float64(targetBlockTime)
+)
+
+// workProportions are the shares of the block subsidy paid to PoW miners,
+// from the height they took effect. DCP-0010 and DCP-0012 lowered the
+// original 60%.
+var workProportions = []struct {
+	height     int64
+	proportion float64
+}{
+	{0, 0.6},
+	{657280, 0.1},
+	{794368, 0.01},
+}
+
+// BlockSubsidy returns the total subsidy of the block at height, in atoms
+func BlockSubsidy(height int64) int64 {
+	subsidy := int64(baseSubsidy)
+	for i := int64(0); i < height/subsidyReductionInterval; i++ {
+		subsidy = subsidy * subsidyReductionMultiplier / subsidyReductionDivisor
+	}
+	return subsidy
+}
+
+// WorkReward returns the reward of the miner of the block at height, in DCR
+func WorkReward(height int64) float64 {
+	proportion := workProportions[0].proportion
+	for _, p := range workProportions {
+		if height >= p.height {
+			proportion = p.proportion
+		}
+	}
+	return float64(BlockSubsidy(height)) * proportion / atomsPerCoin
+}
+
+// DifficultyHashrate returns the network hashrate, in H/s, that finds blocks
+// at the target block time at difficulty
+func DifficultyHashrate(difficulty float64) float64 {
+	return difficulty * hashesPerDifficulty / targetBlockTime.Seconds()
+}
+
+// EstimateHeight estimates the height of the chain at t from the target
+// block time, for when no block of the time is stored
+func EstimateHeight(t time.Time) int64 {
+	if t.Unix() <= genesisTime {
+		return 0
+	}
+	return (t.Unix() - genesisTime) / int64(targetBlockTime/time.Second)
+}
+
+// MiningCosts describes the mining hardware. Efficiency is its power use in
+// J/TH, or W per TH/s, and PowerCost the price of electricity in USD per kWh.
+type MiningCosts struct {
+	Efficiency float64 `json:"efficiency"`
+	PowerCost  float64 `json:"power_cost"`
+}
+
+// Validate returns an error when the costs cannot be used
+func (c MiningCosts) Validate() error {
+	if c.Efficiency <= 0 {
+		return fmt.Errorf("hardware efficiency must be positive, got %v J/TH", c.Efficiency)
+	}
+	if c.PowerCost < 0 {
+		return fmt.Errorf("electricity cost cannot be negative, got %v USD/kWh", c.PowerCost)
+	}
+	return nil
+}
+
+// NetworkDay is the average network hashrate, in H/s, and difficulty
+// reported by the PoW sources on the day starting at Time. Either is 0 when
+// no source reported it. Height is the height of the first stored block of
+// the day, 0 when none is stored.
+type NetworkDay struct {
+	Time            time.Time
+	NetworkHashrate float64
+	Difficulty      float64
+	Height          int64
+}
+
+// ProfitabilityDay is the expected daily revenue of 1 TH/s of hashrate on the
+// day starting at Time. HeightEstimated is set when no block of the day was
+// stored to take the height from. The USD values are only set when Priced,
+// that is, when the DCR price of the day is known.
+type ProfitabilityDay struct {
+	Time            time.Time `json:"time"`
+	NetworkHashrate float64   `json:"network_hashrate"`
+	Height          int64     `json:"height"`
+	HeightEstimated bool      `json:"height_estimated"`
+	BlockReward     float64   `json:"block_reward"`
+	RevenueDCR      float64   `json:"revenue_dcr"`
+	PowerCostUSD    float64   `json:"power_cost_usd"`
+	Priced          bool      `json:"priced"`
+	DCRPrice        float64   `json:"dcr_price"`
+	RevenueUSD      float64   `json:"revenue_usd"`
+	ProfitUSD       float64   `json:"profit_usd"`
+}
+
+// Profitability computes the expected revenue of 1 TH/s on a day, given the
+// DCR price in USD, 0 when unknown. The network hashrate is derived from the
+// difficulty when no source reported it, and false is returned when neither
+// is known.
+func Profitability(day NetworkDay, dcrPrice float64, costs MiningCosts) (ProfitabilityDay, bool) {
+	hashrate := day.NetworkHashrate
+	if hashrate <= 0 {
+		hashrate = DifficultyHashrate(day.Difficulty)
+	}
+	if hashrate <= 0 {
+		return ProfitabilityDay{}, false
+	}
+
+	p := ProfitabilityDay{
+		Time:            day.Time,
+		NetworkHashrate: hashrate,
+		Height:          day.Height,
+		PowerCostUSD:    costs.Efficiency * 24 / 1000 * costs.PowerCost,
+	}
+	if p.Height <= 0 {
+		p.Height = EstimateHeight(day.Time)
+		p.HeightEstimated = true
+	}
+	p.BlockReward = WorkReward(p.Height)
+	p.RevenueDCR = float64(TeraHashesPerSecond) / hashrate * blocksPerDay * p.BlockReward
+	if dcrPrice > 0 {
+		p.Priced = true
+		p.DCRPrice = dcrPrice
+		p.RevenueUSD = p.RevenueDCR * dcrPrice
+		p.ProfitUSD = p.RevenueUSD - p.PowerCostUSD
+	}
+	return p, true
+}
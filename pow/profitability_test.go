@@ -0,0 +1,124 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pow
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBlockSubsidy(t *testing.T) {
+	tests := []struct {
+		height  int64
+		subsidy int64
+	}{
+		{0, 3119582664},
+		{6143, 3119582664},
+		{6144, 3088695706},
+		{2 * 6144, 3058114560},
+	}
+	for _, test := range tests {
+		if subsidy := BlockSubsidy(test.height); subsidy != test.subsidy {
+			t.Errorf("subsidy at %d is %d, expected %d", test.height, subsidy, test.subsidy)
+		}
+	}
+}
+
+func TestWorkReward(t *testing.T) {
+	tests := []struct {
+		height     int64
+		proportion float64
+	}{
+		{1, 0.6},
+		{657279, 0.6},
+		{657280, 0.1},
+		{794368, 0.01},
+	}
+	for _, test := range tests {
+		expected := float64(BlockSubsidy(test.height)) * test.proportion / atomsPerCoin
+		if reward := WorkReward(test.height); reward != expected {
+			t.Errorf("reward at %d is %v, expected %v", test.height, reward, expected)
+		}
+	}
+}
+
+func TestProfitability(t *testing.T) {
+	day := time.Unix(genesisTime, 0).Add(100 * 24 * time.Hour).UTC()
+	costs := MiningCosts{Efficiency: 50, PowerCost: 0.1}
+
+	p, ok := Profitability(NetworkDay{Time: day, NetworkHashrate: 100 * float64(TeraHashesPerSecond)}, 20, costs)
+	if !ok {
+		t.Fatal("no profitability")
+	}
+	if p.Height != 100*288 || !p.HeightEstimated {
+		t.Errorf("height is %d, expected the estimated %d", p.Height, 100*288)
+	}
+	// 1 TH/s of 100 TH/s finds 1% of the 288 daily blocks
+	expectedDCR := 2.88 * WorkReward(p.Height)
+	if math.Abs(p.RevenueDCR-expectedDCR) > 1e-9 {
+		t.Errorf("revenue is %v DCR, expected %v", p.RevenueDCR, expectedDCR)
+	}
+	// 50 W for 24 hours at 0.1 USD/kWh
+	if math.Abs(p.PowerCostUSD-0.12) > 1e-9 {
+		t.Errorf("power cost is %v USD, expected 0.12", p.PowerCostUSD)
+	}
+	if !p.Priced || math.Abs(p.ProfitUSD-(expectedDCR*20-0.12)) > 1e-9 {
+		t.Errorf("unexpected profit %+v", p)
+	}
+
+	// Without a price the USD values are not set
+	p, ok = Profitability(NetworkDay{Time: day, NetworkHashrate: 1e14}, 0, costs)
+	if !ok || p.Priced || p.ProfitUSD != 0 {
+		t.Errorf("unexpected unpriced profitability %+v", p)
+	}
+}
+
+func TestProfitabilityStoredHeight(t *testing.T) {
+	// The chain ran slower than the target block time, so that the height
+	// estimated from the time is past the stored height, and past a change
+	// of the work proportion
+	day := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	if EstimateHeight(day) < 794368 {
+		t.Fatalf("estimated height %d, expected it past 794368", EstimateHeight(day))
+	}
+	p, ok := Profitability(NetworkDay{Time: day, NetworkHashrate: 1e14, Height: 790000}, 0, MiningCosts{Efficiency: 1})
+	if !ok {
+		t.Fatal("no profitability")
+	}
+	if p.Height != 790000 || p.HeightEstimated {
+		t.Errorf("height is %d, estimated %v, expected the stored 790000", p.Height, p.HeightEstimated)
+	}
+	if p.BlockReward != WorkReward(790000) {
+		t.Errorf("block reward %v, expected the reward at the stored height %v", p.BlockReward, WorkReward(790000))
+	}
+}
+
+func TestProfitabilityFromDifficulty(t *testing.T) {
+	difficulty := 1e10
+	p, ok := Profitability(NetworkDay{Difficulty: difficulty}, 0, MiningCosts{Efficiency: 1})
+	if !ok {
+		t.Fatal("no profitability")
+	}
+	if expected := difficulty * (1 << 32) / 300; p.NetworkHashrate != expected {
+		t.Errorf("network hashrate is %v, expected %v", p.NetworkHashrate, expected)
+	}
+
+	if _, ok = Profitability(NetworkDay{}, 0, MiningCosts{Efficiency: 1}); ok {
+		t.Error("profitability without hashrate nor difficulty")
+	}
+}
+
+func TestMiningCostsValidate(t *testing.T) {
+	if err := (MiningCosts{Efficiency: 47, PowerCost: 0}).Validate(); err != nil {
+		t.Error(err)
+	}
+	if err := (MiningCosts{Efficiency: 0, PowerCost: 0.05}).Validate(); err == nil {
+		t.Error("zero efficiency accepted")
+	}
+	if err := (MiningCosts{Efficiency: 47, PowerCost: -1}).Validate(); err == nil {
+		t.Error("negative power cost accepted")
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package share
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// lookback limits how far back shares are computed on the first run
+	lookback = 14 * 24 * time.Hour
+
+	// majority is the percentage of the hashrate the pools counted by the
+	// Nakamoto coefficient must exceed
+	majority = 50
+)
+
+// Monitor computes the share of the network hashrate held by every PoW pool
+// over consecutive periods and raises an alert while a single pool, or the
+// top pools together, hold more than their threshold.
+type Monitor struct {
+	period        time.Duration
+	settle        time.Duration
+	poolThreshold float64
+	topPools      int
+	topThreshold  float64
+	store         Store
+}
+
+// NewMonitor creates a monitor that computes shares every period. A period is
+// computed once settle has passed since its end, so that the sources that
+// report late are counted in it. Thresholds are percentages of the network
+// hashrate.
+func NewMonitor(period, settle time.Duration, poolThreshold float64, topPools int, topThreshold float64, store Store) (*Monitor, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("Pool share period must be positive, got %v", period)
+	}
+	if settle < 0 {
+		return nil, fmt.Errorf("Pool share settle delay must not be negative, got %v", settle)
+	}
+	if poolThreshold <= 0 || poolThreshold > 100 {
+		return nil, fmt.Errorf("Pool share threshold must be between 0 and 100, got %v", poolThreshold)
+	}
+	if topPools < 1 {
+		return nil, fmt.Errorf("Number of top pools must be at least 1, got %d", topPools)
+	}
+	if topThreshold <= 0 || topThreshold > 100 {
+		return nil, fmt.Errorf("Top pools share threshold must be between 0 and 100, got %v", topThreshold)
+	}
+
+	return &Monitor{
+		period:        period,
+		settle:        settle,
+		poolThreshold: poolThreshold,
+		topPools:      topPools,
+		topThreshold:  topThreshold,
+		store:         store,
+	}, nil
+}
+
+// Run computes the shares of every settled period until ctx is cancelled
+func (m *Monitor) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(m.period)
+	defer ticker.Stop()
+	for {
+		if err := m.update(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Errorf("Unable to compute pool shares: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *Monitor) update(ctx context.Context, now time.Time) error {
+	last, err := m.store.LastCentralization(ctx)
+	if err != nil {
+		return err
+	}
+	since := now.Add(-lookback).Truncate(m.period)
+	if last != nil && last.Time.Add(m.period).After(since) {
+		since = last.Time.Add(m.period)
+	}
+
+	hashrates, err := m.store.PoolHashrates(ctx, since.Unix())
+	if err != nil {
+		return err
+	}
+	periods := m.computeShares(hashrates, since, now.Add(-m.settle))
+	if len(periods) == 0 {
+		log.Debug("No new pool shares")
+		return nil
+	}
+
+	openAlerts, err := m.store.OpenShareAlerts(ctx)
+	if err != nil {
+		return err
+	}
+	open := make(map[alertKey]Alert, len(openAlerts))
+	for _, alert := range openAlerts {
+		open[alert.key()] = alert
+	}
+	// Alerts raised and ended within the update are stored once, ended
+	var alerts []Alert
+	raised := make(map[alertKey]int)
+	for _, period := range periods {
+		for _, alert := range m.checkAlerts(period, open) {
+			key := alert.key()
+			if i, ok := raised[key]; ok && alert.End != nil {
+				alerts[i] = alert
+				delete(raised, key)
+				continue
+			}
+			if alert.ID == 0 && alert.End == nil {
+				raised[key] = len(alerts)
+			}
+			alerts = append(alerts, alert)
+		}
+	}
+
+	return m.store.StoreCentralization(ctx, periods, alerts)
+}
+
+// computeShares groups the hashrates into the periods that completed between
+// since and until. A pool's share in a period is its last reported hashrate
+// over the median of the network hashrates reported by all sources. Periods
+// without a network hashrate are skipped.
+func (m *Monitor) computeShares(hashrates []PoolHashrate, since, until time.Time) []Centralization {
+	type periodData struct {
+		network []float64
+		pools   map[string]PoolHashrate
+	}
+	byPeriod := make(map[int64]*periodData)
+	for _, h := range hashrates {
+		start := time.Unix(h.Time, 0).Truncate(m.period)
+		if start.Before(since) || start.Add(m.period).After(until) {
+			continue
+		}
+		data, ok := byPeriod[start.Unix()]
+		if !ok {
+			data = &periodData{pools: make(map[string]PoolHashrate)}
+			byPeriod[start.Unix()] = data
+		}
+		if h.NetworkHashrate > 0 {
+			data.network = append(data.network, h.NetworkHashrate)
+		}
+		if h.PoolHashrate > 0 {
+			if last, ok := data.pools[h.Source]; !ok || h.Time >= last.Time {
+				data.pools[h.Source] = h
+			}
+		}
+	}
+
+	starts := make([]int64, 0, len(byPeriod))
+	for start := range byPeriod {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+
+	periods := make([]Centralization, 0, len(starts))
+	for _, start := range starts {
+		data := byPeriod[start]
+		if len(data.network) == 0 || len(data.pools) == 0 {
+			continue
+		}
+		period := Centralization{
+			Time:            time.Unix(start, 0).UTC(),
+			NetworkHashrate: median(data.network),
+		}
+		for source, h := range data.pools {
+			period.Shares = append(period.Shares, PoolShare{
+				Source:   source,
+				Hashrate: h.PoolHashrate,
+				Share:    h.PoolHashrate / period.NetworkHashrate * 100,
+			})
+		}
+		sort.Slice(period.Shares, func(i, j int) bool {
+			if period.Shares[i].Share == period.Shares[j].Share {
+				return period.Shares[i].Source < period.Shares[j].Source
+			}
+			return period.Shares[i].Share > period.Shares[j].Share
+		})
+		for i, share := range period.Shares {
+			period.KnownShare += share.Share
+			if period.NakamotoCoefficient == 0 && period.KnownShare > majority {
+				period.NakamotoCoefficient = i + 1
+			}
+			if i < m.topPools {
+				period.TopShare += share.Share
+			}
+		}
+		periods = append(periods, period)
+	}
+	return periods
+}
+
+// checkAlerts raises the alerts that start in the period and ends the open
+// alerts whose pools fell below the threshold. open is updated to the alerts
+// that remain open after the period.
+func (m *Monitor) checkAlerts(period Centralization, open map[alertKey]Alert) []Alert {
+	var alerts []Alert
+	above := make(map[alertKey]Alert)
+	for _, share := range period.Shares {
+		if share.Share > m.poolThreshold {
+			alert := Alert{Kind: AlertPool, Pools: []string{share.Source}, Share: share.Share,
+				Threshold: m.poolThreshold, Start: period.Time}
+			above[alert.key()] = alert
+		}
+	}
+	if len(period.Shares) > 0 && period.TopShare > m.topThreshold {
+		alert := Alert{Kind: AlertTopPools, Share: period.TopShare, Threshold: m.topThreshold, Start: period.Time}
+		for i := 0; i < m.topPools && i < len(period.Shares); i++ {
+			alert.Pools = append(alert.Pools, period.Shares[i].Source)
+		}
+		above[alert.key()] = alert
+	}
+
+	for key, alert := range open {
+		if _, ok := above[key]; ok {
+			continue
+		}
+		end := period.Time
+		alert.End = &end
+		log.Infof("Pool share alert ended at %s: %s", end.Format("2006-01-02 15:04"), alert)
+		alerts = append(alerts, alert)
+		delete(open, key)
+	}
+	for key, alert := range above {
+		if _, ok := open[key]; ok {
+			continue
+		}
+		log.Warnf("Pool share alert at %s: %s", alert.Start.Format("2006-01-02 15:04"), alert)
+		alerts = append(alerts, alert)
+		open[key] = alert
+	}
+	return alerts
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
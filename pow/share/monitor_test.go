@@ -0,0 +1,225 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package share
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+const (
+	hour = int64(3600)
+	// start is the beginning of the first period of the tests
+	start = int64(1559347200)
+)
+
+type memoryStore struct {
+	hashrates []PoolHashrate
+	periods   []Centralization
+	alerts    []Alert
+}
+
+func (s *memoryStore) PoolHashrates(ctx context.Context, since int64) ([]PoolHashrate, error) {
+	var hashrates []PoolHashrate
+	for _, h := range s.hashrates {
+		if h.Time >= since {
+			hashrates = append(hashrates, h)
+		}
+	}
+	return hashrates, nil
+}
+
+func (s *memoryStore) LastCentralization(ctx context.Context) (*Centralization, error) {
+	if len(s.periods) == 0 {
+		return nil, nil
+	}
+	return &s.periods[len(s.periods)-1], nil
+}
+
+func (s *memoryStore) OpenShareAlerts(ctx context.Context) ([]Alert, error) {
+	var open []Alert
+	for _, a := range s.alerts {
+		if a.End == nil {
+			open = append(open, a)
+		}
+	}
+	return open, nil
+}
+
+func (s *memoryStore) StoreCentralization(ctx context.Context, periods []Centralization, alerts []Alert) error {
+	s.periods = append(s.periods, periods...)
+	for _, alert := range alerts {
+		if alert.ID == 0 {
+			alert.ID = len(s.alerts) + 1
+			s.alerts = append(s.alerts, alert)
+			continue
+		}
+		s.alerts[alert.ID-1].End = alert.End
+	}
+	return nil
+}
+
+func newTestMonitor(t *testing.T, store Store) *Monitor {
+	m, err := NewMonitor(time.Hour, 0, 40, 2, 75, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestComputeShares(t *testing.T) {
+	m := newTestMonitor(t, nil)
+	hashrates := []PoolHashrate{
+		{Source: "a", Time: start, NetworkHashrate: 90, PoolHashrate: 10},
+		// The last hashrate of a pool in the period counts
+		{Source: "a", Time: start + 600, NetworkHashrate: 100, PoolHashrate: 30},
+		{Source: "b", Time: start + 1200, NetworkHashrate: 110, PoolHashrate: 25},
+		{Source: "c", Time: start + 1800, PoolHashrate: 5},
+		// No network hashrate in the second period
+		{Source: "c", Time: start + hour, PoolHashrate: 5},
+		// The third period has not ended
+		{Source: "a", Time: start + 2*hour, NetworkHashrate: 100, PoolHashrate: 30},
+	}
+	periods := m.computeShares(hashrates, time.Unix(start, 0), time.Unix(start+2*hour+60, 0))
+	if len(periods) != 1 {
+		t.Fatalf("got %d periods, expected 1", len(periods))
+	}
+	p := periods[0]
+	if p.Time.Unix() != start || p.NetworkHashrate != 100 {
+		t.Errorf("unexpected period %+v", p)
+	}
+	expected := []PoolShare{{"a", 30, 30}, {"b", 25, 25}, {"c", 5, 5}}
+	if len(p.Shares) != len(expected) {
+		t.Fatalf("got shares %+v", p.Shares)
+	}
+	for i, share := range p.Shares {
+		if share != expected[i] {
+			t.Errorf("share %d is %+v, expected %+v", i, share, expected[i])
+		}
+	}
+	if p.KnownShare != 60 || p.TopShare != 55 || p.NakamotoCoefficient != 2 {
+		t.Errorf("known share %v, top share %v, Nakamoto coefficient %d", p.KnownShare, p.TopShare,
+			p.NakamotoCoefficient)
+	}
+}
+
+func TestNakamotoCoefficientWithoutMajority(t *testing.T) {
+	m := newTestMonitor(t, nil)
+	periods := m.computeShares([]PoolHashrate{
+		{Source: "a", Time: start, NetworkHashrate: 100, PoolHashrate: 20},
+		{Source: "b", Time: start, NetworkHashrate: 100, PoolHashrate: 20},
+	}, time.Unix(start, 0), time.Unix(start+hour, 0))
+	if len(periods) != 1 || periods[0].NakamotoCoefficient != 0 {
+		t.Errorf("unexpected periods %+v", periods)
+	}
+}
+
+func TestAlerts(t *testing.T) {
+	store := new(memoryStore)
+	for i, shares := range [][2]float64{{30, 30}, {45, 35}, {50, 30}, {30, 30}, {45, 10}} {
+		at := start + int64(i)*hour
+		store.hashrates = append(store.hashrates,
+			PoolHashrate{Source: "a", Time: at, NetworkHashrate: 100, PoolHashrate: shares[0]},
+			PoolHashrate{Source: "b", Time: at, NetworkHashrate: 100, PoolHashrate: shares[1]})
+	}
+	m := newTestMonitor(t, store)
+
+	// The first update computes the first three periods, the second the
+	// rest, so that alerts are ended across updates
+	if err := m.update(context.Background(), time.Unix(start+3*hour, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.update(context.Background(), time.Unix(start+5*hour, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.periods) != 5 {
+		t.Fatalf("got %d periods, expected 5", len(store.periods))
+	}
+
+	type expectedAlert struct {
+		kind       AlertKind
+		start, end int64
+	}
+	expected := []expectedAlert{
+		{AlertPool, start + hour, start + 3*hour},
+		{AlertTopPools, start + hour, start + 3*hour},
+		{AlertPool, start + 4*hour, 0},
+	}
+	if len(store.alerts) != len(expected) {
+		t.Fatalf("got alerts %+v", store.alerts)
+	}
+	for _, e := range expected {
+		found := false
+		for _, alert := range store.alerts {
+			if alert.Kind != e.kind || alert.Start.Unix() != e.start {
+				continue
+			}
+			found = true
+			if e.end == 0 && alert.End != nil || e.end != 0 && (alert.End == nil || alert.End.Unix() != e.end) {
+				t.Errorf("%s alert from %d ends at %v, expected %d", e.kind, e.start, alert.End, e.end)
+			}
+			if alert.Kind == AlertPool && alert.Pools[0] != "a" {
+				t.Errorf("alert of %v, expected a", alert.Pools)
+			}
+		}
+		if !found {
+			t.Errorf("no %s alert from %d in %+v", e.kind, e.start, store.alerts)
+		}
+	}
+}
+
+func TestAlertRaisedAndEndedInOneUpdate(t *testing.T) {
+	store := new(memoryStore)
+	for i, share := range []float64{50, 10} {
+		store.hashrates = append(store.hashrates,
+			PoolHashrate{Source: "a", Time: start + int64(i)*hour, NetworkHashrate: 100, PoolHashrate: share})
+	}
+	m := newTestMonitor(t, store)
+	if err := m.update(context.Background(), time.Unix(start+2*hour, 0)); err != nil {
+		t.Fatal(err)
+	}
+	// The alert is stored once, ended
+	if len(store.alerts) != 1 {
+		t.Fatalf("got alerts %+v", store.alerts)
+	}
+	for _, alert := range store.alerts {
+		if alert.End == nil || alert.End.Unix() != start+hour {
+			t.Errorf("unexpected alert %+v", alert)
+		}
+	}
+}
+
+func TestSettleDelay(t *testing.T) {
+	store := &memoryStore{hashrates: []PoolHashrate{
+		{Source: "a", Time: start + 600, NetworkHashrate: 100, PoolHashrate: 30},
+	}}
+	m, err := NewMonitor(time.Hour, 30*time.Minute, 40, 2, 75, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The period ended but has not settled
+	if err := m.update(context.Background(), time.Unix(start+hour+600, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.periods) != 0 {
+		t.Fatalf("computed %d periods before they settled", len(store.periods))
+	}
+
+	// A source on a long interval reports into the period late
+	store.hashrates = append(store.hashrates,
+		PoolHashrate{Source: "b", Time: start + 1800, NetworkHashrate: 100, PoolHashrate: 20})
+	if err := m.update(context.Background(), time.Unix(start+hour+1800, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.periods) != 1 || len(store.periods[0].Shares) != 2 {
+		t.Fatalf("got periods %+v, expected one with both pools", store.periods)
+	}
+
+	if _, err := NewMonitor(time.Hour, -time.Minute, 40, 2, 75, store); err == nil {
+		t.Error("expected an error for a negative settle delay")
+	}
+}
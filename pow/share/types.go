@@ -0,0 +1,99 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package share
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Store interface {
+	// PoolHashrates returns the hashrates reported by the PoW sources from
+	// since, in unix seconds, oldest first
+	PoolHashrates(ctx context.Context, since int64) ([]PoolHashrate, error)
+	// LastCentralization returns the most recent computed period, nil when
+	// none was computed yet
+	LastCentralization(ctx context.Context) (*Centralization, error)
+	OpenShareAlerts(ctx context.Context) ([]Alert, error)
+	// StoreCentralization stores the computed periods, adds the alerts
+	// without an ID and ends the others
+	StoreCentralization(ctx context.Context, periods []Centralization, alerts []Alert) error
+}
+
+// PoolHashrate is a stored PoW data point, with hashrates in H/s. Hashrates
+// the source does not report are 0.
+type PoolHashrate struct {
+	Source          string
+	Time            int64
+	NetworkHashrate float64
+	PoolHashrate    float64
+}
+
+// PoolShare is the hashrate of a pool and its percentage of the network
+// hashrate
+type PoolShare struct {
+	Source   string  `json:"source"`
+	Hashrate float64 `json:"hashrate"`
+	Share    float64 `json:"share"`
+}
+
+// Centralization is the distribution of the network hashrate over the pools
+// in the period starting at Time. Shares are sorted largest first.
+// NakamotoCoefficient is the smallest number of pools that together hold the
+// majority of the hashrate, 0 when the tracked pools hold no majority.
+// KnownShare is the percentage held by the tracked pools and TopShare the
+// percentage held by the monitor's top pools.
+type Centralization struct {
+	Time                time.Time   `json:"time"`
+	NetworkHashrate     float64     `json:"network_hashrate"`
+	Shares              []PoolShare `json:"shares"`
+	NakamotoCoefficient int         `json:"nakamoto_coefficient"`
+	KnownShare          float64     `json:"known_share"`
+	TopShare            float64     `json:"top_share"`
+}
+
+type AlertKind string
+
+const (
+	// AlertPool is raised when a single pool holds more than the pool
+	// threshold
+	AlertPool AlertKind = "pool"
+	// AlertTopPools is raised when the top pools together hold more than
+	// the top pools threshold
+	AlertTopPools AlertKind = "top_pools"
+)
+
+// Alert is a period in which one or more pools held more than Threshold
+// percent of the network hashrate. Share is the percentage they held when
+// the alert was raised, End is nil while the alert lasts.
+type Alert struct {
+	ID        int        `json:"id"`
+	Kind      AlertKind  `json:"kind"`
+	Pools     []string   `json:"pools"`
+	Share     float64    `json:"share"`
+	Threshold float64    `json:"threshold"`
+	Start     time.Time  `json:"start"`
+	End       *time.Time `json:"end"`
+}
+
+func (a Alert) String() string {
+	return fmt.Sprintf("%s held %.2f%% of the network hashrate, above the %.2f%% threshold",
+		strings.Join(a.Pools, ", "), a.Share, a.Threshold)
+}
+
+// alertKey identifies the open alert of a single pool or of the top pools
+type alertKey struct {
+	kind AlertKind
+	pool string
+}
+
+func (a Alert) key() alertKey {
+	if a.Kind == AlertPool && len(a.Pools) == 1 {
+		return alertKey{kind: a.Kind, pool: a.Pools[0]}
+	}
+	return alertKey{kind: a.Kind}
+}
@@ -1,19 +1,44 @@
 package pow
 
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ericlagergren/decimal"
+)
+
+// PowData is a PoW pool data point with hashrates in H/s. Prices keep the
+// exact digits published by the source and are nil when the source does not
+// report them.
 type PowData struct {
 	Time              int64
 	NetworkHashrate   int64
 	PoolHashrate      float64
 	Workers           int64
 	NetworkDifficulty float64
-	CoinPrice         float64
-	BtcPrice          float64
+	CoinPrice         *decimal.Big
+	BtcPrice          *decimal.Big
 	Source            string
 }
 
+// PowDataDto is a stored PoW pool data point with hashrates in H/s
+type PowDataDto struct {
+	Time              time.Time    `json:"time"`
+	NetworkHashrate   float64      `json:"network_hashrate"`
+	PoolHashrate      float64      `json:"pool_hashrate"`
+	Workers           int64        `json:"workers"`
+	NetworkDifficulty float64      `json:"network_difficulty"`
+	CoinPrice         *decimal.Big `json:"coin_price"`
+	BtcPrice          *decimal.Big `json:"btc_price"`
+	Source            string       `json:"source"`
+}
+
 type luxorPowData struct {
 	Time              string  `json:"time"`
-	NetworkHashrate   int64   `json:"network_hashrate"`
+	NetworkHashrate   float64 `json:"network_hashrate"`
 	PoolHashrate      float64 `json:"pool_hashrate"`
 	Workers           int64   `json:"workers"`
 	NetworkDifficulty float64 `json:"network_difficulty"`
@@ -25,18 +50,83 @@ type luxorAPIResponse struct {
 	GlobalStats []luxorPowData `json:"globalStats"`
 }
 
+func (resp luxorAPIResponse) toPowData(int64) ([]sourceData, []skippedRecord) {
+	data := make([]sourceData, 0, len(resp.GlobalStats))
+	var skipped []skippedRecord
+	for _, j := range resp.GlobalStats {
+		t, err := time.Parse(time.RFC3339, j.Time)
+		if err != nil {
+			skipped = append(skipped, skippedRecord{j.Time, "invalid time"})
+			continue
+		}
+		coinPrice, err := parseOptionalDecimal(j.CoinPrice)
+		if err != nil {
+			skipped = append(skipped, skippedRecord{j.Time, "coin price: " + err.Error()})
+			continue
+		}
+		btcPrice, err := parseOptionalDecimal(j.BtcPrice)
+		if err != nil {
+			skipped = append(skipped, skippedRecord{j.Time, "btc price: " + err.Error()})
+			continue
+		}
+
+		data = append(data, sourceData{
+			PowData: PowData{
+				Time:              t.Unix(),
+				Workers:           j.Workers,
+				NetworkDifficulty: j.NetworkDifficulty,
+				CoinPrice:         coinPrice,
+				BtcPrice:          btcPrice,
+			},
+			networkHashrate: j.NetworkHashrate,
+			poolHashrate:    j.PoolHashrate,
+		})
+	}
+	return data, skipped
+}
+
 type f2poolPowData map[string]float64
 
+// f2poolAPIResponse keys the pool hashrate history by RFC3339 time, in no
+// particular order
 type f2poolAPIResponse struct {
 	Hashrate f2poolPowData `json:"hashrate_history"`
 }
 
+func (resp f2poolAPIResponse) toPowData(int64) ([]sourceData, []skippedRecord) {
+	data := make([]sourceData, 0, len(resp.Hashrate))
+	var skipped []skippedRecord
+	for k, v := range resp.Hashrate {
+		t, err := time.Parse(time.RFC3339, k)
+		if err != nil {
+			skipped = append(skipped, skippedRecord{k, "invalid time"})
+			continue
+		}
+		data = append(data, sourceData{
+			PowData:      PowData{Time: t.Unix()},
+			poolHashrate: v,
+		})
+	}
+	return data, skipped
+}
+
 type coinmineAPIResponse struct {
-	NetworkHashrate int64   `json:"network_hashrate"`
+	NetworkHashrate float64 `json:"network_hashrate"`
 	PoolHashrate    float64 `json:"hashrate"`
 	Workers         int64   `json:"workers"`
 }
 
+func (resp coinmineAPIResponse) toPowData(now int64) ([]sourceData, []skippedRecord) {
+	return []sourceData{{
+		PowData: PowData{
+			Time:    now,
+			Workers: resp.Workers,
+		},
+		networkHashrate: resp.NetworkHashrate,
+		poolHashrate:    resp.PoolHashrate,
+	}}, nil
+}
+
 type btcData struct {
 	NetworkHashrate string              `json:"network_hashrate"`
 	PoolHashrate    string              `json:"pool_hashrate"`
@@ -44,8 +134,202 @@ type btcData struct {
 }
 
 type btcExchangeRateData struct {
-	CoinPrice float64 `json:"DCR2USD"`
+	CoinPrice json.Number `json:"DCR2USD"`
 }
 type btcAPIResponse struct {
 	BtcData btcData `json:"data"`
 }
+
+func (resp btcAPIResponse) toPowData(now int64) ([]sourceData, []skippedRecord) {
+	key := fmt.Sprint(now)
+	n, err := strconv.ParseFloat(resp.BtcData.NetworkHashrate, 64)
+	if err != nil {
+		return nil, []skippedRecord{{key, fmt.Sprintf("invalid network hashrate %q", resp.BtcData.NetworkHashrate)}}
+	}
+	p, err := strconv.ParseFloat(resp.BtcData.PoolHashrate, 64)
+	if err != nil {
+		return nil, []skippedRecord{{key, fmt.Sprintf("invalid pool hashrate %q", resp.BtcData.PoolHashrate)}}
+	}
+	btcPrice, err := parseOptionalDecimal(resp.BtcData.Rates.CoinPrice.String())
+	if err != nil {
+		return nil, []skippedRecord{{key, "coin price: " + err.Error()}}
+	}
+
+	return []sourceData{{
+		PowData: PowData{
+			Time:     now,
+			BtcPrice: btcPrice,
+		},
+		networkHashrate: n,
+		poolHashrate:    p,
+	}}, nil
+}
+
+type poolinData struct {
+	NetworkHashrate     string  `json:"network_hashrate"`
+	NetworkHashrateUnit string  `json:"network_hashrate_unit"`
+	PoolHashrate        string  `json:"pool_hashrate"`
+	PoolHashrateUnit    string  `json:"pool_hashrate_unit"`
+	Workers             int64   `json:"workers"`
+	NetworkDifficulty   float64 `json:"network_diff"`
+}
+
+type poolinAPIResponse struct {
+	ErrNo  int        `json:"err_no"`
+	ErrMsg string     `json:"err_msg"`
+	Data   poolinData `json:"data"`
+}
+
+func (resp poolinAPIResponse) toPowData(now int64) ([]sourceData, []skippedRecord) {
+	key := fmt.Sprint(now)
+	if resp.ErrNo != 0 {
+		return nil, []skippedRecord{{key, fmt.Sprintf("error %d: %s", resp.ErrNo, resp.ErrMsg)}}
+	}
+	var units hashrateUnits
+	var err error
+	if units.network, err = ParseHashrateUnit(resp.Data.NetworkHashrateUnit); err != nil {
+		return nil, []skippedRecord{{key, "network hashrate: " + err.Error()}}
+	}
+	if units.pool, err = ParseHashrateUnit(resp.Data.PoolHashrateUnit); err != nil {
+		return nil, []skippedRecord{{key, "pool hashrate: " + err.Error()}}
+	}
+	n, err := strconv.ParseFloat(resp.Data.NetworkHashrate, 64)
+	if err != nil {
+		return nil, []skippedRecord{{key, fmt.Sprintf("invalid network hashrate %q", resp.Data.NetworkHashrate)}}
+	}
+	p, err := strconv.ParseFloat(resp.Data.PoolHashrate, 64)
+	if err != nil {
+		return nil, []skippedRecord{{key, fmt.Sprintf("invalid pool hashrate %q", resp.Data.PoolHashrate)}}
+	}
+
+	return []sourceData{{
+		PowData: PowData{
+			Time:              now,
+			Workers:           resp.Data.Workers,
+			NetworkDifficulty: resp.Data.NetworkDifficulty,
+		},
+		networkHashrate: n,
+		poolHashrate:    p,
+		units:           units,
+	}}, nil
+}
+
+type antpoolData struct {
+	NetworkHashrate   json.Number `json:"networkHashrate"`
+	PoolHashrate      json.Number `json:"poolHashrate"`
+	ActiveWorkers     int64       `json:"activeWorkerNum"`
+	NetworkDifficulty json.Number `json:"networkDiff"`
+}
+
+type antpoolAPIResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    antpoolData `json:"data"`
+}
+
+func (resp antpoolAPIResponse) toPowData(now int64) ([]sourceData, []skippedRecord) {
+	key := fmt.Sprint(now)
+	if resp.Code != 0 {
+		return nil, []skippedRecord{{key, fmt.Sprintf("error %d: %s", resp.Code, resp.Message)}}
+	}
+	n, err := resp.Data.NetworkHashrate.Float64()
+	if err != nil {
+		return nil, []skippedRecord{{key, fmt.Sprintf("invalid network hashrate %q", resp.Data.NetworkHashrate)}}
+	}
+	p, err := resp.Data.PoolHashrate.Float64()
+	if err != nil {
+		return nil, []skippedRecord{{key, fmt.Sprintf("invalid pool hashrate %q", resp.Data.PoolHashrate)}}
+	}
+	difficulty, err := resp.Data.NetworkDifficulty.Float64()
+	if err != nil {
+		return nil, []skippedRecord{{key, fmt.Sprintf("invalid network difficulty %q", resp.Data.NetworkDifficulty)}}
+	}
+
+	return []sourceData{{
+		PowData: PowData{
+			Time:              now,
+			Workers:           resp.Data.ActiveWorkers,
+			NetworkDifficulty: difficulty,
+		},
+		networkHashrate: n,
+		poolHashrate:    p,
+	}}, nil
+}
+
+type viabtcCoinData struct {
+	Coin              string      `json:"coin"`
+	NetworkHashrate   json.Number `json:"network_hashrate"`
+	PoolHashrate      json.Number `json:"pool_hashrate"`
+	Workers           int64       `json:"workers"`
+	NetworkDifficulty json.Number `json:"curr_diff"`
+}
+
+// viabtcAPIResponse lists the state of every coin mined on the pool
+type viabtcAPIResponse struct {
+	Code    int              `json:"code"`
+	Message string           `json:"message"`
+	Data    []viabtcCoinData `json:"data"`
+}
+
+func (resp viabtcAPIResponse) toPowData(now int64) ([]sourceData, []skippedRecord) {
+	key := fmt.Sprint(now)
+	if resp.Code != 0 {
+		return nil, []skippedRecord{{key, fmt.Sprintf("error %d: %s", resp.Code, resp.Message)}}
+	}
+	for _, coin := range resp.Data {
+		if !strings.EqualFold(coin.Coin, "DCR") {
+			continue
+		}
+		n, err := coin.NetworkHashrate.Float64()
+		if err != nil {
+			return nil, []skippedRecord{{key, fmt.Sprintf("invalid network hashrate %q", coin.NetworkHashrate)}}
+		}
+		p, err := coin.PoolHashrate.Float64()
+		if err != nil {
+			return nil, []skippedRecord{{key, fmt.Sprintf("invalid pool hashrate %q", coin.PoolHashrate)}}
+		}
+		difficulty, err := coin.NetworkDifficulty.Float64()
+		if err != nil {
+			return nil, []skippedRecord{{key, fmt.Sprintf("invalid network difficulty %q", coin.NetworkDifficulty)}}
+		}
+
+		return []sourceData{{
+			PowData: PowData{
+				Time:              now,
+				Workers:           coin.Workers,
+				NetworkDifficulty: difficulty,
+			},
+			networkHashrate: n,
+			poolHashrate:    p,
+		}}, nil
+	}
+	return nil, []skippedRecord{{key, "no DCR state"}}
+}
+
+type uupoolStats struct {
+	Hashrate   float64 `json:"hashrate"`
+	Workers    int64   `json:"workers"`
+	Difficulty float64 `json:"difficulty"`
+}
+
+type uupoolAPIResponse struct {
+	Pool    uupoolStats `json:"pool"`
+	Network uupoolStats `json:"network"`
+	// Time is when the stats were last updated, in unix seconds
+	Time int64 `json:"time"`
+}
+
+func (resp uupoolAPIResponse) toPowData(now int64) ([]sourceData, []skippedRecord) {
+	if resp.Time <= 0 || resp.Time > now {
+		return nil, []skippedRecord{{fmt.Sprint(resp.Time), "invalid time"}}
+	}
+	return []sourceData{{
+		PowData: PowData{
+			Time:              resp.Time,
+			Workers:           resp.Pool.Workers,
+			NetworkDifficulty: resp.Network.Difficulty,
+		},
+		networkHashrate: resp.Network.Hashrate,
+		poolHashrate:    resp.Pool.Hashrate,
+	}}, nil
+}
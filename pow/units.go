@@ -0,0 +1,122 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pow
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// HashrateUnit is a unit of hashrate, valued in H/s
+type HashrateUnit float64
+
+const (
+	HashesPerSecond     HashrateUnit = 1
+	KiloHashesPerSecond HashrateUnit = 1e3
+	MegaHashesPerSecond HashrateUnit = 1e6
+	GigaHashesPerSecond HashrateUnit = 1e9
+	TeraHashesPerSecond HashrateUnit = 1e12
+	PetaHashesPerSecond HashrateUnit = 1e15
+	ExaHashesPerSecond  HashrateUnit = 1e18
+)
+
+var hashrateUnitNames = []struct {
+	unit HashrateUnit
+	name string
+}{
+	{HashesPerSecond, "H/s"},
+	{KiloHashesPerSecond, "KH/s"},
+	{MegaHashesPerSecond, "MH/s"},
+	{GigaHashesPerSecond, "GH/s"},
+	{TeraHashesPerSecond, "TH/s"},
+	{PetaHashesPerSecond, "PH/s"},
+	{ExaHashesPerSecond, "EH/s"},
+}
+
+func (u HashrateUnit) String() string {
+	for _, n := range hashrateUnitNames {
+		if n.unit == u {
+			return n.name
+		}
+	}
+	return fmt.Sprintf("%g H/s", float64(u))
+}
+
+// ParseHashrateUnit parses a unit name such as TH/s, case insensitively and
+// with or without the /s
+func ParseHashrateUnit(s string) (HashrateUnit, error) {
+	name := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "/S")
+	for _, n := range hashrateUnitNames {
+		if name == strings.ToUpper(strings.TrimSuffix(n.name, "/s")) {
+			return n.unit, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown hashrate unit %q", s)
+}
+
+// ToHashes converts a hashrate in the unit to H/s
+func (u HashrateUnit) ToHashes(hashrate float64) float64 {
+	return hashrate * float64(u)
+}
+
+// FromHashes converts a hashrate in H/s to the unit
+func (u HashrateUnit) FromHashes(hashrate float64) float64 {
+	return hashrate / float64(u)
+}
+
+// Format writes a hashrate in H/s in the unit, rounded to two decimals
+func (u HashrateUnit) Format(hashrate float64) string {
+	return fmt.Sprintf("%.2f %s", u.FromHashes(hashrate), u)
+}
+
+// hashrateUnits are the units a source reports the network and the pool
+// hashrate in
+type hashrateUnits struct {
+	network HashrateUnit
+	pool    HashrateUnit
+}
+
+// legacySourceUnits are the units of the sources collected before
+// hashrates were normalized, which stored their hashrates as reported.
+// Btc.com hashrates were converted from PH/s already.
+var legacySourceUnits = map[string]hashrateUnits{
+	Luxor:    luxorUnits,
+	F2pool:   f2poolUnits,
+	Coinmine: coinmineUnits,
+}
+
+// LegacyHashrateUnits returns the units of the network and pool hashrates
+// stored before hashrates were normalized to H/s, for the sources whose
+// hashrates were not stored in H/s
+func LegacyHashrateUnits() (network, pool map[string]HashrateUnit) {
+	network = make(map[string]HashrateUnit)
+	pool = make(map[string]HashrateUnit)
+	for source, units := range legacySourceUnits {
+		if units.network != HashesPerSecond {
+			network[source] = units.network
+		}
+		if units.pool != HashesPerSecond {
+			pool[source] = units.pool
+		}
+	}
+	return network, pool
+}
+
+// normalize converts the hashrates of a source record to H/s, in the units
+// of the record when it has them
+func (units hashrateUnits) normalize(record sourceData) PowData {
+	network, pool := units.network, units.pool
+	if record.units.network != 0 {
+		network = record.units.network
+	}
+	if record.units.pool != 0 {
+		pool = record.units.pool
+	}
+	data := record.PowData
+	data.NetworkHashrate = int64(math.Round(network.ToHashes(record.networkHashrate)))
+	data.PoolHashrate = pool.ToHashes(record.poolHashrate)
+	return data
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pow
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParseHashrateUnit(t *testing.T) {
+	tests := []struct {
+		name string
+		unit HashrateUnit
+	}{
+		{"H/s", HashesPerSecond},
+		{"TH/s", TeraHashesPerSecond},
+		{"th/s", TeraHashesPerSecond},
+		{"PH", PetaHashesPerSecond},
+		{" kh/S ", KiloHashesPerSecond},
+	}
+	for _, test := range tests {
+		unit, err := ParseHashrateUnit(test.name)
+		if err != nil {
+			t.Errorf("%q: %v", test.name, err)
+			continue
+		}
+		if unit != test.unit {
+			t.Errorf("%q: got %v, expected %v", test.name, unit, test.unit)
+		}
+	}
+
+	for _, name := range []string{"", "T", "TB/s"} {
+		if _, err := ParseHashrateUnit(name); err == nil {
+			t.Errorf("%q: expected an error", name)
+		}
+	}
+}
+
+func TestHashrateUnitConversion(t *testing.T) {
+	if h := PetaHashesPerSecond.ToHashes(2.5); h != 2.5e15 {
+		t.Errorf("2.5 PH/s is %v H/s, expected 2.5e15", h)
+	}
+	if th := TeraHashesPerSecond.FromHashes(2.5e15); th != 2500 {
+		t.Errorf("2.5e15 H/s is %v TH/s, expected 2500", th)
+	}
+	if s := PetaHashesPerSecond.Format(1.234e15); s != "1.23 PH/s" {
+		t.Errorf("formatted as %q", s)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	data := coinmineUnits.normalize(sourceData{
+		PowData:         PowData{Time: 1559347200, Workers: 3},
+		networkHashrate: 4.2e17,
+		poolHashrate:    1.5,
+	})
+	if data.NetworkHashrate != 420000000000000000 || data.PoolHashrate != 1500 {
+		t.Errorf("unexpected hashrates %d and %v", data.NetworkHashrate, data.PoolHashrate)
+	}
+	if data.Time != 1559347200 || data.Workers != 3 {
+		t.Errorf("unexpected data %+v", data)
+	}
+}
+
+func TestSourcesDeclareUnits(t *testing.T) {
+	for name, constructor := range PowConstructors {
+		source, err := constructor(http.DefaultClient, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if network, pool := source.HashrateUnits(); network <= 0 || pool <= 0 {
+			t.Errorf("%s declares the units %v and %v", name, network, pool)
+		}
+	}
+}
+
+func TestLegacyHashrateUnits(t *testing.T) {
+	network, pool := LegacyHashrateUnits()
+	if len(network) != 0 {
+		t.Errorf("network hashrates to convert %v, expected none", network)
+	}
+	// Only Coinmine stored its pool hashrates in another unit than H/s, the
+	// sources added since stored normalized hashrates
+	if len(pool) != 1 || pool[Coinmine] != KiloHashesPerSecond {
+		t.Errorf("pool hashrates to convert %v, expected coinmine in KH/s", pool)
+	}
+}
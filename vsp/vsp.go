@@ -44,7 +44,7 @@ func (vsp *Collector) fetch(ctx context.Context, response interface{}) error {
 	defer resp.Body.Close()
 	err = json.NewDecoder(resp.Body).Decode(response)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("Failed to decode json: %v", err))
+		return fmt.Errorf("Failed to decode json: %v", err)
 	}
 
 	return nil
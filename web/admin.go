@@ -0,0 +1,230 @@
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+	"github.com/raedahgroup/dcrextdata/helpers"
+)
+
+// requireAdmin only passes on requests that carry the admin token as a
+// bearer token
+func (s *Server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		auth := req.Header.Get("Authorization")
+		token := strings.TrimPrefix(auth, "Bearer ")
+		if s.adminToken == "" || token == auth ||
+			subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			writeJSONError(res, http.StatusUnauthorized, "invalid admin token")
+			return
+		}
+		next.ServeHTTP(res, req)
+	})
+}
+
+// GetExchangeRegistry lists the registry entries of all exchanges
+func (s *Server) GetExchangeRegistry(res http.ResponseWriter, req *http.Request) {
+	registry, err := s.db.ExchangeRegistry(req.Context())
+	if err != nil {
+		writeJSONError(res, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(res, http.StatusOK, registry)
+}
+
+// UpdateExchange changes the status and notes of the exchange named in the
+// path to the fields set in the request body
+func (s *Server) UpdateExchange(res http.ResponseWriter, req *http.Request) {
+	var update ticks.ExchangeUpdate
+	if err := json.NewDecoder(req.Body).Decode(&update); err != nil {
+		writeJSONError(res, http.StatusBadRequest, err.Error())
+		return
+	}
+	if update.Status != nil {
+		if _, err := ticks.ParseExchangeStatus(string(*update.Status)); err != nil {
+			writeJSONError(res, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	name := chi.URLParam(req, "exchange")
+	if err := s.db.UpdateExchange(req.Context(), name, update); err != nil {
+		writeJSONError(res, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.writeExchangeInfo(req.Context(), res, name)
+}
+
+// marketUpdateRequest changes the market Pair. Dates are given as 2006-01-02
+// or RFC3339, an empty date clears it.
+type marketUpdateRequest struct {
+	Pair       string  `json:"pair"`
+	ListedAt   *string `json:"listed_at"`
+	DelistedAt *string `json:"delisted_at"`
+	Collect    *bool   `json:"collect"`
+	Notes      *string `json:"notes"`
+}
+
+// UpdateExchangeMarket changes the listing dates and notes of a market of the
+// exchange named in the path
+func (s *Server) UpdateExchangeMarket(res http.ResponseWriter, req *http.Request) {
+	var body marketUpdateRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeJSONError(res, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	update := ticks.MarketUpdate{Collect: body.Collect, Notes: body.Notes}
+	var err error
+	if body.ListedAt != nil {
+		if update.ListedAt, err = ticks.ParseMarketDate(*body.ListedAt); err != nil {
+			writeJSONError(res, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if body.DelistedAt != nil {
+		if update.DelistedAt, err = ticks.ParseMarketDate(*body.DelistedAt); err != nil {
+			writeJSONError(res, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	name := chi.URLParam(req, "exchange")
+	if err = s.db.UpdateExchangeMarket(req.Context(), name, body.Pair, update); err != nil {
+		writeJSONError(res, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.writeExchangeInfo(req.Context(), res, name)
+}
+
+func (s *Server) writeExchangeInfo(ctx context.Context, res http.ResponseWriter, name string) {
+	registry, err := s.db.ExchangeRegistry(ctx)
+	if err != nil {
+		writeJSONError(res, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, info := range registry {
+		if info.Name == name {
+			writeJSON(res, http.StatusOK, info)
+			return
+		}
+	}
+	writeJSONError(res, http.StatusNotFound, "unknown exchange "+name)
+}
+
+// backfillJobResponse is a backfill job with its progress in percent and the
+// estimated seconds left, null when unknown
+type backfillJobResponse struct {
+	ticks.BackfillJob
+	Progress float64  `json:"progress"`
+	ETA      *float64 `json:"eta"`
+}
+
+// GetBackfillJobs lists all backfill jobs with their progress
+func (s *Server) GetBackfillJobs(res http.ResponseWriter, req *http.Request) {
+	jobs, err := s.db.BackfillJobs(req.Context())
+	if err != nil {
+		writeJSONError(res, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := make([]backfillJobResponse, len(jobs))
+	for i, job := range jobs {
+		resp[i] = backfillJobResponse{BackfillJob: job, Progress: job.Progress()}
+		if eta, ok := job.ETA(); ok {
+			seconds := eta.Seconds()
+			resp[i].ETA = &seconds
+		}
+	}
+	writeJSON(res, http.StatusOK, resp)
+}
+
+// backfillJobRequest creates a backfill job of the market Pair at Interval,
+// in minutes. Dates are given as 2006-01-02 or RFC3339, To defaults to now.
+type backfillJobRequest struct {
+	Exchange string `json:"exchange"`
+	Pair     string `json:"pair"`
+	Interval int    `json:"interval"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+// CreateBackfillJob queues the backfill job described by the request body
+func (s *Server) CreateBackfillJob(res http.ResponseWriter, req *http.Request) {
+	var body backfillJobRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeJSONError(res, http.StatusBadRequest, err.Error())
+		return
+	}
+	if body.From == "" {
+		writeJSONError(res, http.StatusBadRequest, "from is required")
+		return
+	}
+	from, err := helpers.ParseDate(body.From)
+	if err != nil {
+		writeJSONError(res, http.StatusBadRequest, err.Error())
+		return
+	}
+	to := time.Now()
+	if body.To != "" {
+		if to, err = helpers.ParseDate(body.To); err != nil {
+			writeJSONError(res, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	job, err := ticks.NewBackfillJob(strings.ToLower(body.Exchange), body.Pair,
+		time.Duration(body.Interval)*time.Minute, from, to)
+	if err != nil {
+		writeJSONError(res, http.StatusBadRequest, err.Error())
+		return
+	}
+	if job.ID, err = s.db.CreateBackfillJob(req.Context(), job); err != nil {
+		writeJSONError(res, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(res, http.StatusCreated, backfillJobResponse{BackfillJob: job})
+}
+
+// ChangeBackfillJob pauses, resumes or cancels the backfill job with the id
+// in the path
+func (s *Server) ChangeBackfillJob(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		writeJSONError(res, http.StatusBadRequest, "invalid backfill job id")
+		return
+	}
+	action := chi.URLParam(req, "action")
+	status, from, err := ticks.BackfillTransition(action)
+	if err != nil {
+		writeJSONError(res, http.StatusBadRequest, err.Error())
+		return
+	}
+	changed, err := s.db.SetBackfillStatus(req.Context(), id, status, "", from...)
+	if err != nil {
+		writeJSONError(res, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !changed {
+		writeJSONError(res, http.StatusConflict, fmt.Sprintf("cannot %s backfill job %d", action, id))
+		return
+	}
+	res.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(res http.ResponseWriter, status int, data interface{}) {
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(status)
+	json.NewEncoder(res).Encode(data)
+}
+
+func writeJSONError(res http.ResponseWriter, status int, message string) {
+	writeJSON(res, status, map[string]string{"error": message})
+}
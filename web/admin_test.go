@@ -0,0 +1,50 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		status        int
+	}{
+		{"valid token", "secret", "Bearer secret", http.StatusOK},
+		{"no header", "secret", "", http.StatusUnauthorized},
+		{"wrong token", "secret", "Bearer wrong", http.StatusUnauthorized},
+		{"token prefix", "secret", "Bearer secre", http.StatusUnauthorized},
+		{"longer token", "secret", "Bearer secrets", http.StatusUnauthorized},
+		{"not a bearer token", "secret", "secret", http.StatusUnauthorized},
+		{"basic auth", "secret", "Basic secret", http.StatusUnauthorized},
+		{"empty bearer token", "secret", "Bearer ", http.StatusUnauthorized},
+		{"no admin token", "", "Bearer ", http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		s := &Server{adminToken: test.token}
+		called := false
+		handler := s.requireAdmin(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			called = true
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/exchanges", nil)
+		if test.authorization != "" {
+			req.Header.Set("Authorization", test.authorization)
+		}
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		if res.Code != test.status {
+			t.Errorf("%s: status %d, expected %d", test.name, res.Code, test.status)
+		}
+		if called != (test.status == http.StatusOK) {
+			t.Errorf("%s: handler called %v", test.name, called)
+		}
+	}
+}
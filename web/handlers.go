@@ -2,15 +2,48 @@ package web
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ericlagergren/decimal"
+	"github.com/raedahgroup/dcrextdata/chain"
+	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+	"github.com/raedahgroup/dcrextdata/fiat"
+	"github.com/raedahgroup/dcrextdata/pow"
+	"github.com/raedahgroup/dcrextdata/pow/share"
 )
 
 const (
-	recordsPerPage  = 20
+	recordsPerPage = 20
+
+	defaultHashrateUnit = pow.TeraHashesPerSecond
+
+	defaultLuckDays = 7
+
+	defaultProfitabilityDays = 30
+
+	// profitabilityPair is the market DCR prices are taken from for the
+	// profitability, converted to USD with the BTC rates
+	profitabilityPair = "BTC/DCR"
+
+	// profitabilityInterval is the interval, in minutes, of the candles whose
+	// closes are averaged into the daily price, which every exchange collects
+	profitabilityInterval = 1440
 )
 
+// luckDays are the windows, in days, pool luck can be computed over
+var luckDays = []int{1, 7, 30}
+
+// profitabilityDays are the periods, in days, profitability can be shown for
+var profitabilityDays = []int{30, 90, 365}
+
+// hashrateUnits are the units PoW hashrates can be shown in
+var hashrateUnits = []pow.HashrateUnit{pow.TeraHashesPerSecond, pow.PetaHashesPerSecond}
+
 func (s *Server) GetExchangeTicks(res http.ResponseWriter, req *http.Request) {
 	req.ParseForm()
 	page := req.FormValue("page")
@@ -31,11 +64,36 @@ func (s *Server) GetExchangeTicks(res http.ResponseWriter, req *http.Request) {
 
 	totalCount, err := s.db.AllExchangeTicksCount(ctx)
 
+	currency := req.FormValue("currency")
+	if currency != "" {
+		allExhangeSlice = s.convertTicks(ctx, allExhangeSlice, currency)
+	}
+
+	currencies, err := s.db.FiatCurrencies(ctx)
+	if err != nil {
+		log.Errorf("unable to fetch fiat currencies: %v", err)
+	}
+
+	// Markets whose series may be incomplete are pointed out above the ticks
+	var unhealthy []ticks.MarketHealth
+	states, err := s.db.ExchangeHealth(ctx)
+	if err != nil {
+		log.Errorf("unable to fetch exchange health: %v", err)
+	}
+	for _, state := range states {
+		if state.Health != ticks.HealthHealthy {
+			unhealthy = append(unhealthy, state)
+		}
+	}
+
 	data := map[string]interface{}{
-		"exData":                      allExhangeSlice,
-		"currentPage":              int(pageToLoad),
-		"previousPage":             int(pageToLoad - 1),
-		"totalPages":               int(math.Ceil(float64(totalCount) / float64(txPerPage))),
+		"exData":       allExhangeSlice,
+		"currency":     currency,
+		"currencies":   currencies,
+		"unhealthy":    unhealthy,
+		"currentPage":  int(pageToLoad),
+		"previousPage": int(pageToLoad - 1),
+		"totalPages":   int(math.Ceil(float64(totalCount) / float64(txPerPage))),
 	}
 
 	totalTxLoaded := int(offset) + len(allExhangeSlice)
@@ -68,10 +126,10 @@ func (s *Server) GetVspTicks(res http.ResponseWriter, req *http.Request) {
 	totalCount, err := s.db.AllVSPTickCount(ctx)
 
 	data := map[string]interface{}{
-		"vspData":                      allVSPSlice,
-		"currentPage":              int(pageToLoad),
-		"previousPage":             int(pageToLoad - 1),
-		"totalPages":               int(math.Ceil(float64(totalCount) / float64(txPerPage))),
+		"vspData":      allVSPSlice,
+		"currentPage":  int(pageToLoad),
+		"previousPage": int(pageToLoad - 1),
+		"totalPages":   int(math.Ceil(float64(totalCount) / float64(txPerPage))),
 	}
 
 	totalTxLoaded := int(offset) + len(allVSPSlice)
@@ -81,3 +139,694 @@ func (s *Server) GetVspTicks(res http.ResponseWriter, req *http.Request) {
 
 	s.render("vsp.html", data, res)
 }
+
+func (s *Server) GetSpreads(res http.ResponseWriter, req *http.Request) {
+	req.ParseForm()
+	page := req.FormValue("page")
+	sustainedOnly := req.FormValue("sustained") == "1"
+
+	pageToLoad, err := strconv.ParseInt(page, 10, 32)
+	if err != nil || pageToLoad <= 0 {
+		pageToLoad = 1
+	}
+
+	offset := (int(pageToLoad) - 1) * recordsPerPage
+
+	ctx := context.Background()
+	spreads, err := s.db.FetchSpreads(ctx, sustainedOnly, offset, recordsPerPage)
+	if err != nil {
+		panic(err)
+	}
+
+	totalCount, err := s.db.SpreadsCount(ctx, sustainedOnly)
+
+	data := map[string]interface{}{
+		"spreadData":   spreads,
+		"sustained":    sustainedOnly,
+		"currentPage":  int(pageToLoad),
+		"previousPage": int(pageToLoad - 1),
+		"totalPages":   int(math.Ceil(float64(totalCount) / float64(recordsPerPage))),
+	}
+
+	totalLoaded := offset + len(spreads)
+	if int64(totalLoaded) < totalCount {
+		data["nextPage"] = int(pageToLoad + 1)
+	}
+
+	s.render("spreads.html", data, res)
+}
+
+// GetTransferStatuses shows the current DCR deposit and withdrawal status of
+// the exchanges and the history of changes
+func (s *Server) GetTransferStatuses(res http.ResponseWriter, req *http.Request) {
+	req.ParseForm()
+	page := req.FormValue("page")
+
+	pageToLoad, err := strconv.ParseInt(page, 10, 32)
+	if err != nil || pageToLoad <= 0 {
+		pageToLoad = 1
+	}
+
+	offset := (int(pageToLoad) - 1) * recordsPerPage
+
+	ctx := context.Background()
+	current, err := s.db.LatestTransferStatuses(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	history, err := s.db.TransferStatusHistory(ctx, offset, recordsPerPage)
+	if err != nil {
+		panic(err)
+	}
+
+	totalCount, err := s.db.TransferStatusCount(ctx)
+
+	data := map[string]interface{}{
+		"currentData":  current,
+		"historyData":  history,
+		"currentPage":  int(pageToLoad),
+		"previousPage": int(pageToLoad - 1),
+		"totalPages":   int(math.Ceil(float64(totalCount) / float64(recordsPerPage))),
+	}
+
+	totalLoaded := offset + len(history)
+	if int64(totalLoaded) < totalCount {
+		data["nextPage"] = int(pageToLoad + 1)
+	}
+
+	s.render("transfers.html", data, res)
+}
+
+// GetExchangeHealth shows the health of the collected markets and the
+// history of their outages
+func (s *Server) GetExchangeHealth(res http.ResponseWriter, req *http.Request) {
+	req.ParseForm()
+	page := req.FormValue("page")
+
+	pageToLoad, err := strconv.ParseInt(page, 10, 32)
+	if err != nil || pageToLoad <= 0 {
+		pageToLoad = 1
+	}
+
+	offset := (int(pageToLoad) - 1) * recordsPerPage
+
+	ctx := context.Background()
+	states, err := s.db.ExchangeHealth(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	outages, err := s.db.FetchOutages(ctx, offset, recordsPerPage)
+	if err != nil {
+		panic(err)
+	}
+
+	totalCount, err := s.db.OutagesCount(ctx)
+
+	data := map[string]interface{}{
+		"healthData":   states,
+		"outageData":   outages,
+		"currentPage":  int(pageToLoad),
+		"previousPage": int(pageToLoad - 1),
+		"totalPages":   int(math.Ceil(float64(totalCount) / float64(recordsPerPage))),
+	}
+
+	totalLoaded := offset + len(outages)
+	if int64(totalLoaded) < totalCount {
+		data["nextPage"] = int(pageToLoad + 1)
+	}
+
+	s.render("health.html", data, res)
+}
+
+// powDataRow is a PoW data point with its hashrates in Unit
+type powDataRow struct {
+	pow.PowDataDto
+	NetworkHashrate float64 `json:"network_hashrate"`
+	PoolHashrate    float64 `json:"pool_hashrate"`
+	Unit            string  `json:"unit"`
+}
+
+// parseHashrateUnit returns the unit named by the unit form value, TH/s when
+// it is empty
+func parseHashrateUnit(req *http.Request) (pow.HashrateUnit, error) {
+	unit := req.FormValue("unit")
+	if unit == "" {
+		return defaultHashrateUnit, nil
+	}
+	return pow.ParseHashrateUnit(unit)
+}
+
+// powDataRows fetches a page of PoW data with the hashrates converted to unit
+func (s *Server) powDataRows(ctx context.Context, unit pow.HashrateUnit, offset int) ([]powDataRow, error) {
+	data, err := s.db.FetchPowData(ctx, offset, recordsPerPage)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]powDataRow, len(data))
+	for i, d := range data {
+		rows[i] = powDataRow{
+			PowDataDto:      d,
+			NetworkHashrate: unit.FromHashes(d.NetworkHashrate),
+			PoolHashrate:    unit.FromHashes(d.PoolHashrate),
+			Unit:            unit.String(),
+		}
+	}
+	return rows, nil
+}
+
+func (s *Server) GetPowData(res http.ResponseWriter, req *http.Request) {
+	req.ParseForm()
+	page := req.FormValue("page")
+
+	pageToLoad, err := strconv.ParseInt(page, 10, 32)
+	if err != nil || pageToLoad <= 0 {
+		pageToLoad = 1
+	}
+
+	offset := (int(pageToLoad) - 1) * recordsPerPage
+
+	unit, err := parseHashrateUnit(req)
+	if err != nil {
+		unit = defaultHashrateUnit
+	}
+
+	ctx := context.Background()
+	powData, err := s.powDataRows(ctx, unit, offset)
+	if err != nil {
+		panic(err)
+	}
+
+	totalCount, err := s.db.PowDataCount(ctx)
+
+	data := map[string]interface{}{
+		"powData":      powData,
+		"unit":         unit.String(),
+		"units":        hashrateUnitNames(),
+		"currentPage":  int(pageToLoad),
+		"previousPage": int(pageToLoad - 1),
+		"totalPages":   int(math.Ceil(float64(totalCount) / float64(recordsPerPage))),
+	}
+
+	totalLoaded := offset + len(powData)
+	if int64(totalLoaded) < totalCount {
+		data["nextPage"] = int(pageToLoad + 1)
+	}
+
+	s.render("pow.html", data, res)
+}
+
+// GetPowDataAPI returns a page of PoW data as JSON, with the hashrates in the
+// unit query parameter, TH/s by default
+func (s *Server) GetPowDataAPI(res http.ResponseWriter, req *http.Request) {
+	pageToLoad, err := strconv.Atoi(req.FormValue("page"))
+	if err != nil || pageToLoad <= 0 {
+		pageToLoad = 1
+	}
+
+	unit, err := parseHashrateUnit(req)
+	if err != nil {
+		writeJSONError(res, http.StatusBadRequest, err.Error())
+		return
+	}
+	rows, err := s.powDataRows(req.Context(), unit, (pageToLoad-1)*recordsPerPage)
+	if err != nil {
+		writeJSONError(res, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(res, http.StatusOK, rows)
+}
+
+// GetPoolShares shows the latest share of the network hashrate held by each
+// pool, the pool share alerts and the centralization history
+func (s *Server) GetPoolShares(res http.ResponseWriter, req *http.Request) {
+	req.ParseForm()
+	page := req.FormValue("page")
+
+	pageToLoad, err := strconv.ParseInt(page, 10, 32)
+	if err != nil || pageToLoad <= 0 {
+		pageToLoad = 1
+	}
+
+	offset := (int(pageToLoad) - 1) * recordsPerPage
+
+	unit, err := parseHashrateUnit(req)
+	if err != nil {
+		unit = defaultHashrateUnit
+	}
+
+	ctx := context.Background()
+	history, err := s.db.FetchCentralization(ctx, offset, recordsPerPage)
+	if err != nil {
+		panic(err)
+	}
+	for i := range history {
+		history[i] = convertHashrates(history[i], unit)
+	}
+
+	data := map[string]interface{}{
+		"history":      history,
+		"unit":         unit.String(),
+		"units":        hashrateUnitNames(),
+		"currentPage":  int(pageToLoad),
+		"previousPage": int(pageToLoad - 1),
+	}
+
+	latest := history
+	if pageToLoad > 1 {
+		if latest, err = s.db.FetchCentralization(ctx, 0, 1); err != nil {
+			panic(err)
+		}
+	}
+	if len(latest) > 0 {
+		data["latest"] = convertHashrates(latest[0], unit)
+	}
+
+	alerts, err := s.db.FetchShareAlerts(ctx, recordsPerPage)
+	if err != nil {
+		log.Errorf("unable to fetch pool share alerts: %v", err)
+	}
+	data["alerts"] = alerts
+
+	totalCount, err := s.db.CentralizationCount(ctx)
+	data["totalPages"] = int(math.Ceil(float64(totalCount) / float64(recordsPerPage)))
+
+	totalLoaded := offset + len(history)
+	if int64(totalLoaded) < totalCount {
+		data["nextPage"] = int(pageToLoad + 1)
+	}
+
+	s.render("poolshare.html", data, res)
+}
+
+// GetPowBlocks shows the luck of every pool over the last days and the blocks
+// found by the pools
+func (s *Server) GetPowBlocks(res http.ResponseWriter, req *http.Request) {
+	req.ParseForm()
+	page := req.FormValue("page")
+
+	pageToLoad, err := strconv.ParseInt(page, 10, 32)
+	if err != nil || pageToLoad <= 0 {
+		pageToLoad = 1
+	}
+
+	offset := (int(pageToLoad) - 1) * recordsPerPage
+
+	days := defaultLuckDays
+	for _, d := range luckDays {
+		if req.FormValue("days") == strconv.Itoa(d) {
+			days = d
+		}
+	}
+
+	ctx := context.Background()
+	blocks, err := s.db.FetchPowBlocks(ctx, offset, recordsPerPage)
+	if err != nil {
+		panic(err)
+	}
+
+	totalCount, err := s.db.PowBlockCount(ctx)
+
+	data := map[string]interface{}{
+		"blocks":       blocks,
+		"days":         days,
+		"luckDays":     luckDays,
+		"currentPage":  int(pageToLoad),
+		"previousPage": int(pageToLoad - 1),
+		"totalPages":   int(math.Ceil(float64(totalCount) / float64(recordsPerPage))),
+	}
+
+	totalLoaded := offset + len(blocks)
+	if int64(totalLoaded) < totalCount {
+		data["nextPage"] = int(pageToLoad + 1)
+	}
+
+	// Luck needs the pool shares, which may not be computed
+	window := time.Duration(days) * 24 * time.Hour
+	since := time.Now().Add(-window)
+	counts, err := s.db.PoolBlockCounts(ctx, since.Unix())
+	if err != nil {
+		log.Errorf("unable to count pool blocks: %v", err)
+	}
+	shares, err := s.db.AveragePoolShares(ctx, since)
+	if err != nil {
+		log.Errorf("unable to fetch pool shares: %v", err)
+	} else {
+		data["luck"] = pow.Luck(counts, shares, window)
+	}
+
+	s.render("powblocks.html", data, res)
+}
+
+// parseMiningCosts returns the mining costs of the efficiency and powercost
+// form values, falling back to the server's costs for the empty ones
+func (s *Server) parseMiningCosts(req *http.Request) (pow.MiningCosts, error) {
+	costs := s.costs
+	if efficiency := req.FormValue("efficiency"); efficiency != "" {
+		v, err := strconv.ParseFloat(efficiency, 64)
+		if err != nil {
+			return costs, fmt.Errorf("invalid efficiency %q", efficiency)
+		}
+		costs.Efficiency = v
+	}
+	if powerCost := req.FormValue("powercost"); powerCost != "" {
+		v, err := strconv.ParseFloat(powerCost, 64)
+		if err != nil {
+			return costs, fmt.Errorf("invalid power cost %q", powerCost)
+		}
+		costs.PowerCost = v
+	}
+	return costs, costs.Validate()
+}
+
+// parseProfitabilityDays returns the period of the days form value, 30 days
+// when it is not one of profitabilityDays
+func parseProfitabilityDays(req *http.Request) int {
+	for _, d := range profitabilityDays {
+		if req.FormValue("days") == strconv.Itoa(d) {
+			return d
+		}
+	}
+	return defaultProfitabilityDays
+}
+
+// profitability computes the daily profitability of 1 TH/s over the last
+// days, most recent first. Days without a USD price of DCR are not priced.
+func (s *Server) profitability(ctx context.Context, days int, costs pow.MiningCosts) ([]pow.ProfitabilityDay, error) {
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	networkDays, err := s.db.DailyNetworkStats(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	heights, err := s.db.DailyHeights(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := s.db.DailyClosePrices(ctx, profitabilityPair, profitabilityInterval, since)
+	if err != nil {
+		return nil, err
+	}
+
+	converter := fiat.NewConverter(s.db, "USD")
+	result := make([]pow.ProfitabilityDay, 0, len(networkDays))
+	for i := len(networkDays) - 1; i >= 0; i-- {
+		day := networkDays[i]
+		day.Height = heights[day.Time.Unix()]
+		var dcrPrice float64
+		if price, found := prices[day.Time.Unix()]; found {
+			if dcrPrice, err = converter.ConvertPrice(ctx, price, profitabilityPair, day.Time); err != nil {
+				dcrPrice = 0
+			}
+		}
+		if p, ok := pow.Profitability(day, dcrPrice, costs); ok {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// GetProfitability shows the expected daily revenue and profit of 1 TH/s of
+// hashrate over the last days
+func (s *Server) GetProfitability(res http.ResponseWriter, req *http.Request) {
+	req.ParseForm()
+	days := parseProfitabilityDays(req)
+
+	data := map[string]interface{}{
+		"days":              days,
+		"profitabilityDays": profitabilityDays,
+	}
+
+	costs, err := s.parseMiningCosts(req)
+	if err != nil {
+		data["error"] = err.Error()
+		costs = s.costs
+	}
+	data["costs"] = costs
+
+	profitability, err := s.profitability(context.Background(), days, costs)
+	if err != nil {
+		panic(err)
+	}
+	data["profitability"] = profitability
+
+	s.render("profitability.html", data, res)
+}
+
+// GetProfitabilityAPI returns the daily profitability of 1 TH/s as JSON, for
+// the efficiency and powercost query parameters, the configured costs by
+// default
+func (s *Server) GetProfitabilityAPI(res http.ResponseWriter, req *http.Request) {
+	costs, err := s.parseMiningCosts(req)
+	if err != nil {
+		writeJSONError(res, http.StatusBadRequest, err.Error())
+		return
+	}
+	profitability, err := s.profitability(req.Context(), parseProfitabilityDays(req), costs)
+	if err != nil {
+		writeJSONError(res, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(res, http.StatusOK, profitability)
+}
+
+// chainBlockRow is a block with its network hashrate in the shown unit
+type chainBlockRow struct {
+	chain.Block
+	NetworkHashrate float64
+}
+
+// GetChainBlocks shows the blocks recorded from the dcrd node, highest first
+func (s *Server) GetChainBlocks(res http.ResponseWriter, req *http.Request) {
+	req.ParseForm()
+	page := req.FormValue("page")
+
+	pageToLoad, err := strconv.ParseInt(page, 10, 32)
+	if err != nil || pageToLoad <= 0 {
+		pageToLoad = 1
+	}
+
+	offset := (int(pageToLoad) - 1) * recordsPerPage
+
+	unit, err := parseHashrateUnit(req)
+	if err != nil {
+		unit = defaultHashrateUnit
+	}
+
+	ctx := context.Background()
+	blocks, err := s.db.FetchChainBlocks(ctx, offset, recordsPerPage)
+	if err != nil {
+		panic(err)
+	}
+	rows := make([]chainBlockRow, len(blocks))
+	for i, b := range blocks {
+		rows[i] = chainBlockRow{Block: b, NetworkHashrate: unit.FromHashes(b.NetworkHashrate)}
+	}
+
+	totalCount, err := s.db.ChainBlockCount(ctx)
+
+	data := map[string]interface{}{
+		"blocks":       rows,
+		"unit":         unit.String(),
+		"units":        hashrateUnitNames(),
+		"currentPage":  int(pageToLoad),
+		"previousPage": int(pageToLoad - 1),
+		"totalPages":   int(math.Ceil(float64(totalCount) / float64(recordsPerPage))),
+	}
+
+	totalLoaded := offset + len(blocks)
+	if int64(totalLoaded) < totalCount {
+		data["nextPage"] = int(pageToLoad + 1)
+	}
+
+	s.render("chain.html", data, res)
+}
+
+// accuracyRetargets is the number of the most recent retargets the accuracy
+// of the projections is computed over
+const accuracyRetargets = 30
+
+// GetProjection shows the latest projection of the next retarget, the
+// accuracy of the projections of the recent retargets, and the projections
+// made, the most recent first
+func (s *Server) GetProjection(res http.ResponseWriter, req *http.Request) {
+	req.ParseForm()
+	page := req.FormValue("page")
+
+	pageToLoad, err := strconv.ParseInt(page, 10, 32)
+	if err != nil || pageToLoad <= 0 {
+		pageToLoad = 1
+	}
+
+	offset := (int(pageToLoad) - 1) * recordsPerPage
+
+	ctx := context.Background()
+	last, err := s.db.LastProjection(ctx)
+	if err != nil {
+		panic(err)
+	}
+	var accuracy []chain.ProjectionAccuracy
+	if last != nil {
+		resolved, err := s.db.FetchResolvedProjections(ctx, last.RetargetHeight-accuracyRetargets*chain.RetargetInterval)
+		if err != nil {
+			panic(err)
+		}
+		accuracy = chain.Accuracy(resolved)
+	}
+
+	projections, err := s.db.FetchChainProjections(ctx, offset, recordsPerPage)
+	if err != nil {
+		panic(err)
+	}
+
+	totalCount, err := s.db.ChainProjectionCount(ctx)
+
+	data := map[string]interface{}{
+		"last":         last,
+		"accuracy":     accuracy,
+		"retargets":    accuracyRetargets,
+		"projections":  projections,
+		"currentPage":  int(pageToLoad),
+		"previousPage": int(pageToLoad - 1),
+		"totalPages":   int(math.Ceil(float64(totalCount) / float64(recordsPerPage))),
+	}
+
+	totalLoaded := offset + len(projections)
+	if int64(totalLoaded) < totalCount {
+		data["nextPage"] = int(pageToLoad + 1)
+	}
+
+	s.render("projection.html", data, res)
+}
+
+// propagatedBlock is a block and the times the nodes first saw it, in the
+// order they saw it
+type propagatedBlock struct {
+	Hash   string
+	Height int64
+	Nodes  []chain.BlockPropagation
+}
+
+// GetPropagation shows the delays of every node over the last day and the
+// times the nodes first saw the recent blocks
+func (s *Server) GetPropagation(res http.ResponseWriter, req *http.Request) {
+	req.ParseForm()
+	page := req.FormValue("page")
+
+	pageToLoad, err := strconv.ParseInt(page, 10, 32)
+	if err != nil || pageToLoad <= 0 {
+		pageToLoad = 1
+	}
+
+	offset := (int(pageToLoad) - 1) * recordsPerPage
+
+	ctx := context.Background()
+	propagation, err := s.db.FetchBlockPropagation(ctx, offset, recordsPerPage)
+	if err != nil {
+		panic(err)
+	}
+	var blocks []propagatedBlock
+	for _, p := range propagation {
+		if len(blocks) == 0 || blocks[len(blocks)-1].Hash != p.Hash {
+			blocks = append(blocks, propagatedBlock{Hash: p.Hash, Height: p.Height})
+		}
+		last := &blocks[len(blocks)-1]
+		last.Nodes = append(last.Nodes, p)
+	}
+
+	delays, err := s.db.NodeDelays(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		panic(err)
+	}
+
+	totalCount, err := s.db.PropagatedBlockCount(ctx)
+
+	data := map[string]interface{}{
+		"blocks":       blocks,
+		"delays":       delays,
+		"currentPage":  int(pageToLoad),
+		"previousPage": int(pageToLoad - 1),
+		"totalPages":   int(math.Ceil(float64(totalCount) / float64(recordsPerPage))),
+	}
+
+	totalLoaded := offset + len(blocks)
+	if int64(totalLoaded) < totalCount {
+		data["nextPage"] = int(pageToLoad + 1)
+	}
+
+	s.render("propagation.html", data, res)
+}
+
+// convertHashrates converts the hashrates of a period from H/s to unit
+func convertHashrates(c share.Centralization, unit pow.HashrateUnit) share.Centralization {
+	c.NetworkHashrate = unit.FromHashes(c.NetworkHashrate)
+	shares := make([]share.PoolShare, len(c.Shares))
+	for i, s := range c.Shares {
+		s.Hashrate = unit.FromHashes(s.Hashrate)
+		shares[i] = s
+	}
+	c.Shares = shares
+	return c
+}
+
+func hashrateUnitNames() []string {
+	names := make([]string, len(hashrateUnits))
+	for i, u := range hashrateUnits {
+		names[i] = u.String()
+	}
+	return names
+}
+
+// convertTicks converts the tick prices to currency, from the rates of the
+// time range of the ticks. Ticks without a known rate are left in their
+// original currency.
+func (s *Server) convertTicks(ctx context.Context, tickDtos []ticks.TickDto, currency string) []ticks.TickDto {
+	if len(tickDtos) == 0 {
+		return tickDtos
+	}
+	from, to := tickDtos[0].Time, tickDtos[0].Time
+	for _, tick := range tickDtos {
+		if tick.Time.Before(from) {
+			from = tick.Time
+		}
+		if tick.Time.After(to) {
+			to = tick.Time
+		}
+	}
+	rates, err := s.db.FiatRatesBetween(ctx, from, to)
+	if err != nil {
+		log.Errorf("unable to fetch fiat rates: %v", err)
+		return tickDtos
+	}
+	converter := fiat.NewConverter(fiat.NewRateHistory(rates), currency)
+
+	for i, tick := range tickDtos {
+		rate, err := converter.Rate(ctx, fiat.QuoteAsset(tick.CurrencyPair), tick.Time)
+		if err != nil {
+			continue
+		}
+		r := new(decimal.Big).SetFloat64(rate)
+		tickDtos[i].High = new(decimal.Big).Mul(tick.High, r)
+		tickDtos[i].Low = new(decimal.Big).Mul(tick.Low, r)
+		tickDtos[i].Open = new(decimal.Big).Mul(tick.Open, r)
+		tickDtos[i].Close = new(decimal.Big).Mul(tick.Close, r)
+		tickDtos[i].QuoteVolume = new(decimal.Big).Mul(tick.QuoteVolume, r)
+		if parts := strings.Split(tick.CurrencyPair, "/"); len(parts) == 2 {
+			tickDtos[i].CurrencyPair = fmt.Sprintf("%s/%s", converter.Currency(), parts[1])
+		}
+	}
+	return tickDtos
+}
+
+func (s *Server) GetFiatRates(res http.ResponseWriter, req *http.Request) {
+	rates, err := s.db.LatestFiatRates(context.Background())
+	if err != nil {
+		panic(err)
+	}
+
+	data := map[string]interface{}{
+		"fiatData": rates,
+	}
+
+	s.render("fiat.html", data, res)
+}
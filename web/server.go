@@ -3,7 +3,6 @@ package web
 import (
 	"context"
 	"fmt"
-	"log"
 	"net"
 	"net/http"
 	"os"
@@ -11,10 +10,17 @@ import (
 	"strings"
 	"sync"
 	"text/template"
+	"time"
 
 	"github.com/go-chi/chi"
+	"github.com/raedahgroup/dcrextdata/chain"
+	"github.com/raedahgroup/dcrextdata/exchanges/arbitrage"
 	"github.com/raedahgroup/dcrextdata/exchanges/ticks"
+	"github.com/raedahgroup/dcrextdata/exchanges/transfers"
+	"github.com/raedahgroup/dcrextdata/fiat"
 	"github.com/raedahgroup/dcrextdata/postgres/models"
+	"github.com/raedahgroup/dcrextdata/pow"
+	"github.com/raedahgroup/dcrextdata/pow/share"
 	"github.com/raedahgroup/dcrextdata/vsp"
 )
 
@@ -27,18 +33,67 @@ type DataQuery interface {
 	VSPTicks(ctx context.Context, vspName string, offset int, limit int) ([]vsp.VSPTickDto, error)
 	AllVSPTicks(ctx context.Context, offset int, limit int) ([]vsp.VSPTickDto, error)
 	AllVSPTickCount(ctx context.Context) (int64, error)
+	FiatRate(ctx context.Context, asset, currency string, at time.Time) (float64, error)
+	FiatRatesBetween(ctx context.Context, from, to time.Time) ([]fiat.Rate, error)
+	LatestFiatRates(ctx context.Context) ([]fiat.RateDto, error)
+	FiatCurrencies(ctx context.Context) ([]string, error)
+	FetchSpreads(ctx context.Context, sustainedOnly bool, offset int, limit int) ([]arbitrage.SpreadDto, error)
+	SpreadsCount(ctx context.Context, sustainedOnly bool) (int64, error)
+	ExchangeRegistry(ctx context.Context) ([]ticks.ExchangeInfo, error)
+	UpdateExchange(ctx context.Context, name string, update ticks.ExchangeUpdate) error
+	UpdateExchangeMarket(ctx context.Context, name, pair string, update ticks.MarketUpdate) error
+	LatestTransferStatuses(ctx context.Context) ([]transfers.StatusDto, error)
+	TransferStatusHistory(ctx context.Context, offset int, limit int) ([]transfers.StatusDto, error)
+	TransferStatusCount(ctx context.Context) (int64, error)
+	ExchangeHealth(ctx context.Context) ([]ticks.MarketHealth, error)
+	FetchOutages(ctx context.Context, offset int, limit int) ([]ticks.Outage, error)
+	OutagesCount(ctx context.Context) (int64, error)
+	BackfillJobs(ctx context.Context) ([]ticks.BackfillJob, error)
+	CreateBackfillJob(ctx context.Context, job ticks.BackfillJob) (int, error)
+	SetBackfillStatus(ctx context.Context, id int, status ticks.BackfillStatus, errMsg string, from ...ticks.BackfillStatus) (bool, error)
+	FetchPowData(ctx context.Context, offset int, limit int) ([]pow.PowDataDto, error)
+	PowDataCount(ctx context.Context) (int64, error)
+	FetchCentralization(ctx context.Context, offset int, limit int) ([]share.Centralization, error)
+	CentralizationCount(ctx context.Context) (int64, error)
+	FetchShareAlerts(ctx context.Context, limit int) ([]share.Alert, error)
+	FetchPowBlocks(ctx context.Context, offset int, limit int) ([]pow.PoolBlock, error)
+	PowBlockCount(ctx context.Context) (int64, error)
+	PoolBlockCounts(ctx context.Context, since int64) (map[string]int64, error)
+	AveragePoolShares(ctx context.Context, since time.Time) (map[string]float64, error)
+	DailyNetworkStats(ctx context.Context, since time.Time) ([]pow.NetworkDay, error)
+	DailyHeights(ctx context.Context, since time.Time) (map[int64]int64, error)
+	DailyClosePrices(ctx context.Context, pair string, interval int, since time.Time) (map[int64]float64, error)
+
+	FetchChainBlocks(ctx context.Context, offset int, limit int) ([]chain.Block, error)
+	ChainBlockCount(ctx context.Context) (int64, error)
+
+	LastProjection(ctx context.Context) (*chain.Projection, error)
+	FetchChainProjections(ctx context.Context, offset int, limit int) ([]chain.Projection, error)
+	ChainProjectionCount(ctx context.Context) (int64, error)
+	FetchResolvedProjections(ctx context.Context, from int64) ([]chain.Projection, error)
+
+	FetchBlockPropagation(ctx context.Context, offset int, limit int) ([]chain.BlockPropagation, error)
+	PropagatedBlockCount(ctx context.Context) (int64, error)
+	NodeDelays(ctx context.Context, since time.Time) ([]chain.NodeDelays, error)
 }
 
 type Server struct {
-	templates map[string]*template.Template
-	lock      sync.RWMutex
-	db        DataQuery
+	templates  map[string]*template.Template
+	lock       sync.RWMutex
+	db         DataQuery
+	adminToken string
+	costs      pow.MiningCosts
 }
 
-func StartHttpServer(httpHost, httpPort string, db DataQuery) {
+// StartHttpServer serves the web interface, and the admin API when adminToken
+// is set. Mining profitability is computed for costs unless a request gives
+// its own.
+func StartHttpServer(httpHost, httpPort, adminToken string, costs pow.MiningCosts, db DataQuery) {
 	server := &Server{
-		templates: map[string]*template.Template{},
-		db:        db,
+		templates:  map[string]*template.Template{},
+		db:         db,
+		adminToken: adminToken,
+		costs:      costs,
 	}
 
 	// load templates
@@ -64,14 +119,26 @@ func StartHttpServer(httpHost, httpPort string, db DataQuery) {
 func (s *Server) loadTemplates() {
 	layout := "web/views/layout.html"
 	tpls := map[string]string{
-		"exchange.html": "web/views/exchange.html",
-		"vsp.html":      "web/views/vsp.html",
+		"exchange.html":      "web/views/exchange.html",
+		"vsp.html":           "web/views/vsp.html",
+		"fiat.html":          "web/views/fiat.html",
+		"spreads.html":       "web/views/spreads.html",
+		"transfers.html":     "web/views/transfers.html",
+		"health.html":        "web/views/health.html",
+		"pow.html":           "web/views/pow.html",
+		"poolshare.html":     "web/views/poolshare.html",
+		"powblocks.html":     "web/views/powblocks.html",
+		"profitability.html": "web/views/profitability.html",
+		"chain.html":         "web/views/chain.html",
+		"projection.html":    "web/views/projection.html",
+		"propagation.html":   "web/views/propagation.html",
 	}
 
 	for i, v := range tpls {
 		tpl, err := template.New(i).Funcs(templateFuncMap()).ParseFiles(v, layout)
 		if err != nil {
-			log.Fatalf("error loading templates: %s", err.Error())
+			log.Criticalf("error loading templates: %s", err.Error())
+			os.Exit(1)
 		}
 
 		s.lock.Lock()
@@ -85,6 +152,10 @@ func templateFuncMap() template.FuncMap {
 		"incByOne": func(number int) int {
 			return number + 1
 		},
+		"unixTime": func(seconds int64) time.Time {
+			return time.Unix(seconds, 0).UTC()
+		},
+		"teraHashes": pow.TeraHashesPerSecond.FromHashes,
 	}
 }
 
@@ -95,12 +166,14 @@ func (s *Server) render(tplName string, data map[string]interface{}, res http.Re
 	if tpl, ok := s.templates[tplName]; ok {
 		err := tpl.Execute(res, data)
 		if err != nil {
-			log.Fatalf("error executing template: %s", err.Error())
+			log.Criticalf("error executing template: %s", err.Error())
+			os.Exit(1)
 		}
 		return
 	}
 
-	log.Fatalf("template %s is not registered", tplName)
+	log.Criticalf("template %s is not registered", tplName)
+	os.Exit(1)
 }
 
 func FileServer(r chi.Router, path string, root http.FileSystem) {
@@ -124,5 +197,30 @@ func FileServer(r chi.Router, path string, root http.FileSystem) {
 func (s *Server) registerHandlers(r *chi.Mux) {
 	r.Get("/", s.GetExchangeTicks)
 	r.Get("/vspticks", s.GetVspTicks)
+	r.Get("/fiat", s.GetFiatRates)
+	r.Get("/spreads", s.GetSpreads)
+	r.Get("/transfers", s.GetTransferStatuses)
+	r.Get("/health", s.GetExchangeHealth)
+	r.Get("/pow", s.GetPowData)
+	r.Get("/api/pow", s.GetPowDataAPI)
+	r.Get("/poolshare", s.GetPoolShares)
+	r.Get("/powblocks", s.GetPowBlocks)
+	r.Get("/profitability", s.GetProfitability)
+	r.Get("/api/profitability", s.GetProfitabilityAPI)
+	r.Get("/chain", s.GetChainBlocks)
+	r.Get("/projection", s.GetProjection)
+	r.Get("/propagation", s.GetPropagation)
+
+	if s.adminToken != "" {
+		r.Route("/api/admin", func(r chi.Router) {
+			r.Use(s.requireAdmin)
+			r.Get("/exchanges", s.GetExchangeRegistry)
+			r.Patch("/exchanges/{exchange}", s.UpdateExchange)
+			r.Patch("/exchanges/{exchange}/markets", s.UpdateExchangeMarket)
+			r.Get("/backfills", s.GetBackfillJobs)
+			r.Post("/backfills", s.CreateBackfillJob)
+			r.Post("/backfills/{id}/{action}", s.ChangeBackfillJob)
+		})
+	}
 
-}
\ No newline at end of file
+}